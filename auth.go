@@ -1,69 +1,718 @@
 package auth
 
 import (
+	"context"
+	"crypto"
+	"crypto/ed25519"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/suryansh74/auth-package/internal/db"
 	"github.com/suryansh74/auth-package/internal/handlers"
 	"github.com/suryansh74/auth-package/internal/middleware"
+	"github.com/suryansh74/auth-package/internal/services"
+	"github.com/suryansh74/auth-package/mailer"
 	"github.com/suryansh74/auth-package/token"
 )
 
+const (
+	// defaultRevocationCleanupInterval is used when
+	// Config.RevocationCleanupInterval is not set
+	defaultRevocationCleanupInterval = time.Hour
+	// defaultJWKSMaxAge is used when Config.JWKSMaxAge is not set
+	defaultJWKSMaxAge = 15 * time.Minute
+	// defaultInvitationDuration is used when Config.InvitationDuration is
+	// not set
+	defaultInvitationDuration = 72 * time.Hour
+	// defaultPasswordResetDuration is used when
+	// Config.PasswordResetDuration is not set
+	defaultPasswordResetDuration = 30 * time.Minute
+	// defaultEmailVerificationDuration is used when
+	// Config.EmailVerificationDuration is not set
+	defaultEmailVerificationDuration = 24 * time.Hour
+	// defaultEmailChangeDuration is used when Config.EmailChangeDuration is
+	// not set
+	defaultEmailChangeDuration = 24 * time.Hour
+	// defaultAccountGracePeriod is used when Config.AccountGracePeriod is
+	// not set
+	defaultAccountGracePeriod = 30 * 24 * time.Hour
+	// defaultAccountPurgeInterval is used when Config.AccountPurgeInterval
+	// is not set
+	defaultAccountPurgeInterval = time.Hour
+	// defaultMailWorkers and defaultMailQueueSize are used when
+	// Config.MailWorkers and Config.MailQueueSize are not set
+	defaultMailWorkers   = 4
+	defaultMailQueueSize = 256
+	// defaultMailLimitPerAddress, defaultMailLimitPerIP and
+	// defaultMailLimitWindow are used when the matching Config.MailLimit
+	// fields are not set
+	defaultMailLimitPerAddress = 3
+	defaultMailLimitPerIP      = 20
+	defaultMailLimitWindow     = 15 * time.Minute
+	// defaultAccountRestoreLimitPerAddress, defaultAccountRestoreLimitPerIP
+	// and defaultAccountRestoreLimitWindow are used when the matching
+	// Config.AccountRestoreLimit fields are not set
+	defaultAccountRestoreLimitPerAddress = 5
+	defaultAccountRestoreLimitPerIP      = 20
+	defaultAccountRestoreLimitWindow     = 15 * time.Minute
+)
+
 type Server struct {
-	app        *fiber.App
-	auth       db.Auth
-	tokenMaker token.Maker
-	config     Config
+	app         *fiber.App
+	auth        db.Auth
+	tokenMaker  token.Maker
+	revocations token.RevocationStore
+	// introspectionClients maps client IDs to secrets
+	introspectionClients map[string]string
+	// cookies is nil unless tokens are sent in cookies
+	cookies *middleware.CookieConfig
+	roles   services.RoleService
+	orgs    services.OrganizationService
+	// statuses tells the middleware which accounts are blocked
+	statuses services.UserStatusService
+	// authorizer checks roles, permissions and scopes
+	authorizer *middleware.Authorizer
+	mailer     mailer.Mailer
+	// mails sends emails in the background within the configured limits
+	mails  *handlers.MailQueue
+	config Config
 }
 
+// RoleStore loads the current roles and permissions of a user, see
+// Server.UseRoleStore
+type RoleStore = middleware.RoleStore
+
+// Tenant is the organization a request acts for, see Server.TenantMiddleware
+type Tenant = middleware.Tenant
+
 func NewAuthServer(app *fiber.App, dbObj *pgxpool.Pool, config Config) (*Server, error) {
-	tokenMaker, err := token.NewPasetoMaker(config.TokenSymmetricKey)
+	tokenMaker, err := newTokenMaker(config)
 	if err != nil {
 		return nil, fmt.Errorf("cannot create token maker: %w", err)
 	}
 
+	auth := db.NewAuth(dbObj)
+	revocations, err := newRevocationStore(config, auth)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create revocation store: %w", err)
+	}
+
+	introspectionClients, err := parseClientCredentials(config.IntrospectionClients)
+	if err != nil {
+		return nil, fmt.Errorf("invalid introspection clients: %w", err)
+	}
+
+	cookies, err := newCookieConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cookie settings: %w", err)
+	}
+
+	switch config.OptionalAuthPolicy {
+	case "", OptionalAuthIgnore, OptionalAuthRejectExpired, OptionalAuthReject:
+	default:
+		return nil, fmt.Errorf("unsupported optional auth policy %q", config.OptionalAuthPolicy)
+	}
+	switch config.UnverifiedLoginPolicy {
+	case "", UnverifiedLoginAllow, UnverifiedLoginRestrict, UnverifiedLoginDeny:
+	default:
+		return nil, fmt.Errorf("unsupported unverified login policy %q", config.UnverifiedLoginPolicy)
+	}
+
+	m, err := newMailer(config)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create mailer: %w", err)
+	}
+
+	roles := services.NewRoleManager(auth)
 	server := &Server{
-		app:        app,
-		auth:       db.NewAuth(dbObj),
-		tokenMaker: tokenMaker,
-		config:     config,
+		app:                  app,
+		auth:                 auth,
+		tokenMaker:           tokenMaker,
+		revocations:          revocations,
+		introspectionClients: introspectionClients,
+		cookies:              cookies,
+		roles:                roles,
+		orgs:                 services.NewOrganizationManager(auth),
+		statuses:             services.NewUserStatusManager(auth),
+		authorizer:           middleware.NewAuthorizer(roles),
+		mailer:               m,
+		mails:                newMailQueue(config),
+		config:               config,
 	}
 	return server, nil
 }
 
-// SetupRoutes registers authentication routes (register, login, check-auth-user)
+// parseClientCredentials parses client_id:client_secret pairs, skipping
+// empty entries
+func parseClientCredentials(entries []string) (map[string]string, error) {
+	clients := make(map[string]string, len(entries))
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		id, secret, found := strings.Cut(entry, ":")
+		if !found || id == "" || secret == "" {
+			return nil, fmt.Errorf("expected client_id:client_secret, got %q", entry)
+		}
+		if _, exists := clients[id]; exists {
+			return nil, fmt.Errorf("duplicate client %q", id)
+		}
+		clients[id] = secret
+	}
+	return clients, nil
+}
+
+// newCookieConfig describes the token cookies for the cookie and both
+// transports, filling in defaults for unset names, path and SameSite. It
+// returns nil for the header transport.
+func newCookieConfig(config Config) (*middleware.CookieConfig, error) {
+	switch config.TokenTransport {
+	case "", TokenTransportHeader:
+		return nil, nil
+	case TokenTransportCookie, TokenTransportBoth:
+	default:
+		return nil, fmt.Errorf("unsupported token transport %q", config.TokenTransport)
+	}
+
+	cookies := &middleware.CookieConfig{
+		AccessName:  valueOrDefault(config.CookieName, "access_token"),
+		RefreshName: valueOrDefault(config.RefreshCookieName, "refresh_token"),
+		CSRFName:    valueOrDefault(config.CSRFCookieName, "csrf_token"),
+		Domain:      config.CookieDomain,
+		Path:        valueOrDefault(config.CookiePath, "/"),
+		RefreshPath: "/auth",
+		SameSite:    valueOrDefault(config.CookieSameSite, fiber.CookieSameSiteLaxMode),
+	}
+	switch strings.ToLower(cookies.SameSite) {
+	case fiber.CookieSameSiteStrictMode, fiber.CookieSameSiteLaxMode, fiber.CookieSameSiteNoneMode:
+	default:
+		return nil, fmt.Errorf("unsupported SameSite mode %q", cookies.SameSite)
+	}
+	return cookies, nil
+}
+
+func valueOrDefault(value, defaultValue string) string {
+	if value == "" {
+		return defaultValue
+	}
+	return value
+}
+
+func intOrDefault(value, defaultValue int) int {
+	if value == 0 {
+		return defaultValue
+	}
+	return value
+}
+
+func durationOrDefault(value, defaultValue time.Duration) time.Duration {
+	if value == 0 {
+		return defaultValue
+	}
+	return value
+}
+
+// newMailer builds the mailer selected by config.Mailer. It must be set:
+// a server that only logs reset and verification links would leak them, so
+// logging is only used when asked for.
+func newMailer(config Config) (mailer.Mailer, error) {
+	switch config.Mailer {
+	case "":
+		return nil, fmt.Errorf("no mailer configured, set MAILER to %s, %s or %s", MailerSMTP, MailerLog, MailerMemory)
+	case MailerLog:
+		return mailer.NewLogMailer(nil), nil
+	case MailerMemory:
+		return mailer.NewMemoryMailer(), nil
+	case MailerSMTP:
+		return mailer.NewSMTPMailer(mailer.SMTPConfig{
+			Host:           config.SMTPHost,
+			Port:           config.SMTPPort,
+			Username:       config.SMTPUsername,
+			Password:       config.SMTPPassword,
+			From:           config.MailFrom,
+			AllowPlaintext: config.SMTPAllowPlaintext,
+		})
+	}
+	return nil, fmt.Errorf("unsupported mailer %q", config.Mailer)
+}
+
+// newMailQueue builds the queue emails are sent through, filling in
+// defaults for unset limits. Negative limits are not enforced.
+func newMailQueue(config Config) *handlers.MailQueue {
+	return handlers.NewMailQueue(handlers.MailQueueConfig{
+		Workers:    intOrDefault(config.MailWorkers, defaultMailWorkers),
+		Size:       intOrDefault(config.MailQueueSize, defaultMailQueueSize),
+		PerAddress: intOrDefault(config.MailLimitPerAddress, defaultMailLimitPerAddress),
+		PerIP:      intOrDefault(config.MailLimitPerIP, defaultMailLimitPerIP),
+		Window:     durationOrDefault(config.MailLimitWindow, defaultMailLimitWindow),
+	})
+}
+
+// newRevocationStore builds the store selected by config.RevocationStore,
+// defaulting to Postgres when it is not set
+func newRevocationStore(config Config, auth db.Auth) (token.RevocationStore, error) {
+	switch config.RevocationStore {
+	case "", RevocationStorePostgres:
+		return db.NewRevocationStore(auth), nil
+	case RevocationStoreMemory:
+		return token.NewMemoryRevocationStore(), nil
+	}
+	return nil, fmt.Errorf("unsupported revocation store %q", config.RevocationStore)
+}
+
+// newTokenMaker builds the token maker selected by config.TokenMaker,
+// defaulting to PASETO when it is not set
+func newTokenMaker(config Config) (token.Maker, error) {
+	opts := []token.MakerOption{token.WithLeeway(config.TokenLeeway)}
+
+	switch config.TokenMaker {
+	case "", TokenMakerPaseto:
+		opts = append(opts, token.WithRetiredKeys(config.RetiredSymmetricKeys...))
+		return token.NewPasetoMaker(config.TokenSymmetricKey, opts...)
+	case TokenMakerPasetoPublic:
+		retiredKeys, err := loadPublicKeys(config.RetiredPublicKeyPaths)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, token.WithRetiredPublicKeys(retiredKeys...))
+		privateKey, err := token.LoadPrivateKey(config.TokenPrivateKeyPath)
+		if err != nil {
+			return nil, err
+		}
+		edKey, ok := privateKey.(ed25519.PrivateKey)
+		if !ok {
+			return nil, fmt.Errorf("%s requires an Ed25519 private key", TokenMakerPasetoPublic)
+		}
+		version := config.PasetoPublicVersion
+		if version == "" {
+			version = token.PasetoVersion4
+		}
+		return token.NewPasetoPublicMaker(version, edKey, opts...)
+	case TokenMakerJWT:
+		if config.JWTAlgorithm == token.AlgorithmHS256 {
+			opts = append(opts, token.WithRetiredKeys(config.RetiredSymmetricKeys...))
+			return token.NewJWTMaker(config.JWTAlgorithm, []byte(config.TokenSymmetricKey), opts...)
+		}
+		retiredKeys, err := loadPublicKeys(config.RetiredPublicKeyPaths)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, token.WithRetiredPublicKeys(retiredKeys...))
+		privateKey, err := token.LoadPrivateKey(config.TokenPrivateKeyPath)
+		if err != nil {
+			return nil, err
+		}
+		return token.NewJWTMaker(config.JWTAlgorithm, privateKey, opts...)
+	}
+	return nil, fmt.Errorf("unsupported token maker %q", config.TokenMaker)
+}
+
+// loadPublicKeys reads the PEM public keys at paths, skipping empty entries
+func loadPublicKeys(paths []string) ([]crypto.PublicKey, error) {
+	var keys []crypto.PublicKey
+	for _, path := range paths {
+		if path == "" {
+			continue
+		}
+		key, err := token.LoadPublicKey(path)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// SetupRoutes registers authentication routes (register, login, refresh, logout, check-auth-user)
 //
 // Public Routes:
 //
 //	POST /auth/register       → Register new user
 //	POST /auth/login          → Login user
+//	POST /auth/refresh        → Rotate refresh token and issue new access token
+//	POST /auth/introspect     → Report whether a token is active (client credentials)
+//	POST /auth/password/forgot → Email a password reset link
+//	POST /auth/password/reset  → Set a new password with a reset token
+//	GET  /auth/verify-email    → Verify an email with the token of a verification link
+//	POST /auth/verify-email    → Same, with the token in the body
+//	POST /auth/verify-email/resend → Email a new verification link
+//	GET  /auth/email/confirm   → Apply an email change with the link sent to the new address
+//	POST /auth/email/confirm   → Same, with the token in the body
+//	GET  /auth/email/cancel    → Cancel or undo an email change with the link sent to the old address
+//	POST /auth/email/cancel    → Same, with the token in the body
+//	POST /auth/restore         → Restore a deleted account during its grace period
+//	GET  /.well-known/jwks.json → Public keys to verify tokens with
 //
-// Protected Routes:
+// Protected Routes (the first four also accept restricted tokens of
+// unverified users):
 //
 //	GET  /auth/me             → Get current authenticated user info
+//	POST /auth/logout         → Revoke current token and its session
+//	POST /auth/logout-all     → Revoke every token and session of the user
+//	DELETE /auth/me           → Delete the account, restorable until the grace period ends
+//	PATCH /auth/me            → Update profile fields, given the updated_at last read
+//	POST /auth/switch-org     → Act for another organization, re-issuing the access token
+//	POST /auth/password       → Change password, signing out sessions started before
+//	POST /auth/email/change   → Request an email change, confirmed from the new address
+//
+// Organization Routes:
+//
+//	GET    /auth/orgs                            → List organizations of the user
+//	POST   /auth/orgs                            → Create an organization owned by the user
+//	POST   /auth/orgs/invitations/accept         → Join an organization with an invitation token
+//	GET    /auth/orgs/:org_id/members            → List members
+//	POST   /auth/orgs/:org_id/invitations        → Invite a user by email (owner, admin)
+//	PATCH  /auth/orgs/:org_id/members/:user_id   → Change the role of a member (owner)
+//	DELETE /auth/orgs/:org_id/members/:user_id   → Remove a member, or leave
+//
+// Admin Routes (require the rbac:manage permission):
+//
+//	GET    /auth/admin/roles                           → List roles
+//	POST   /auth/admin/roles                           → Create a role
+//	DELETE /auth/admin/roles/:role                     → Delete a role
+//	GET    /auth/admin/roles/:role/permissions         → List permissions of a role
+//	POST   /auth/admin/roles/:role/permissions         → Grant a permission to a role
+//	DELETE /auth/admin/roles/:role/permissions/:permission → Revoke a permission from a role
+//	GET    /auth/admin/permissions                     → List permissions
+//	POST   /auth/admin/permissions                     → Create a permission
+//	DELETE /auth/admin/permissions/:permission         → Delete a permission
+//	GET    /auth/admin/users/:id/roles                 → List roles and permissions of a user
+//	POST   /auth/admin/users/:id/roles                 → Assign a role to a user
+//	DELETE /auth/admin/users/:id/roles/:role           → Revoke a role from a user
+//	PUT    /auth/admin/users/:id/status                → Suspend, lock or reinstate a user
+//
+// Suspended, locked and deleted accounts are refused by Login, refresh and
+// every protected route with 403 and the code account_suspended,
+// account_locked or account_deleted.
 func (s *Server) SetupRoutes() {
-	userHandler := handlers.NewUserHandler(s.app, s.auth, s.tokenMaker, s.config.AccessTokenDuration)
+	verificationDuration := s.config.EmailVerificationDuration
+	if verificationDuration <= 0 {
+		verificationDuration = defaultEmailVerificationDuration
+	}
+	policy := handlers.UnverifiedLoginPolicy(valueOrDefault(s.config.UnverifiedLoginPolicy, UnverifiedLoginRestrict))
+	verification := handlers.EmailVerificationConfig{
+		Mailer:        s.mailer,
+		TokenDuration: verificationDuration,
+		URL:           s.config.EmailVerificationURL,
+		Issuer:        s.config.TokenIssuer,
+		Policy:        policy,
+		Queue:         s.mails,
+	}
+
+	userHandler := handlers.NewUserHandler(s.app, s.auth, s.tokenMaker, s.revocations, handlers.TokenConfig{
+		AccessTokenDuration:  s.config.AccessTokenDuration,
+		RefreshTokenDuration: s.config.RefreshTokenDuration,
+		Issuer:               s.config.TokenIssuer,
+		Audience:             s.config.TokenAudience,
+		Cookies:              s.cookies,
+		CookiesOnly:          s.config.TokenTransport == TokenTransportCookie,
+	}, verification)
+
+	jwksMaxAge := s.config.JWKSMaxAge
+	if jwksMaxAge <= 0 {
+		jwksMaxAge = defaultJWKSMaxAge
+	}
+	keySetHandler := handlers.NewKeySetHandler(s.tokenMaker, jwksMaxAge)
+	s.app.Get("/.well-known/jwks.json", keySetHandler.JWKS)
 
 	// Public auth routes at /auth prefix
 	authGroup := s.app.Group("/auth")
 	authGroup.Post("/register", userHandler.Register)
 	authGroup.Post("/login", userHandler.Login)
+	authGroup.Post("/refresh", userHandler.RefreshToken)
+
+	// Token introspection for services that cannot verify tokens themselves
+	introspectionHandler := handlers.NewIntrospectionHandler(s.tokenMaker, s.revocations, s.statuses, s.introspectionClients, s.issuerOptions()...)
+	authGroup.Post("/introspect", introspectionHandler.Introspect)
+
+	// Forgotten passwords
+	resetDuration := s.config.PasswordResetDuration
+	if resetDuration <= 0 {
+		resetDuration = defaultPasswordResetDuration
+	}
+	passwordHandler := handlers.NewPasswordHandler(s.auth, s.revocations, s.mailer, handlers.PasswordResetConfig{
+		TokenDuration:       resetDuration,
+		URL:                 s.config.PasswordResetURL,
+		AccessTokenDuration: s.config.AccessTokenDuration,
+		Queue:               s.mails,
+	})
+	authGroup.Post("/password/forgot", passwordHandler.ForgotPassword)
+	authGroup.Post("/password/reset", passwordHandler.ResetPassword)
+
+	// Email verification
+	verificationHandler := handlers.NewVerificationHandler(s.auth, s.tokenMaker, verification)
+	authGroup.Get("/verify-email", verificationHandler.VerifyEmail)
+	authGroup.Post("/verify-email", verificationHandler.VerifyEmail)
+	authGroup.Post("/verify-email/resend", verificationHandler.ResendVerification)
+
+	// Email changes, confirmed from the new address and cancellable from the old one
+	emailChangeDuration := s.config.EmailChangeDuration
+	if emailChangeDuration <= 0 {
+		emailChangeDuration = defaultEmailChangeDuration
+	}
+	emailChangeHandler := handlers.NewEmailChangeHandler(s.auth, s.revocations, handlers.EmailChangeConfig{
+		Mailer:              s.mailer,
+		TokenDuration:       emailChangeDuration,
+		ConfirmURL:          s.config.EmailChangeConfirmURL,
+		CancelURL:           s.config.EmailChangeCancelURL,
+		AccessTokenDuration: s.config.AccessTokenDuration,
+		Queue:               s.mails,
+	})
+	authGroup.Get("/email/confirm", emailChangeHandler.ConfirmEmailChange)
+	authGroup.Post("/email/confirm", emailChangeHandler.ConfirmEmailChange)
+	authGroup.Get("/email/cancel", emailChangeHandler.CancelEmailChange)
+	authGroup.Post("/email/cancel", emailChangeHandler.CancelEmailChange)
+
+	// Account deletion, restorable until purged
+	accountHandler := handlers.NewAccountHandler(s.auth, s.revocations, handlers.AccountDeletionConfig{
+		Mailer:                 s.mailer,
+		GracePeriod:            s.accountGracePeriod(),
+		AccessTokenDuration:    s.config.AccessTokenDuration,
+		Cookies:                s.cookies,
+		Queue:                  s.mails,
+		RestoreLimitPerAddress: intOrDefault(s.config.AccountRestoreLimitPerAddress, defaultAccountRestoreLimitPerAddress),
+		RestoreLimitPerIP:      intOrDefault(s.config.AccountRestoreLimitPerIP, defaultAccountRestoreLimitPerIP),
+		RestoreLimitWindow:     durationOrDefault(s.config.AccountRestoreLimitWindow, defaultAccountRestoreLimitWindow),
+	})
+	authGroup.Post("/restore", accountHandler.RestoreAccount)
 
 	// Protected auth routes
-	authGroup.Get("/me", s.AuthMiddleware(), userHandler.CheckAuthUser)
+	authGroup.Get("/me", s.RestrictedAuthMiddleware(), userHandler.CheckAuthUser)
+	authGroup.Patch("/me", s.AuthMiddleware(), userHandler.UpdateProfile)
+	authGroup.Post("/logout", s.RestrictedAuthMiddleware(), userHandler.Logout)
+	authGroup.Post("/logout-all", s.RestrictedAuthMiddleware(), userHandler.LogoutAll)
+	authGroup.Delete("/me", s.RestrictedAuthMiddleware(), accountHandler.DeleteAccount)
+	authGroup.Post("/switch-org", s.AuthMiddleware(), userHandler.SwitchOrganization)
+	authGroup.Post("/password", s.AuthMiddleware(), userHandler.ChangePassword)
+	authGroup.Post("/email/change", s.AuthMiddleware(), emailChangeHandler.ChangeEmail)
+
+	// Organizations, memberships and invitations
+	invitationDuration := s.config.InvitationDuration
+	if invitationDuration <= 0 {
+		invitationDuration = defaultInvitationDuration
+	}
+	organizationHandler := handlers.NewOrganizationHandler(s.orgs, invitationDuration)
+	orgs := authGroup.Group("/orgs", s.AuthMiddleware())
+	orgs.Get("/", organizationHandler.ListOrganizations)
+	orgs.Post("/", organizationHandler.CreateOrganization)
+	orgs.Post("/invitations/accept", organizationHandler.AcceptInvitation)
+	orgs.Get("/:org_id/members", organizationHandler.ListMembers)
+	orgs.Post("/:org_id/invitations", organizationHandler.Invite)
+	orgs.Patch("/:org_id/members/:user_id", organizationHandler.UpdateMemberRole)
+	orgs.Delete("/:org_id/members/:user_id", organizationHandler.RemoveMember)
+
+	// Role and permission management
+	rbacHandler := handlers.NewRBACHandler(s.roles)
+	admin := authGroup.Group("/admin", s.AuthMiddleware(), s.RequirePermissions(services.ManageRBACPermission))
+	admin.Get("/roles", rbacHandler.ListRoles)
+	admin.Post("/roles", rbacHandler.CreateRole)
+	admin.Delete("/roles/:role", rbacHandler.DeleteRole)
+	admin.Get("/roles/:role/permissions", rbacHandler.ListRolePermissions)
+	admin.Post("/roles/:role/permissions", rbacHandler.GrantPermission)
+	admin.Delete("/roles/:role/permissions/:permission", rbacHandler.RevokePermission)
+	admin.Get("/permissions", rbacHandler.ListPermissions)
+	admin.Post("/permissions", rbacHandler.CreatePermission)
+	admin.Delete("/permissions/:permission", rbacHandler.DeletePermission)
+	admin.Get("/users/:id/roles", rbacHandler.ListUserRoles)
+	admin.Post("/users/:id/roles", rbacHandler.AssignRole)
+	admin.Delete("/users/:id/roles/:role", rbacHandler.RevokeRole)
+
+	// Account status: suspending, locking and reinstating users
+	userStatusHandler := handlers.NewUserStatusHandler(s.auth, s.revocations, s.config.AccessTokenDuration)
+	admin.Put("/users/:id/status", userStatusHandler.UpdateUserStatus)
+}
+
+// UseMailer replaces the mailer selected by Config.Mailer, for example with
+// one backed by an email API, or a mailer.MemoryMailer in tests. It must be
+// called before SetupRoutes.
+func (s *Server) UseMailer(m mailer.Mailer) {
+	s.mailer = m
+}
+
+// BootstrapSuperAdmin creates the super_admin role and the rbac:manage
+// permission when missing, and assigns the role to the user registered with
+// Config.SuperAdminEmail unless an active user already holds it. The user
+// must have verified the email and have an active account, so nobody gets
+// the role by registering the address first. It is meant to run on every
+// start; with no email configured only the role and permission are created.
+func (s *Server) BootstrapSuperAdmin(ctx context.Context) error {
+	return s.roles.BootstrapSuperAdmin(ctx, s.config.SuperAdminEmail)
+}
+
+// KeyRing returns the key ring of the token maker so signing keys can be
+// added, activated and removed without restarting the server. It returns nil
+// when the configured maker does not support key rotation.
+//
+// Example usage:
+//
+//	ring := server.KeyRing()
+//	ring.AddKey("2025-02", []byte(newKey))
+//	ring.ActivateKey("2025-02")
+func (s *Server) KeyRing() *token.KeyRing {
+	if rotator, ok := s.tokenMaker.(token.KeyRotator); ok {
+		return rotator.KeyRing()
+	}
+	return nil
+}
+
+// StartRevocationCleanup periodically deletes revoked token entries whose
+// tokens have expired anyway. It returns immediately and stops when ctx is
+// cancelled.
+func (s *Server) StartRevocationCleanup(ctx context.Context) {
+	interval := s.config.RevocationCleanupInterval
+	if interval <= 0 {
+		interval = defaultRevocationCleanupInterval
+	}
+	go token.RunRevocationCleanup(ctx, s.revocations, interval)
+}
+
+// StartAccountPurge periodically removes accounts deleted longer ago than
+// the grace period, along with their sessions, memberships and roles. It
+// returns immediately and stops when ctx is cancelled. Without it deleted
+// accounts are kept, hidden, for good.
+func (s *Server) StartAccountPurge(ctx context.Context) {
+	interval := s.config.AccountPurgeInterval
+	if interval <= 0 {
+		interval = defaultAccountPurgeInterval
+	}
+	go services.RunAccountPurge(ctx, services.NewAccountManager(s.auth, s.accountGracePeriod()), interval)
+}
+
+func (s *Server) accountGracePeriod() time.Duration {
+	if s.config.AccountGracePeriod <= 0 {
+		return defaultAccountGracePeriod
+	}
+	return s.config.AccountGracePeriod
 }
 
 // AuthMiddleware returns the authentication middleware that can be used
-// to protect custom routes in the application.
+// to protect custom routes in the application. The token is read from the
+// Authorization header unless extractors are given, which are tried in
+// order. With cookie transport the token cookie is tried last, and
+// state-changing requests authenticated by cookie must echo the CSRF cookie
+// in the X-CSRF-Token header.
 //
 // Example usage:
 //
 //	server.SetupRoutes()
 //	app.Get("/protected", server.AuthMiddleware(), myHandler)
-func (s *Server) AuthMiddleware() fiber.Handler {
-	return middleware.AuthMiddleware(s.tokenMaker)
+//	app.Get("/ws", server.AuthMiddleware(auth.FromAuthHeader(), auth.FromQuery("access_token")), wsHandler)
+func (s *Server) AuthMiddleware(extractors ...Extractor) fiber.Handler {
+	return middleware.AuthMiddleware(s.tokenMaker, s.middlewareOptions(extractors)...)
+}
+
+// RestrictedAuthMiddleware works like AuthMiddleware but also accepts the
+// restricted tokens given to users who have not verified their email when
+// Config.UnverifiedLoginPolicy is restrict. Everywhere else such tokens are
+// refused with 403 and the email_not_verified code. Handlers can tell them
+// apart by the purpose of the payload, token.PurposeRestricted.
+//
+// Example usage:
+//
+//	app.Get("/onboarding", server.RestrictedAuthMiddleware(), onboardingHandler)
+func (s *Server) RestrictedAuthMiddleware(extractors ...Extractor) fiber.Handler {
+	opts := append(s.middlewareOptions(extractors), middleware.WithRestrictedTokens())
+	return middleware.AuthMiddleware(s.tokenMaker, opts...)
+}
+
+// OptionalAuthMiddleware returns a middleware for routes that serve
+// anonymous visitors too. When a valid token is present its payload is
+// available through the same helpers as with AuthMiddleware; otherwise the
+// request continues without one. Config.OptionalAuthPolicy decides whether
+// invalid or expired tokens are ignored or rejected.
+//
+// Example usage:
+//
+//	app.Get("/feed", server.OptionalAuthMiddleware(), feedHandler)
+func (s *Server) OptionalAuthMiddleware(extractors ...Extractor) fiber.Handler {
+	opts := append(s.middlewareOptions(extractors),
+		middleware.WithInvalidTokenPolicy(middleware.InvalidTokenPolicy(s.config.OptionalAuthPolicy)),
+	)
+	return middleware.OptionalAuthMiddleware(s.tokenMaker, opts...)
+}
+
+func (s *Server) middlewareOptions(extractors []Extractor) []middleware.Option {
+	opts := []middleware.Option{
+		middleware.WithRevocationStore(s.revocations),
+		middleware.WithVerifyOptions(s.verifyOptions()...),
+		middleware.WithExtractors(extractors...),
+		middleware.WithAccountStatusStore(s.statuses),
+	}
+	if s.cookies != nil {
+		opts = append(opts, middleware.WithCookie(*s.cookies))
+	}
+	return opts
+}
+
+// verifyOptions returns the claim checks every token accepted by the
+// server must pass
+func (s *Server) verifyOptions() []token.VerifyOption {
+	opts := s.issuerOptions()
+	if len(s.config.TokenAudience) > 0 {
+		opts = append(opts, token.ExpectAudience(s.config.TokenAudience[0]))
+	}
+	return opts
+}
+
+// issuerOptions checks that tokens were issued by this server. Introspection
+// only applies these, as the asking service may be any of the audiences.
+func (s *Server) issuerOptions() []token.VerifyOption {
+	var opts []token.VerifyOption
+	if s.config.TokenIssuer != "" {
+		opts = append(opts, token.ExpectIssuer(s.config.TokenIssuer))
+	}
+	return opts
+}
+
+// UseRoleStore makes RequireRoles and RequirePermissions look roles and
+// permissions up in store instead of the roles tables managed by the admin
+// endpoints. It must be called before those handlers are created.
+func (s *Server) UseRoleStore(store RoleStore) {
+	s.authorizer = middleware.NewAuthorizer(store)
+}
+
+// RequireRoles returns a handler that responds 403 unless the user has at
+// least one of roles. It must run after AuthMiddleware, and panics when no
+// role is given.
+//
+// Example usage:
+//
+//	app.Delete("/posts/:id", server.AuthMiddleware(), server.RequireRoles("admin", "editor"), deletePost)
+func (s *Server) RequireRoles(roles ...string) fiber.Handler {
+	return s.authorizer.RequireRoles(roles...)
+}
+
+// RequirePermissions returns a handler that responds 403 unless the user has
+// every one of permissions, as loaded from the RoleStore. It must run after
+// AuthMiddleware, and panics when no permission is given.
+func (s *Server) RequirePermissions(permissions ...string) fiber.Handler {
+	return s.authorizer.RequirePermissions(permissions...)
+}
+
+// RequireScopes returns a handler that responds 403 unless the token was
+// granted every one of scopes. It must run after AuthMiddleware, and panics
+// when no scope is given.
+func (s *Server) RequireScopes(scopes ...string) fiber.Handler {
+	return s.authorizer.RequireScopes(scopes...)
+}
+
+// TenantMiddleware returns a handler that scopes the request to the
+// organization the token acts for, as chosen with POST /auth/switch-org.
+// Requests without an active organization, or whose user is no longer a
+// member of it, are rejected with 403. When roles are given the user must
+// have one of them in the organization. It must run after AuthMiddleware;
+// handlers read the organization with GetTenant.
+//
+// Example usage:
+//
+//	projects := server.ProtectedGroupWith("/projects", server.TenantMiddleware())
+//	app.Delete("/settings", server.AuthMiddleware(), server.TenantMiddleware("owner", "admin"), updateSettings)
+func (s *Server) TenantMiddleware(roles ...string) fiber.Handler {
+	return middleware.TenantMiddleware(s.orgs, roles...)
 }
 
 // ProtectedGroup creates a new route group with authentication middleware applied.
@@ -77,3 +726,28 @@ func (s *Server) AuthMiddleware() fiber.Handler {
 func (s *Server) ProtectedGroup(prefix string) fiber.Router {
 	return s.app.Group(prefix, s.AuthMiddleware())
 }
+
+// ProtectedGroupWith creates a route group with authentication middleware
+// followed by authorization requirements, every one of which must pass.
+//
+// Example usage:
+//
+//	admin := server.ProtectedGroupWith("/admin", server.RequireRoles("admin"))
+//	admin.Get("/users", listUsersHandler)
+func (s *Server) ProtectedGroupWith(prefix string, requirements ...fiber.Handler) fiber.Router {
+	chain := append([]fiber.Handler{s.AuthMiddleware()}, requirements...)
+	return s.app.Group(prefix, chain...)
+}
+
+// GetTenant returns the organization stored by TenantMiddleware
+func GetTenant(c *fiber.Ctx) (*Tenant, error) {
+	return middleware.GetTenant(c)
+}
+
+// GetAuthPayload returns the payload of the access token stored by
+// AuthMiddleware or OptionalAuthMiddleware. It returns an error when the
+// request was not authenticated, which after OptionalAuthMiddleware means
+// an anonymous visitor.
+func GetAuthPayload(c *fiber.Ctx) (*token.Payload, error) {
+	return middleware.GetAuthPayload(c)
+}
@@ -6,11 +6,105 @@ import (
 	"github.com/spf13/viper"
 )
 
+// Supported values for Config.TokenMaker
+const (
+	TokenMakerPaseto       = "paseto"
+	TokenMakerPasetoPublic = "paseto-public"
+	TokenMakerJWT          = "jwt"
+)
+
+// Supported values for Config.RevocationStore
+const (
+	RevocationStorePostgres = "postgres"
+	RevocationStoreMemory   = "memory"
+)
+
+// Supported values for Config.TokenTransport
+const (
+	TokenTransportHeader = "header"
+	TokenTransportCookie = "cookie"
+	TokenTransportBoth   = "both"
+)
+
+// Supported values for Config.OptionalAuthPolicy, deciding what
+// Server.OptionalAuthMiddleware does with invalid or expired tokens
+const (
+	OptionalAuthIgnore        = "ignore"
+	OptionalAuthRejectExpired = "reject-expired"
+	OptionalAuthReject        = "reject"
+)
+
+// Supported values for Config.Mailer
+const (
+	MailerSMTP   = "smtp"
+	MailerLog    = "log"
+	MailerMemory = "memory"
+)
+
+// Supported values for Config.UnverifiedLoginPolicy, deciding what users
+// who have not verified their email get when logging in. Restricted tokens
+// are the default, so an address nobody proved to own does not unlock the
+// whole API.
+const (
+	UnverifiedLoginAllow    = "allow"
+	UnverifiedLoginRestrict = "restrict"
+	UnverifiedLoginDeny     = "deny"
+)
+
 type Config struct {
-	DBSource            string        `mapstructure:"DB_SOURCE"`
-	ServerAddress       string        `mapstructure:"SERVER_ADDRESS"`
-	TokenSymmetricKey   string        `mapstructure:"TOKEN_SYMMETRIC_KEY"`
-	AccessTokenDuration time.Duration `mapstructure:"ACCESS_TOKEN_DURATION"`
+	DBSource                      string        `mapstructure:"DB_SOURCE"`
+	ServerAddress                 string        `mapstructure:"SERVER_ADDRESS"`
+	TokenMaker                    string        `mapstructure:"TOKEN_MAKER"`
+	TokenSymmetricKey             string        `mapstructure:"TOKEN_SYMMETRIC_KEY"`
+	RetiredSymmetricKeys          []string      `mapstructure:"TOKEN_RETIRED_SYMMETRIC_KEYS"`
+	TokenPrivateKeyPath           string        `mapstructure:"TOKEN_PRIVATE_KEY_PATH"`
+	RetiredPublicKeyPaths         []string      `mapstructure:"TOKEN_RETIRED_PUBLIC_KEY_PATHS"`
+	PasetoPublicVersion           string        `mapstructure:"PASETO_PUBLIC_VERSION"`
+	JWTAlgorithm                  string        `mapstructure:"JWT_ALGORITHM"`
+	AccessTokenDuration           time.Duration `mapstructure:"ACCESS_TOKEN_DURATION"`
+	RefreshTokenDuration          time.Duration `mapstructure:"REFRESH_TOKEN_DURATION"`
+	TokenLeeway                   time.Duration `mapstructure:"TOKEN_LEEWAY"`
+	TokenIssuer                   string        `mapstructure:"TOKEN_ISSUER"`
+	TokenAudience                 []string      `mapstructure:"TOKEN_AUDIENCE"`
+	RevocationStore               string        `mapstructure:"REVOCATION_STORE"`
+	RevocationCleanupInterval     time.Duration `mapstructure:"REVOCATION_CLEANUP_INTERVAL"`
+	JWKSMaxAge                    time.Duration `mapstructure:"JWKS_MAX_AGE"`
+	IntrospectionClients          []string      `mapstructure:"INTROSPECTION_CLIENTS"`
+	TokenTransport                string        `mapstructure:"TOKEN_TRANSPORT"`
+	CookieName                    string        `mapstructure:"COOKIE_NAME"`
+	RefreshCookieName             string        `mapstructure:"REFRESH_COOKIE_NAME"`
+	CSRFCookieName                string        `mapstructure:"CSRF_COOKIE_NAME"`
+	CookieDomain                  string        `mapstructure:"COOKIE_DOMAIN"`
+	CookiePath                    string        `mapstructure:"COOKIE_PATH"`
+	CookieSameSite                string        `mapstructure:"COOKIE_SAME_SITE"`
+	OptionalAuthPolicy            string        `mapstructure:"OPTIONAL_AUTH_POLICY"`
+	SuperAdminEmail               string        `mapstructure:"SUPER_ADMIN_EMAIL"`
+	InvitationDuration            time.Duration `mapstructure:"INVITATION_DURATION"`
+	Mailer                        string        `mapstructure:"MAILER"`
+	SMTPHost                      string        `mapstructure:"SMTP_HOST"`
+	SMTPPort                      int           `mapstructure:"SMTP_PORT"`
+	SMTPUsername                  string        `mapstructure:"SMTP_USERNAME"`
+	SMTPPassword                  string        `mapstructure:"SMTP_PASSWORD"`
+	SMTPAllowPlaintext            bool          `mapstructure:"SMTP_ALLOW_PLAINTEXT"`
+	MailFrom                      string        `mapstructure:"MAIL_FROM"`
+	MailWorkers                   int           `mapstructure:"MAIL_WORKERS"`
+	MailQueueSize                 int           `mapstructure:"MAIL_QUEUE_SIZE"`
+	MailLimitPerAddress           int           `mapstructure:"MAIL_LIMIT_PER_ADDRESS"`
+	MailLimitPerIP                int           `mapstructure:"MAIL_LIMIT_PER_IP"`
+	MailLimitWindow               time.Duration `mapstructure:"MAIL_LIMIT_WINDOW"`
+	PasswordResetDuration         time.Duration `mapstructure:"PASSWORD_RESET_TOKEN_DURATION"`
+	PasswordResetURL              string        `mapstructure:"PASSWORD_RESET_URL"`
+	EmailVerificationDuration     time.Duration `mapstructure:"EMAIL_VERIFICATION_TOKEN_DURATION"`
+	EmailVerificationURL          string        `mapstructure:"EMAIL_VERIFICATION_URL"`
+	UnverifiedLoginPolicy         string        `mapstructure:"UNVERIFIED_LOGIN_POLICY"`
+	EmailChangeDuration           time.Duration `mapstructure:"EMAIL_CHANGE_TOKEN_DURATION"`
+	EmailChangeConfirmURL         string        `mapstructure:"EMAIL_CHANGE_CONFIRM_URL"`
+	EmailChangeCancelURL          string        `mapstructure:"EMAIL_CHANGE_CANCEL_URL"`
+	AccountGracePeriod            time.Duration `mapstructure:"ACCOUNT_DELETION_GRACE_PERIOD"`
+	AccountPurgeInterval          time.Duration `mapstructure:"ACCOUNT_PURGE_INTERVAL"`
+	AccountRestoreLimitPerAddress int           `mapstructure:"ACCOUNT_RESTORE_LIMIT_PER_ADDRESS"`
+	AccountRestoreLimitPerIP      int           `mapstructure:"ACCOUNT_RESTORE_LIMIT_PER_IP"`
+	AccountRestoreLimitWindow     time.Duration `mapstructure:"ACCOUNT_RESTORE_LIMIT_WINDOW"`
 }
 
 func LoadConfig(path string) (config Config, err error) {
@@ -37,6 +37,11 @@ func main() {
 
 	// Setup auth routes
 	server.SetupRoutes()
+	server.StartRevocationCleanup(context.Background())
+	server.StartAccountPurge(context.Background())
+	if err := server.BootstrapSuperAdmin(context.Background()); err != nil {
+		log.Printf("cannot bootstrap super admin: %v", err)
+	}
 
 	// Public route - no timeout
 	app.Get("/hi", sayHello)
@@ -44,10 +49,21 @@ func main() {
 	// Route with timeout wrapper - handler MUST respect context
 	app.Get("/hii", timeout.NewWithContext(sayHiWithTimeout, 3*time.Second))
 
+	// Public route that greets logged-in users by email
+	app.Get("/feed", server.OptionalAuthMiddleware(), sayHelloToUser)
+
 	// Protected routes
 	protected := server.ProtectedGroup("/api")
 	protected.Get("/users", sayHello)
 
+	// Routes only admins can reach
+	admin := server.ProtectedGroupWith("/admin", server.RequireRoles("admin"))
+	admin.Get("/stats", sayHello)
+
+	// Routes scoped to the organization chosen with POST /auth/switch-org
+	tenant := server.ProtectedGroupWith("/org", server.TenantMiddleware())
+	tenant.Get("/hello", sayHelloToTenant)
+
 	log.Printf("Starting server on %s", config.ServerAddress)
 	app.Listen(config.ServerAddress)
 }
@@ -58,6 +74,26 @@ func sayHello(c *fiber.Ctx) error {
 	})
 }
 
+func sayHelloToUser(c *fiber.Ctx) error {
+	payload, err := auth.GetAuthPayload(c)
+	if err != nil {
+		return sayHello(c)
+	}
+	return c.Status(fiber.StatusOK).JSON(&fiber.Map{
+		"message": "Hello " + payload.Email,
+	})
+}
+
+func sayHelloToTenant(c *fiber.Ctx) error {
+	tenant, err := auth.GetTenant(c)
+	if err != nil {
+		return err
+	}
+	return c.Status(fiber.StatusOK).JSON(&fiber.Map{
+		"message": "Hello " + tenant.Role + " of " + tenant.OrgID.String(),
+	})
+}
+
 // ✅ CORRECT: Handler that respects the timeout context
 func sayHiWithTimeout(c *fiber.Ctx) error {
 	// Use the helper function that checks context
@@ -0,0 +1,35 @@
+package auth
+
+import "github.com/suryansh74/auth-package/internal/middleware"
+
+// Extractor finds the access token in a request. Extractors passed to
+// Server.AuthMiddleware are tried in order and the first token found is
+// used. Any func(*fiber.Ctx) (string, error) can be one: return an empty
+// string to let the next extractor try, or an error to reject the request.
+type Extractor = middleware.Extractor
+
+// FromHeader reads the token from header. When scheme is set the header
+// must be "<scheme> <token>" and the scheme is matched case-insensitively.
+func FromHeader(header, scheme string) Extractor {
+	return middleware.FromHeader(header, scheme)
+}
+
+// FromAuthHeader reads the token from "Authorization: Bearer <token>",
+// which is what Server.AuthMiddleware does by default
+func FromAuthHeader() Extractor {
+	return middleware.FromAuthHeader()
+}
+
+// FromCookie reads the token from the cookie called name and, on
+// state-changing requests, requires the X-CSRF-Token header to match the
+// cookie called csrfName. The cookie transport of Config does this already
+// and needs no extractor.
+func FromCookie(name, csrfName string) Extractor {
+	return middleware.FromCookie(middleware.CookieConfig{AccessName: name, CSRFName: csrfName})
+}
+
+// FromQuery reads the token from a query parameter, for WebSocket
+// handshakes and download links
+func FromQuery(param string) Extractor {
+	return middleware.FromQuery(param)
+}
@@ -3,7 +3,56 @@ package apperrors
 import "errors"
 
 var (
-	ErrUserAlreadyExist = errors.New("user already exists")
-	UnExpectedError     = errors.New("unexpected error")
-	ErrUserNotFound     = errors.New("user not found for given email")
+	ErrUserAlreadyExist    = errors.New("user already exists")
+	UnExpectedError        = errors.New("unexpected error")
+	ErrUserNotFound        = errors.New("user not found for given email")
+	ErrInvalidRefreshToken = errors.New("refresh token is invalid")
+	ErrExpiredRefreshToken = errors.New("refresh token is expired")
+	ErrRefreshTokenReused  = errors.New("refresh token reuse detected")
+
+	ErrWeakPassword       = errors.New("password must be 8 to 72 characters long and contain a letter and a digit")
+	ErrIncorrectPassword  = errors.New("current password is incorrect")
+	ErrPasswordNotChanged = errors.New("new password must differ from the current one")
+	ErrInvalidResetToken  = errors.New("password reset token is invalid or expired")
+
+	ErrInvalidName      = errors.New("name must be 1 to 255 characters without control characters")
+	ErrNoProfileChanges = errors.New("no profile field to update")
+	ErrProfileConflict  = errors.New("profile was changed since it was read, reload it and try again")
+
+	ErrAccountNotRestorable = errors.New("account can no longer be restored")
+
+	ErrAccountSuspended        = errors.New("account is suspended")
+	ErrAccountLocked           = errors.New("account is locked, reset your password to unlock it")
+	ErrAccountDeleted          = errors.New("account is deleted")
+	ErrInvalidUserStatus       = errors.New("status must be active, suspended or locked")
+	ErrInvalidStatusTransition = errors.New("account cannot move to this status from its current one")
+	ErrInvalidStatusReason     = errors.New("reason is required to suspend or lock an account, up to 500 characters without control characters")
+	ErrInvalidSuspensionEnd    = errors.New("suspended_until must be in the future and is only valid for suspensions")
+	ErrUserStatusConflict      = errors.New("account status was changed meanwhile, reload it and try again")
+
+	ErrInvalidEmail       = errors.New("email address is invalid")
+	ErrEmailNotChanged    = errors.New("new email must differ from the current one")
+	ErrInvalidEmailChange = errors.New("email change link is invalid or expired")
+
+	ErrEmailNotVerified         = errors.New("email is not verified")
+	ErrInvalidVerificationToken = errors.New("verification link is invalid or expired")
+
+	ErrInvalidRBACName        = errors.New("name must be 1 to 100 characters of a-z, 0-9, '_', '-', '.' or ':'")
+	ErrRoleAlreadyExist       = errors.New("role already exists")
+	ErrRoleNotFound           = errors.New("role not found")
+	ErrPermissionAlreadyExist = errors.New("permission already exists")
+	ErrPermissionNotFound     = errors.New("permission not found")
+	ErrSuperAdminIneligible   = errors.New("super admin must have a verified email and an active account")
+
+	ErrInvalidOrganizationSlug      = errors.New("slug must be 1 to 100 characters of a-z, 0-9 or '-'")
+	ErrInvalidOrganizationName      = errors.New("organization name is required")
+	ErrInvalidOrganizationRole      = errors.New("role must be owner, admin or member")
+	ErrOrganizationAlreadyExist     = errors.New("organization already exists")
+	ErrOrganizationNotFound         = errors.New("organization not found")
+	ErrNotOrganizationMember        = errors.New("user is not a member of the organization")
+	ErrAlreadyOrganizationMember    = errors.New("user is already a member of the organization")
+	ErrInsufficientOrganizationRole = errors.New("insufficient organization role")
+	ErrLastOrganizationOwner        = errors.New("organization must keep at least one owner")
+	ErrInvalidInvitation            = errors.New("invitation is invalid or expired")
+	ErrInvitationEmailMismatch      = errors.New("invitation was sent to another email")
 )
@@ -1,17 +1,26 @@
 package db
 
 import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/suryansh74/auth-package/internal/db/sqlc"
 )
 
 type Auth interface {
 	sqlc.Querier
+	// ExecTx runs fn in a transaction, committed when fn returns nil and
+	// rolled back otherwise. Queries made through the Auth given to fn are
+	// part of the transaction, nested ExecTx calls included.
+	ExecTx(ctx context.Context, fn func(Auth) error) error
 }
 
 type AuthPsql struct {
 	*sqlc.Queries
 	connPool *pgxpool.Pool
+	// tx is the transaction the queries run in, if any
+	tx pgx.Tx
 }
 
 func NewAuth(db *pgxpool.Pool) Auth {
@@ -20,3 +29,25 @@ func NewAuth(db *pgxpool.Pool) Auth {
 		connPool: db,
 	}
 }
+
+func (auth *AuthPsql) ExecTx(ctx context.Context, fn func(Auth) error) error {
+	if auth.tx != nil {
+		return fn(auth)
+	}
+	tx, err := auth.connPool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	// rolling back a committed transaction does nothing
+	defer tx.Rollback(ctx)
+
+	err = fn(&AuthPsql{
+		Queries:  auth.Queries.WithTx(tx),
+		connPool: auth.connPool,
+		tx:       tx,
+	})
+	if err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
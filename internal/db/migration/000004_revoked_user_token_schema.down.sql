@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_revoked_user_tokens_expires_at;
+DROP TABLE IF EXISTS revoked_user_tokens;
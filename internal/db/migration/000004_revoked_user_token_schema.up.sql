@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS revoked_user_tokens (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    issued_before TIMESTAMP NOT NULL,
+    expires_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX idx_revoked_user_tokens_expires_at ON revoked_user_tokens(expires_at);
@@ -0,0 +1,10 @@
+ALTER TABLE users ADD COLUMN status VARCHAR(20) NOT NULL DEFAULT 'pending'
+    CHECK (status IN ('pending', 'active', 'suspended', 'locked', 'deleted'));
+-- why an admin suspended or locked the account
+ALTER TABLE users ADD COLUMN status_reason TEXT NULL;
+ALTER TABLE users ADD COLUMN status_changed_at TIMESTAMP NULL;
+-- suspensions without an end last until lifted
+ALTER TABLE users ADD COLUMN suspended_until TIMESTAMP NULL;
+
+UPDATE users SET status = 'active' WHERE email_verified_at IS NOT NULL;
+UPDATE users SET status = 'deleted', status_changed_at = deleted_at WHERE deleted_at IS NOT NULL;
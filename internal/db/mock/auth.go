@@ -10,6 +10,7 @@ import (
 
 	gomock "github.com/golang/mock/gomock"
 	pgtype "github.com/jackc/pgx/v5/pgtype"
+	db "github.com/suryansh74/auth-package/internal/db"
 	sqlc "github.com/suryansh74/auth-package/internal/db/sqlc"
 )
 
@@ -36,6 +37,243 @@ func (m *MockAuth) EXPECT() *MockAuthMockRecorder {
 	return m.recorder
 }
 
+// AcceptOrganizationInvitation mocks base method.
+func (m *MockAuth) AcceptOrganizationInvitation(ctx context.Context, id pgtype.UUID) (sqlc.OrganizationInvitation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AcceptOrganizationInvitation", ctx, id)
+	ret0, _ := ret[0].(sqlc.OrganizationInvitation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AcceptOrganizationInvitation indicates an expected call of AcceptOrganizationInvitation.
+func (mr *MockAuthMockRecorder) AcceptOrganizationInvitation(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AcceptOrganizationInvitation", reflect.TypeOf((*MockAuth)(nil).AcceptOrganizationInvitation), ctx, id)
+}
+
+// AddOrganizationMember mocks base method.
+func (m *MockAuth) AddOrganizationMember(ctx context.Context, arg sqlc.AddOrganizationMemberParams) (sqlc.OrganizationMember, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddOrganizationMember", ctx, arg)
+	ret0, _ := ret[0].(sqlc.OrganizationMember)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddOrganizationMember indicates an expected call of AddOrganizationMember.
+func (mr *MockAuthMockRecorder) AddOrganizationMember(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddOrganizationMember", reflect.TypeOf((*MockAuth)(nil).AddOrganizationMember), ctx, arg)
+}
+
+// AssignUserRole mocks base method.
+func (m *MockAuth) AssignUserRole(ctx context.Context, arg sqlc.AssignUserRoleParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AssignUserRole", ctx, arg)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AssignUserRole indicates an expected call of AssignUserRole.
+func (mr *MockAuthMockRecorder) AssignUserRole(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AssignUserRole", reflect.TypeOf((*MockAuth)(nil).AssignUserRole), ctx, arg)
+}
+
+// CancelEmailChangeRequest mocks base method.
+func (m *MockAuth) CancelEmailChangeRequest(ctx context.Context, id pgtype.UUID) (sqlc.EmailChangeRequest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CancelEmailChangeRequest", ctx, id)
+	ret0, _ := ret[0].(sqlc.EmailChangeRequest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CancelEmailChangeRequest indicates an expected call of CancelEmailChangeRequest.
+func (mr *MockAuthMockRecorder) CancelEmailChangeRequest(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelEmailChangeRequest", reflect.TypeOf((*MockAuth)(nil).CancelEmailChangeRequest), ctx, id)
+}
+
+// CancelOtherUserEmailChangeRequests mocks base method.
+func (m *MockAuth) CancelOtherUserEmailChangeRequests(ctx context.Context, arg sqlc.CancelOtherUserEmailChangeRequestsParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CancelOtherUserEmailChangeRequests", ctx, arg)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CancelOtherUserEmailChangeRequests indicates an expected call of CancelOtherUserEmailChangeRequests.
+func (mr *MockAuthMockRecorder) CancelOtherUserEmailChangeRequests(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelOtherUserEmailChangeRequests", reflect.TypeOf((*MockAuth)(nil).CancelOtherUserEmailChangeRequests), ctx, arg)
+}
+
+// CancelUserEmailChangeRequests mocks base method.
+func (m *MockAuth) CancelUserEmailChangeRequests(ctx context.Context, userID pgtype.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CancelUserEmailChangeRequests", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CancelUserEmailChangeRequests indicates an expected call of CancelUserEmailChangeRequests.
+func (mr *MockAuthMockRecorder) CancelUserEmailChangeRequests(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelUserEmailChangeRequests", reflect.TypeOf((*MockAuth)(nil).CancelUserEmailChangeRequests), ctx, userID)
+}
+
+// ConfirmEmailChangeRequest mocks base method.
+func (m *MockAuth) ConfirmEmailChangeRequest(ctx context.Context, id pgtype.UUID) (sqlc.EmailChangeRequest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConfirmEmailChangeRequest", ctx, id)
+	ret0, _ := ret[0].(sqlc.EmailChangeRequest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ConfirmEmailChangeRequest indicates an expected call of ConfirmEmailChangeRequest.
+func (mr *MockAuthMockRecorder) ConfirmEmailChangeRequest(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConfirmEmailChangeRequest", reflect.TypeOf((*MockAuth)(nil).ConfirmEmailChangeRequest), ctx, id)
+}
+
+// CountOrganizationOwners mocks base method.
+func (m *MockAuth) CountOrganizationOwners(ctx context.Context, organizationID pgtype.UUID) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountOrganizationOwners", ctx, organizationID)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountOrganizationOwners indicates an expected call of CountOrganizationOwners.
+func (mr *MockAuthMockRecorder) CountOrganizationOwners(ctx, organizationID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountOrganizationOwners", reflect.TypeOf((*MockAuth)(nil).CountOrganizationOwners), ctx, organizationID)
+}
+
+// CountRoleUsers mocks base method.
+func (m *MockAuth) CountRoleUsers(ctx context.Context, roleID pgtype.UUID) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountRoleUsers", ctx, roleID)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountRoleUsers indicates an expected call of CountRoleUsers.
+func (mr *MockAuthMockRecorder) CountRoleUsers(ctx, roleID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountRoleUsers", reflect.TypeOf((*MockAuth)(nil).CountRoleUsers), ctx, roleID)
+}
+
+// CreateEmailChangeRequest mocks base method.
+func (m *MockAuth) CreateEmailChangeRequest(ctx context.Context, arg sqlc.CreateEmailChangeRequestParams) (sqlc.EmailChangeRequest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateEmailChangeRequest", ctx, arg)
+	ret0, _ := ret[0].(sqlc.EmailChangeRequest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateEmailChangeRequest indicates an expected call of CreateEmailChangeRequest.
+func (mr *MockAuthMockRecorder) CreateEmailChangeRequest(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateEmailChangeRequest", reflect.TypeOf((*MockAuth)(nil).CreateEmailChangeRequest), ctx, arg)
+}
+
+// CreateOrganization mocks base method.
+func (m *MockAuth) CreateOrganization(ctx context.Context, arg sqlc.CreateOrganizationParams) (sqlc.Organization, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateOrganization", ctx, arg)
+	ret0, _ := ret[0].(sqlc.Organization)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateOrganization indicates an expected call of CreateOrganization.
+func (mr *MockAuthMockRecorder) CreateOrganization(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOrganization", reflect.TypeOf((*MockAuth)(nil).CreateOrganization), ctx, arg)
+}
+
+// CreateOrganizationInvitation mocks base method.
+func (m *MockAuth) CreateOrganizationInvitation(ctx context.Context, arg sqlc.CreateOrganizationInvitationParams) (sqlc.OrganizationInvitation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateOrganizationInvitation", ctx, arg)
+	ret0, _ := ret[0].(sqlc.OrganizationInvitation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateOrganizationInvitation indicates an expected call of CreateOrganizationInvitation.
+func (mr *MockAuthMockRecorder) CreateOrganizationInvitation(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOrganizationInvitation", reflect.TypeOf((*MockAuth)(nil).CreateOrganizationInvitation), ctx, arg)
+}
+
+// CreatePasswordResetToken mocks base method.
+func (m *MockAuth) CreatePasswordResetToken(ctx context.Context, arg sqlc.CreatePasswordResetTokenParams) (sqlc.PasswordResetToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreatePasswordResetToken", ctx, arg)
+	ret0, _ := ret[0].(sqlc.PasswordResetToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreatePasswordResetToken indicates an expected call of CreatePasswordResetToken.
+func (mr *MockAuthMockRecorder) CreatePasswordResetToken(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePasswordResetToken", reflect.TypeOf((*MockAuth)(nil).CreatePasswordResetToken), ctx, arg)
+}
+
+// CreatePermission mocks base method.
+func (m *MockAuth) CreatePermission(ctx context.Context, arg sqlc.CreatePermissionParams) (sqlc.Permission, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreatePermission", ctx, arg)
+	ret0, _ := ret[0].(sqlc.Permission)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreatePermission indicates an expected call of CreatePermission.
+func (mr *MockAuthMockRecorder) CreatePermission(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePermission", reflect.TypeOf((*MockAuth)(nil).CreatePermission), ctx, arg)
+}
+
+// CreateRole mocks base method.
+func (m *MockAuth) CreateRole(ctx context.Context, arg sqlc.CreateRoleParams) (sqlc.Role, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateRole", ctx, arg)
+	ret0, _ := ret[0].(sqlc.Role)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateRole indicates an expected call of CreateRole.
+func (mr *MockAuthMockRecorder) CreateRole(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateRole", reflect.TypeOf((*MockAuth)(nil).CreateRole), ctx, arg)
+}
+
+// CreateSession mocks base method.
+func (m *MockAuth) CreateSession(ctx context.Context, arg sqlc.CreateSessionParams) (sqlc.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateSession", ctx, arg)
+	ret0, _ := ret[0].(sqlc.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateSession indicates an expected call of CreateSession.
+func (mr *MockAuthMockRecorder) CreateSession(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSession", reflect.TypeOf((*MockAuth)(nil).CreateSession), ctx, arg)
+}
+
 // CreateUser mocks base method.
 func (m *MockAuth) CreateUser(ctx context.Context, arg sqlc.CreateUserParams) (sqlc.User, error) {
 	m.ctrl.T.Helper()
@@ -51,6 +289,241 @@ func (mr *MockAuthMockRecorder) CreateUser(ctx, arg interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUser", reflect.TypeOf((*MockAuth)(nil).CreateUser), ctx, arg)
 }
 
+// DeleteExpiredRevokedTokens mocks base method.
+func (m *MockAuth) DeleteExpiredRevokedTokens(ctx context.Context, expiresAt pgtype.Timestamp) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteExpiredRevokedTokens", ctx, expiresAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteExpiredRevokedTokens indicates an expected call of DeleteExpiredRevokedTokens.
+func (mr *MockAuthMockRecorder) DeleteExpiredRevokedTokens(ctx, expiresAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteExpiredRevokedTokens", reflect.TypeOf((*MockAuth)(nil).DeleteExpiredRevokedTokens), ctx, expiresAt)
+}
+
+// DeleteExpiredRevokedUserTokens mocks base method.
+func (m *MockAuth) DeleteExpiredRevokedUserTokens(ctx context.Context, expiresAt pgtype.Timestamp) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteExpiredRevokedUserTokens", ctx, expiresAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteExpiredRevokedUserTokens indicates an expected call of DeleteExpiredRevokedUserTokens.
+func (mr *MockAuthMockRecorder) DeleteExpiredRevokedUserTokens(ctx, expiresAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteExpiredRevokedUserTokens", reflect.TypeOf((*MockAuth)(nil).DeleteExpiredRevokedUserTokens), ctx, expiresAt)
+}
+
+// DeletePermission mocks base method.
+func (m *MockAuth) DeletePermission(ctx context.Context, id pgtype.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeletePermission", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeletePermission indicates an expected call of DeletePermission.
+func (mr *MockAuthMockRecorder) DeletePermission(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePermission", reflect.TypeOf((*MockAuth)(nil).DeletePermission), ctx, id)
+}
+
+// DeleteRole mocks base method.
+func (m *MockAuth) DeleteRole(ctx context.Context, id pgtype.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteRole", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteRole indicates an expected call of DeleteRole.
+func (mr *MockAuthMockRecorder) DeleteRole(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteRole", reflect.TypeOf((*MockAuth)(nil).DeleteRole), ctx, id)
+}
+
+// ExecTx mocks base method.
+func (m *MockAuth) ExecTx(ctx context.Context, fn func(db.Auth) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExecTx", ctx, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ExecTx indicates an expected call of ExecTx.
+func (mr *MockAuthMockRecorder) ExecTx(ctx, fn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExecTx", reflect.TypeOf((*MockAuth)(nil).ExecTx), ctx, fn)
+}
+
+// GetDeletedUserByEmail mocks base method.
+func (m *MockAuth) GetDeletedUserByEmail(ctx context.Context, email string) (sqlc.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDeletedUserByEmail", ctx, email)
+	ret0, _ := ret[0].(sqlc.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDeletedUserByEmail indicates an expected call of GetDeletedUserByEmail.
+func (mr *MockAuthMockRecorder) GetDeletedUserByEmail(ctx, email interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDeletedUserByEmail", reflect.TypeOf((*MockAuth)(nil).GetDeletedUserByEmail), ctx, email)
+}
+
+// GetEmailChangeRequestByCancelTokenHash mocks base method.
+func (m *MockAuth) GetEmailChangeRequestByCancelTokenHash(ctx context.Context, cancelTokenHash string) (sqlc.EmailChangeRequest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetEmailChangeRequestByCancelTokenHash", ctx, cancelTokenHash)
+	ret0, _ := ret[0].(sqlc.EmailChangeRequest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetEmailChangeRequestByCancelTokenHash indicates an expected call of GetEmailChangeRequestByCancelTokenHash.
+func (mr *MockAuthMockRecorder) GetEmailChangeRequestByCancelTokenHash(ctx, cancelTokenHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEmailChangeRequestByCancelTokenHash", reflect.TypeOf((*MockAuth)(nil).GetEmailChangeRequestByCancelTokenHash), ctx, cancelTokenHash)
+}
+
+// GetEmailChangeRequestByConfirmTokenHash mocks base method.
+func (m *MockAuth) GetEmailChangeRequestByConfirmTokenHash(ctx context.Context, confirmTokenHash string) (sqlc.EmailChangeRequest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetEmailChangeRequestByConfirmTokenHash", ctx, confirmTokenHash)
+	ret0, _ := ret[0].(sqlc.EmailChangeRequest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetEmailChangeRequestByConfirmTokenHash indicates an expected call of GetEmailChangeRequestByConfirmTokenHash.
+func (mr *MockAuthMockRecorder) GetEmailChangeRequestByConfirmTokenHash(ctx, confirmTokenHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEmailChangeRequestByConfirmTokenHash", reflect.TypeOf((*MockAuth)(nil).GetEmailChangeRequestByConfirmTokenHash), ctx, confirmTokenHash)
+}
+
+// GetOrganization mocks base method.
+func (m *MockAuth) GetOrganization(ctx context.Context, id pgtype.UUID) (sqlc.Organization, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOrganization", ctx, id)
+	ret0, _ := ret[0].(sqlc.Organization)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOrganization indicates an expected call of GetOrganization.
+func (mr *MockAuthMockRecorder) GetOrganization(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrganization", reflect.TypeOf((*MockAuth)(nil).GetOrganization), ctx, id)
+}
+
+// GetOrganizationBySlug mocks base method.
+func (m *MockAuth) GetOrganizationBySlug(ctx context.Context, slug string) (sqlc.Organization, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOrganizationBySlug", ctx, slug)
+	ret0, _ := ret[0].(sqlc.Organization)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOrganizationBySlug indicates an expected call of GetOrganizationBySlug.
+func (mr *MockAuthMockRecorder) GetOrganizationBySlug(ctx, slug interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrganizationBySlug", reflect.TypeOf((*MockAuth)(nil).GetOrganizationBySlug), ctx, slug)
+}
+
+// GetOrganizationInvitationByTokenHash mocks base method.
+func (m *MockAuth) GetOrganizationInvitationByTokenHash(ctx context.Context, tokenHash string) (sqlc.OrganizationInvitation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOrganizationInvitationByTokenHash", ctx, tokenHash)
+	ret0, _ := ret[0].(sqlc.OrganizationInvitation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOrganizationInvitationByTokenHash indicates an expected call of GetOrganizationInvitationByTokenHash.
+func (mr *MockAuthMockRecorder) GetOrganizationInvitationByTokenHash(ctx, tokenHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrganizationInvitationByTokenHash", reflect.TypeOf((*MockAuth)(nil).GetOrganizationInvitationByTokenHash), ctx, tokenHash)
+}
+
+// GetOrganizationMember mocks base method.
+func (m *MockAuth) GetOrganizationMember(ctx context.Context, arg sqlc.GetOrganizationMemberParams) (sqlc.OrganizationMember, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOrganizationMember", ctx, arg)
+	ret0, _ := ret[0].(sqlc.OrganizationMember)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOrganizationMember indicates an expected call of GetOrganizationMember.
+func (mr *MockAuthMockRecorder) GetOrganizationMember(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrganizationMember", reflect.TypeOf((*MockAuth)(nil).GetOrganizationMember), ctx, arg)
+}
+
+// GetPasswordResetTokenByHash mocks base method.
+func (m *MockAuth) GetPasswordResetTokenByHash(ctx context.Context, tokenHash string) (sqlc.PasswordResetToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPasswordResetTokenByHash", ctx, tokenHash)
+	ret0, _ := ret[0].(sqlc.PasswordResetToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPasswordResetTokenByHash indicates an expected call of GetPasswordResetTokenByHash.
+func (mr *MockAuthMockRecorder) GetPasswordResetTokenByHash(ctx, tokenHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPasswordResetTokenByHash", reflect.TypeOf((*MockAuth)(nil).GetPasswordResetTokenByHash), ctx, tokenHash)
+}
+
+// GetPermissionByName mocks base method.
+func (m *MockAuth) GetPermissionByName(ctx context.Context, name string) (sqlc.Permission, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPermissionByName", ctx, name)
+	ret0, _ := ret[0].(sqlc.Permission)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPermissionByName indicates an expected call of GetPermissionByName.
+func (mr *MockAuthMockRecorder) GetPermissionByName(ctx, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPermissionByName", reflect.TypeOf((*MockAuth)(nil).GetPermissionByName), ctx, name)
+}
+
+// GetRoleByName mocks base method.
+func (m *MockAuth) GetRoleByName(ctx context.Context, name string) (sqlc.Role, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRoleByName", ctx, name)
+	ret0, _ := ret[0].(sqlc.Role)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRoleByName indicates an expected call of GetRoleByName.
+func (mr *MockAuthMockRecorder) GetRoleByName(ctx, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRoleByName", reflect.TypeOf((*MockAuth)(nil).GetRoleByName), ctx, name)
+}
+
+// GetSessionByRefreshTokenHash mocks base method.
+func (m *MockAuth) GetSessionByRefreshTokenHash(ctx context.Context, refreshTokenHash string) (sqlc.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSessionByRefreshTokenHash", ctx, refreshTokenHash)
+	ret0, _ := ret[0].(sqlc.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSessionByRefreshTokenHash indicates an expected call of GetSessionByRefreshTokenHash.
+func (mr *MockAuthMockRecorder) GetSessionByRefreshTokenHash(ctx, refreshTokenHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSessionByRefreshTokenHash", reflect.TypeOf((*MockAuth)(nil).GetSessionByRefreshTokenHash), ctx, refreshTokenHash)
+}
+
 // GetUser mocks base method.
 func (m *MockAuth) GetUser(ctx context.Context, id pgtype.UUID) (sqlc.User, error) {
 	m.ctrl.T.Helper()
@@ -80,3 +553,532 @@ func (mr *MockAuthMockRecorder) GetUserByEmail(ctx, email interface{}) *gomock.C
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByEmail", reflect.TypeOf((*MockAuth)(nil).GetUserByEmail), ctx, email)
 }
+
+// GetUserStatus mocks base method.
+func (m *MockAuth) GetUserStatus(ctx context.Context, id pgtype.UUID) (sqlc.GetUserStatusRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserStatus", ctx, id)
+	ret0, _ := ret[0].(sqlc.GetUserStatusRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserStatus indicates an expected call of GetUserStatus.
+func (mr *MockAuthMockRecorder) GetUserStatus(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserStatus", reflect.TypeOf((*MockAuth)(nil).GetUserStatus), ctx, id)
+}
+
+// GetUserTokenRevocation mocks base method.
+func (m *MockAuth) GetUserTokenRevocation(ctx context.Context, userID pgtype.UUID) (pgtype.Timestamp, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserTokenRevocation", ctx, userID)
+	ret0, _ := ret[0].(pgtype.Timestamp)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserTokenRevocation indicates an expected call of GetUserTokenRevocation.
+func (mr *MockAuthMockRecorder) GetUserTokenRevocation(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserTokenRevocation", reflect.TypeOf((*MockAuth)(nil).GetUserTokenRevocation), ctx, userID)
+}
+
+// GrantRolePermission mocks base method.
+func (m *MockAuth) GrantRolePermission(ctx context.Context, arg sqlc.GrantRolePermissionParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GrantRolePermission", ctx, arg)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// GrantRolePermission indicates an expected call of GrantRolePermission.
+func (mr *MockAuthMockRecorder) GrantRolePermission(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GrantRolePermission", reflect.TypeOf((*MockAuth)(nil).GrantRolePermission), ctx, arg)
+}
+
+// InvalidateUserPasswordResetTokens mocks base method.
+func (m *MockAuth) InvalidateUserPasswordResetTokens(ctx context.Context, userID pgtype.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InvalidateUserPasswordResetTokens", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InvalidateUserPasswordResetTokens indicates an expected call of InvalidateUserPasswordResetTokens.
+func (mr *MockAuthMockRecorder) InvalidateUserPasswordResetTokens(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InvalidateUserPasswordResetTokens", reflect.TypeOf((*MockAuth)(nil).InvalidateUserPasswordResetTokens), ctx, userID)
+}
+
+// IsTokenRevoked mocks base method.
+func (m *MockAuth) IsTokenRevoked(ctx context.Context, tokenID pgtype.UUID) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsTokenRevoked", ctx, tokenID)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsTokenRevoked indicates an expected call of IsTokenRevoked.
+func (mr *MockAuthMockRecorder) IsTokenRevoked(ctx, tokenID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsTokenRevoked", reflect.TypeOf((*MockAuth)(nil).IsTokenRevoked), ctx, tokenID)
+}
+
+// IsUserTokenRevoked mocks base method.
+func (m *MockAuth) IsUserTokenRevoked(ctx context.Context, arg sqlc.IsUserTokenRevokedParams) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsUserTokenRevoked", ctx, arg)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsUserTokenRevoked indicates an expected call of IsUserTokenRevoked.
+func (mr *MockAuthMockRecorder) IsUserTokenRevoked(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsUserTokenRevoked", reflect.TypeOf((*MockAuth)(nil).IsUserTokenRevoked), ctx, arg)
+}
+
+// ListOrganizationMembers mocks base method.
+func (m *MockAuth) ListOrganizationMembers(ctx context.Context, organizationID pgtype.UUID) ([]sqlc.ListOrganizationMembersRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListOrganizationMembers", ctx, organizationID)
+	ret0, _ := ret[0].([]sqlc.ListOrganizationMembersRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListOrganizationMembers indicates an expected call of ListOrganizationMembers.
+func (mr *MockAuthMockRecorder) ListOrganizationMembers(ctx, organizationID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListOrganizationMembers", reflect.TypeOf((*MockAuth)(nil).ListOrganizationMembers), ctx, organizationID)
+}
+
+// ListPermissions mocks base method.
+func (m *MockAuth) ListPermissions(ctx context.Context) ([]sqlc.Permission, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPermissions", ctx)
+	ret0, _ := ret[0].([]sqlc.Permission)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPermissions indicates an expected call of ListPermissions.
+func (mr *MockAuthMockRecorder) ListPermissions(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPermissions", reflect.TypeOf((*MockAuth)(nil).ListPermissions), ctx)
+}
+
+// ListRolePermissions mocks base method.
+func (m *MockAuth) ListRolePermissions(ctx context.Context, roleID pgtype.UUID) ([]sqlc.Permission, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListRolePermissions", ctx, roleID)
+	ret0, _ := ret[0].([]sqlc.Permission)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListRolePermissions indicates an expected call of ListRolePermissions.
+func (mr *MockAuthMockRecorder) ListRolePermissions(ctx, roleID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListRolePermissions", reflect.TypeOf((*MockAuth)(nil).ListRolePermissions), ctx, roleID)
+}
+
+// ListRoles mocks base method.
+func (m *MockAuth) ListRoles(ctx context.Context) ([]sqlc.Role, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListRoles", ctx)
+	ret0, _ := ret[0].([]sqlc.Role)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListRoles indicates an expected call of ListRoles.
+func (mr *MockAuthMockRecorder) ListRoles(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListRoles", reflect.TypeOf((*MockAuth)(nil).ListRoles), ctx)
+}
+
+// ListUserOrganizations mocks base method.
+func (m *MockAuth) ListUserOrganizations(ctx context.Context, userID pgtype.UUID) ([]sqlc.ListUserOrganizationsRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListUserOrganizations", ctx, userID)
+	ret0, _ := ret[0].([]sqlc.ListUserOrganizationsRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListUserOrganizations indicates an expected call of ListUserOrganizations.
+func (mr *MockAuthMockRecorder) ListUserOrganizations(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUserOrganizations", reflect.TypeOf((*MockAuth)(nil).ListUserOrganizations), ctx, userID)
+}
+
+// ListUserPermissions mocks base method.
+func (m *MockAuth) ListUserPermissions(ctx context.Context, userID pgtype.UUID) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListUserPermissions", ctx, userID)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListUserPermissions indicates an expected call of ListUserPermissions.
+func (mr *MockAuthMockRecorder) ListUserPermissions(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUserPermissions", reflect.TypeOf((*MockAuth)(nil).ListUserPermissions), ctx, userID)
+}
+
+// ListUserRoles mocks base method.
+func (m *MockAuth) ListUserRoles(ctx context.Context, userID pgtype.UUID) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListUserRoles", ctx, userID)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListUserRoles indicates an expected call of ListUserRoles.
+func (mr *MockAuthMockRecorder) ListUserRoles(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUserRoles", reflect.TypeOf((*MockAuth)(nil).ListUserRoles), ctx, userID)
+}
+
+// LockOrganization mocks base method.
+func (m *MockAuth) LockOrganization(ctx context.Context, id pgtype.UUID) (pgtype.UUID, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LockOrganization", ctx, id)
+	ret0, _ := ret[0].(pgtype.UUID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LockOrganization indicates an expected call of LockOrganization.
+func (mr *MockAuthMockRecorder) LockOrganization(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LockOrganization", reflect.TypeOf((*MockAuth)(nil).LockOrganization), ctx, id)
+}
+
+// LockOwnedOrganizations mocks base method.
+func (m *MockAuth) LockOwnedOrganizations(ctx context.Context, userID pgtype.UUID) ([]pgtype.UUID, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LockOwnedOrganizations", ctx, userID)
+	ret0, _ := ret[0].([]pgtype.UUID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LockOwnedOrganizations indicates an expected call of LockOwnedOrganizations.
+func (mr *MockAuthMockRecorder) LockOwnedOrganizations(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LockOwnedOrganizations", reflect.TypeOf((*MockAuth)(nil).LockOwnedOrganizations), ctx, userID)
+}
+
+// MarkSessionUsed mocks base method.
+func (m *MockAuth) MarkSessionUsed(ctx context.Context, id pgtype.UUID) (sqlc.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkSessionUsed", ctx, id)
+	ret0, _ := ret[0].(sqlc.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MarkSessionUsed indicates an expected call of MarkSessionUsed.
+func (mr *MockAuthMockRecorder) MarkSessionUsed(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkSessionUsed", reflect.TypeOf((*MockAuth)(nil).MarkSessionUsed), ctx, id)
+}
+
+// PurgeDeletedUsers mocks base method.
+func (m *MockAuth) PurgeDeletedUsers(ctx context.Context, deletedBefore pgtype.Timestamp) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeDeletedUsers", ctx, deletedBefore)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeDeletedUsers indicates an expected call of PurgeDeletedUsers.
+func (mr *MockAuthMockRecorder) PurgeDeletedUsers(ctx, deletedBefore interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeDeletedUsers", reflect.TypeOf((*MockAuth)(nil).PurgeDeletedUsers), ctx, deletedBefore)
+}
+
+// RemoveOrganizationMember mocks base method.
+func (m *MockAuth) RemoveOrganizationMember(ctx context.Context, arg sqlc.RemoveOrganizationMemberParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveOrganizationMember", ctx, arg)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveOrganizationMember indicates an expected call of RemoveOrganizationMember.
+func (mr *MockAuthMockRecorder) RemoveOrganizationMember(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveOrganizationMember", reflect.TypeOf((*MockAuth)(nil).RemoveOrganizationMember), ctx, arg)
+}
+
+// RestoreUser mocks base method.
+func (m *MockAuth) RestoreUser(ctx context.Context, arg sqlc.RestoreUserParams) (sqlc.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreUser", ctx, arg)
+	ret0, _ := ret[0].(sqlc.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RestoreUser indicates an expected call of RestoreUser.
+func (mr *MockAuthMockRecorder) RestoreUser(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreUser", reflect.TypeOf((*MockAuth)(nil).RestoreUser), ctx, arg)
+}
+
+// RestoreUserEmail mocks base method.
+func (m *MockAuth) RestoreUserEmail(ctx context.Context, arg sqlc.RestoreUserEmailParams) (sqlc.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreUserEmail", ctx, arg)
+	ret0, _ := ret[0].(sqlc.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RestoreUserEmail indicates an expected call of RestoreUserEmail.
+func (mr *MockAuthMockRecorder) RestoreUserEmail(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreUserEmail", reflect.TypeOf((*MockAuth)(nil).RestoreUserEmail), ctx, arg)
+}
+
+// RevokeOtherUserSessions mocks base method.
+func (m *MockAuth) RevokeOtherUserSessions(ctx context.Context, arg sqlc.RevokeOtherUserSessionsParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeOtherUserSessions", ctx, arg)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeOtherUserSessions indicates an expected call of RevokeOtherUserSessions.
+func (mr *MockAuthMockRecorder) RevokeOtherUserSessions(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeOtherUserSessions", reflect.TypeOf((*MockAuth)(nil).RevokeOtherUserSessions), ctx, arg)
+}
+
+// RevokeRolePermission mocks base method.
+func (m *MockAuth) RevokeRolePermission(ctx context.Context, arg sqlc.RevokeRolePermissionParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeRolePermission", ctx, arg)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeRolePermission indicates an expected call of RevokeRolePermission.
+func (mr *MockAuthMockRecorder) RevokeRolePermission(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeRolePermission", reflect.TypeOf((*MockAuth)(nil).RevokeRolePermission), ctx, arg)
+}
+
+// RevokeSessionFamily mocks base method.
+func (m *MockAuth) RevokeSessionFamily(ctx context.Context, familyID pgtype.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeSessionFamily", ctx, familyID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeSessionFamily indicates an expected call of RevokeSessionFamily.
+func (mr *MockAuthMockRecorder) RevokeSessionFamily(ctx, familyID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeSessionFamily", reflect.TypeOf((*MockAuth)(nil).RevokeSessionFamily), ctx, familyID)
+}
+
+// RevokeToken mocks base method.
+func (m *MockAuth) RevokeToken(ctx context.Context, arg sqlc.RevokeTokenParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeToken", ctx, arg)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeToken indicates an expected call of RevokeToken.
+func (mr *MockAuthMockRecorder) RevokeToken(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeToken", reflect.TypeOf((*MockAuth)(nil).RevokeToken), ctx, arg)
+}
+
+// RevokeUserRole mocks base method.
+func (m *MockAuth) RevokeUserRole(ctx context.Context, arg sqlc.RevokeUserRoleParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeUserRole", ctx, arg)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeUserRole indicates an expected call of RevokeUserRole.
+func (mr *MockAuthMockRecorder) RevokeUserRole(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeUserRole", reflect.TypeOf((*MockAuth)(nil).RevokeUserRole), ctx, arg)
+}
+
+// RevokeUserSessions mocks base method.
+func (m *MockAuth) RevokeUserSessions(ctx context.Context, userID pgtype.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeUserSessions", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeUserSessions indicates an expected call of RevokeUserSessions.
+func (mr *MockAuthMockRecorder) RevokeUserSessions(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeUserSessions", reflect.TypeOf((*MockAuth)(nil).RevokeUserSessions), ctx, userID)
+}
+
+// RevokeUserTokens mocks base method.
+func (m *MockAuth) RevokeUserTokens(ctx context.Context, arg sqlc.RevokeUserTokensParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeUserTokens", ctx, arg)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeUserTokens indicates an expected call of RevokeUserTokens.
+func (mr *MockAuthMockRecorder) RevokeUserTokens(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeUserTokens", reflect.TypeOf((*MockAuth)(nil).RevokeUserTokens), ctx, arg)
+}
+
+// SetSessionOrganization mocks base method.
+func (m *MockAuth) SetSessionOrganization(ctx context.Context, arg sqlc.SetSessionOrganizationParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetSessionOrganization", ctx, arg)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetSessionOrganization indicates an expected call of SetSessionOrganization.
+func (mr *MockAuthMockRecorder) SetSessionOrganization(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetSessionOrganization", reflect.TypeOf((*MockAuth)(nil).SetSessionOrganization), ctx, arg)
+}
+
+// SoftDeleteUser mocks base method.
+func (m *MockAuth) SoftDeleteUser(ctx context.Context, arg sqlc.SoftDeleteUserParams) (sqlc.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SoftDeleteUser", ctx, arg)
+	ret0, _ := ret[0].(sqlc.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SoftDeleteUser indicates an expected call of SoftDeleteUser.
+func (mr *MockAuthMockRecorder) SoftDeleteUser(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SoftDeleteUser", reflect.TypeOf((*MockAuth)(nil).SoftDeleteUser), ctx, arg)
+}
+
+// UpdateOrganizationMemberRole mocks base method.
+func (m *MockAuth) UpdateOrganizationMemberRole(ctx context.Context, arg sqlc.UpdateOrganizationMemberRoleParams) (sqlc.OrganizationMember, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateOrganizationMemberRole", ctx, arg)
+	ret0, _ := ret[0].(sqlc.OrganizationMember)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateOrganizationMemberRole indicates an expected call of UpdateOrganizationMemberRole.
+func (mr *MockAuthMockRecorder) UpdateOrganizationMemberRole(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateOrganizationMemberRole", reflect.TypeOf((*MockAuth)(nil).UpdateOrganizationMemberRole), ctx, arg)
+}
+
+// UpdateUserEmail mocks base method.
+func (m *MockAuth) UpdateUserEmail(ctx context.Context, arg sqlc.UpdateUserEmailParams) (sqlc.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateUserEmail", ctx, arg)
+	ret0, _ := ret[0].(sqlc.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateUserEmail indicates an expected call of UpdateUserEmail.
+func (mr *MockAuthMockRecorder) UpdateUserEmail(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserEmail", reflect.TypeOf((*MockAuth)(nil).UpdateUserEmail), ctx, arg)
+}
+
+// UpdateUserPassword mocks base method.
+func (m *MockAuth) UpdateUserPassword(ctx context.Context, arg sqlc.UpdateUserPasswordParams) (sqlc.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateUserPassword", ctx, arg)
+	ret0, _ := ret[0].(sqlc.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateUserPassword indicates an expected call of UpdateUserPassword.
+func (mr *MockAuthMockRecorder) UpdateUserPassword(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserPassword", reflect.TypeOf((*MockAuth)(nil).UpdateUserPassword), ctx, arg)
+}
+
+// UpdateUserProfile mocks base method.
+func (m *MockAuth) UpdateUserProfile(ctx context.Context, arg sqlc.UpdateUserProfileParams) (sqlc.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateUserProfile", ctx, arg)
+	ret0, _ := ret[0].(sqlc.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateUserProfile indicates an expected call of UpdateUserProfile.
+func (mr *MockAuthMockRecorder) UpdateUserProfile(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserProfile", reflect.TypeOf((*MockAuth)(nil).UpdateUserProfile), ctx, arg)
+}
+
+// UpdateUserStatus mocks base method.
+func (m *MockAuth) UpdateUserStatus(ctx context.Context, arg sqlc.UpdateUserStatusParams) (sqlc.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateUserStatus", ctx, arg)
+	ret0, _ := ret[0].(sqlc.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateUserStatus indicates an expected call of UpdateUserStatus.
+func (mr *MockAuthMockRecorder) UpdateUserStatus(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserStatus", reflect.TypeOf((*MockAuth)(nil).UpdateUserStatus), ctx, arg)
+}
+
+// UsePasswordResetToken mocks base method.
+func (m *MockAuth) UsePasswordResetToken(ctx context.Context, id pgtype.UUID) (sqlc.PasswordResetToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UsePasswordResetToken", ctx, id)
+	ret0, _ := ret[0].(sqlc.PasswordResetToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UsePasswordResetToken indicates an expected call of UsePasswordResetToken.
+func (mr *MockAuthMockRecorder) UsePasswordResetToken(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UsePasswordResetToken", reflect.TypeOf((*MockAuth)(nil).UsePasswordResetToken), ctx, id)
+}
+
+// VerifyUserEmail mocks base method.
+func (m *MockAuth) VerifyUserEmail(ctx context.Context, arg sqlc.VerifyUserEmailParams) (sqlc.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyUserEmail", ctx, arg)
+	ret0, _ := ret[0].(sqlc.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VerifyUserEmail indicates an expected call of VerifyUserEmail.
+func (mr *MockAuthMockRecorder) VerifyUserEmail(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyUserEmail", reflect.TypeOf((*MockAuth)(nil).VerifyUserEmail), ctx, arg)
+}
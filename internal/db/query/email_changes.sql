@@ -0,0 +1,37 @@
+-- name: CreateEmailChangeRequest :one
+INSERT INTO email_change_requests (
+  user_id, old_email, new_email, confirm_token_hash, cancel_token_hash, expires_at
+) VALUES (
+  $1, $2, $3, $4, $5, $6
+)
+RETURNING *;
+
+-- name: GetEmailChangeRequestByConfirmTokenHash :one
+SELECT * FROM email_change_requests
+WHERE confirm_token_hash = $1 LIMIT 1;
+
+-- name: GetEmailChangeRequestByCancelTokenHash :one
+SELECT * FROM email_change_requests
+WHERE cancel_token_hash = $1 LIMIT 1;
+
+-- name: ConfirmEmailChangeRequest :one
+UPDATE email_change_requests
+SET confirmed_at = NOW()
+WHERE id = $1 AND confirmed_at IS NULL AND cancelled_at IS NULL
+RETURNING *;
+
+-- name: CancelEmailChangeRequest :one
+UPDATE email_change_requests
+SET cancelled_at = NOW()
+WHERE id = $1 AND cancelled_at IS NULL
+RETURNING *;
+
+-- name: CancelUserEmailChangeRequests :exec
+UPDATE email_change_requests
+SET cancelled_at = NOW()
+WHERE user_id = $1 AND confirmed_at IS NULL AND cancelled_at IS NULL;
+
+-- name: CancelOtherUserEmailChangeRequests :exec
+UPDATE email_change_requests
+SET cancelled_at = NOW()
+WHERE user_id = $1 AND id <> $2 AND cancelled_at IS NULL;
@@ -0,0 +1,84 @@
+-- name: CreateOrganization :one
+INSERT INTO organizations (
+  name, slug
+) VALUES (
+  $1, $2
+)
+RETURNING *;
+
+-- name: GetOrganization :one
+SELECT * FROM organizations
+WHERE id = $1 LIMIT 1;
+
+-- name: GetOrganizationBySlug :one
+SELECT * FROM organizations
+WHERE slug = $1 LIMIT 1;
+
+-- name: LockOrganization :one
+SELECT id FROM organizations
+WHERE id = $1
+FOR UPDATE;
+
+-- name: LockOwnedOrganizations :many
+SELECT o.id FROM organizations o
+JOIN organization_members om ON om.organization_id = o.id
+WHERE om.user_id = $1 AND om.role = 'owner'
+ORDER BY o.id
+FOR UPDATE OF o;
+
+-- name: ListUserOrganizations :many
+SELECT o.id, o.name, o.slug, o.created_at, m.role FROM organizations o
+JOIN organization_members m ON m.organization_id = o.id
+WHERE m.user_id = $1
+ORDER BY o.name;
+
+-- name: AddOrganizationMember :one
+INSERT INTO organization_members (
+  organization_id, user_id, role
+) VALUES (
+  $1, $2, $3
+)
+RETURNING *;
+
+-- name: GetOrganizationMember :one
+SELECT * FROM organization_members
+WHERE organization_id = $1 AND user_id = $2 LIMIT 1;
+
+-- name: ListOrganizationMembers :many
+SELECT m.organization_id, m.user_id, m.role, m.created_at, u.name, u.email FROM organization_members m
+JOIN users u ON u.id = m.user_id
+WHERE m.organization_id = $1 AND u.deleted_at IS NULL
+ORDER BY m.created_at;
+
+-- name: UpdateOrganizationMemberRole :one
+UPDATE organization_members
+SET role = $3
+WHERE organization_id = $1 AND user_id = $2
+RETURNING *;
+
+-- name: RemoveOrganizationMember :exec
+DELETE FROM organization_members
+WHERE organization_id = $1 AND user_id = $2;
+
+-- name: CountOrganizationOwners :one
+SELECT COUNT(*) FROM organization_members om
+JOIN users u ON u.id = om.user_id
+WHERE om.organization_id = $1 AND om.role = 'owner' AND u.deleted_at IS NULL;
+
+-- name: CreateOrganizationInvitation :one
+INSERT INTO organization_invitations (
+  organization_id, email, role, token_hash, invited_by, expires_at
+) VALUES (
+  $1, $2, $3, $4, $5, $6
+)
+RETURNING *;
+
+-- name: GetOrganizationInvitationByTokenHash :one
+SELECT * FROM organization_invitations
+WHERE token_hash = $1 LIMIT 1;
+
+-- name: AcceptOrganizationInvitation :one
+UPDATE organization_invitations
+SET accepted_at = NOW()
+WHERE id = $1 AND accepted_at IS NULL
+RETURNING *;
@@ -0,0 +1,89 @@
+-- name: CreateRole :one
+INSERT INTO roles (
+  name, description
+) VALUES (
+  $1, $2
+)
+RETURNING *;
+
+-- name: GetRoleByName :one
+SELECT * FROM roles
+WHERE name = $1 LIMIT 1;
+
+-- name: ListRoles :many
+SELECT * FROM roles
+ORDER BY name;
+
+-- name: DeleteRole :exec
+DELETE FROM roles
+WHERE id = $1;
+
+-- name: CreatePermission :one
+INSERT INTO permissions (
+  name, description
+) VALUES (
+  $1, $2
+)
+RETURNING *;
+
+-- name: GetPermissionByName :one
+SELECT * FROM permissions
+WHERE name = $1 LIMIT 1;
+
+-- name: ListPermissions :many
+SELECT * FROM permissions
+ORDER BY name;
+
+-- name: DeletePermission :exec
+DELETE FROM permissions
+WHERE id = $1;
+
+-- name: GrantRolePermission :exec
+INSERT INTO role_permissions (
+  role_id, permission_id
+) VALUES (
+  $1, $2
+)
+ON CONFLICT DO NOTHING;
+
+-- name: RevokeRolePermission :exec
+DELETE FROM role_permissions
+WHERE role_id = $1 AND permission_id = $2;
+
+-- name: ListRolePermissions :many
+SELECT p.* FROM permissions p
+JOIN role_permissions rp ON rp.permission_id = p.id
+WHERE rp.role_id = $1
+ORDER BY p.name;
+
+-- name: AssignUserRole :exec
+INSERT INTO user_roles (
+  user_id, role_id
+) VALUES (
+  $1, $2
+)
+ON CONFLICT DO NOTHING;
+
+-- name: RevokeUserRole :exec
+DELETE FROM user_roles
+WHERE user_id = $1 AND role_id = $2;
+
+-- name: ListUserRoles :many
+SELECT r.name FROM roles r
+JOIN user_roles ur ON ur.role_id = r.id
+JOIN users u ON u.id = ur.user_id
+WHERE ur.user_id = $1 AND u.deleted_at IS NULL
+ORDER BY r.name;
+
+-- name: ListUserPermissions :many
+SELECT DISTINCT p.name FROM permissions p
+JOIN role_permissions rp ON rp.permission_id = p.id
+JOIN user_roles ur ON ur.role_id = rp.role_id
+JOIN users u ON u.id = ur.user_id
+WHERE ur.user_id = $1 AND u.deleted_at IS NULL
+ORDER BY p.name;
+
+-- name: CountRoleUsers :one
+SELECT COUNT(*) FROM user_roles ur
+JOIN users u ON u.id = ur.user_id
+WHERE ur.role_id = $1 AND u.deleted_at IS NULL AND u.status = 'active';
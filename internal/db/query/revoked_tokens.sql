@@ -0,0 +1,41 @@
+-- name: RevokeToken :exec
+INSERT INTO revoked_tokens (
+  token_id, expires_at
+) VALUES (
+  $1, $2
+)
+ON CONFLICT (token_id) DO NOTHING;
+
+-- name: IsTokenRevoked :one
+SELECT EXISTS (
+  SELECT 1 FROM revoked_tokens
+  WHERE token_id = $1
+);
+
+-- name: DeleteExpiredRevokedTokens :exec
+DELETE FROM revoked_tokens
+WHERE expires_at < $1;
+
+-- name: RevokeUserTokens :exec
+INSERT INTO revoked_user_tokens (
+  user_id, issued_before, expires_at
+) VALUES (
+  $1, $2, $3
+)
+ON CONFLICT (user_id) DO UPDATE
+SET issued_before = GREATEST(revoked_user_tokens.issued_before, EXCLUDED.issued_before),
+    expires_at = GREATEST(revoked_user_tokens.expires_at, EXCLUDED.expires_at);
+
+-- name: IsUserTokenRevoked :one
+SELECT EXISTS (
+  SELECT 1 FROM revoked_user_tokens
+  WHERE user_id = $1 AND issued_before > $2
+);
+
+-- name: GetUserTokenRevocation :one
+SELECT issued_before FROM revoked_user_tokens
+WHERE user_id = $1;
+
+-- name: DeleteExpiredRevokedUserTokens :exec
+DELETE FROM revoked_user_tokens
+WHERE expires_at < $1;
@@ -0,0 +1,37 @@
+-- name: CreateSession :one
+INSERT INTO sessions (
+  user_id, family_id, refresh_token_hash, user_agent, client_ip, expires_at, organization_id
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7
+)
+RETURNING *;
+
+-- name: GetSessionByRefreshTokenHash :one
+SELECT * FROM sessions
+WHERE refresh_token_hash = $1 LIMIT 1;
+
+-- name: MarkSessionUsed :one
+UPDATE sessions
+SET used_at = NOW()
+WHERE id = $1 AND used_at IS NULL AND revoked_at IS NULL
+RETURNING *;
+
+-- name: RevokeSessionFamily :exec
+UPDATE sessions
+SET revoked_at = NOW()
+WHERE family_id = $1 AND revoked_at IS NULL;
+
+-- name: RevokeUserSessions :exec
+UPDATE sessions
+SET revoked_at = NOW()
+WHERE user_id = $1 AND revoked_at IS NULL;
+
+-- name: SetSessionOrganization :exec
+UPDATE sessions
+SET organization_id = $2
+WHERE family_id = $1 AND revoked_at IS NULL;
+
+-- name: RevokeOtherUserSessions :exec
+UPDATE sessions
+SET revoked_at = NOW()
+WHERE user_id = $1 AND family_id <> $2 AND revoked_at IS NULL;
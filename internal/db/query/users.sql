@@ -1,10 +1,10 @@
 -- name: GetUser :one
 SELECT * FROM users
-WHERE id = $1 LIMIT 1;
+WHERE id = $1 AND deleted_at IS NULL LIMIT 1;
 
 -- name: GetUserByEmail :one
 SELECT * FROM users
-WHERE email = $1 LIMIT 1;
+WHERE email = $1 AND deleted_at IS NULL LIMIT 1;
 
 -- name: CreateUser :one
 INSERT INTO users (
@@ -13,3 +13,74 @@ INSERT INTO users (
   $1, $2, $3
 )
 RETURNING *;
+
+-- name: UpdateUserPassword :one
+UPDATE users
+SET password = $2, password_changed_at = $3
+WHERE id = $1 AND deleted_at IS NULL
+RETURNING *;
+
+-- name: VerifyUserEmail :one
+UPDATE users
+SET email_verified_at = COALESCE(email_verified_at, NOW()),
+    status = CASE WHEN status = 'pending' THEN 'active' ELSE status END,
+    status_changed_at = CASE WHEN status = 'pending' THEN NOW() ELSE status_changed_at END
+WHERE id = $1 AND email = $2 AND deleted_at IS NULL
+RETURNING *;
+
+-- name: UpdateUserProfile :one
+UPDATE users
+SET name = COALESCE(sqlc.narg(name), name)
+WHERE id = sqlc.arg(id) AND updated_at = sqlc.arg(updated_at) AND deleted_at IS NULL
+RETURNING *;
+
+-- name: UpdateUserEmail :one
+UPDATE users
+SET email = sqlc.arg(new_email), email_verified_at = NOW(),
+    status = CASE WHEN status = 'pending' THEN 'active' ELSE status END,
+    status_changed_at = CASE WHEN status = 'pending' THEN NOW() ELSE status_changed_at END
+WHERE id = sqlc.arg(id) AND email = sqlc.arg(old_email) AND deleted_at IS NULL
+RETURNING *;
+
+-- name: GetDeletedUserByEmail :one
+SELECT * FROM users
+WHERE email = $1 AND deleted_at IS NOT NULL LIMIT 1;
+
+-- name: SoftDeleteUser :one
+UPDATE users
+SET deleted_at = $2, status = 'deleted', status_changed_at = $2
+WHERE id = $1 AND deleted_at IS NULL
+RETURNING *;
+
+-- name: RestoreUser :one
+UPDATE users
+SET deleted_at = NULL,
+    status = CASE WHEN email_verified_at IS NULL THEN 'pending' ELSE 'active' END,
+    status_reason = 'account restored',
+    status_changed_at = NOW()
+WHERE id = sqlc.arg(id) AND deleted_at > sqlc.arg(deleted_after)
+RETURNING *;
+
+-- name: RestoreUserEmail :one
+UPDATE users
+SET email = sqlc.arg(email),
+    email_verified_at = NOW(),
+    status = CASE WHEN status = 'pending' THEN 'active' ELSE status END,
+    status_changed_at = CASE WHEN status = 'pending' THEN NOW() ELSE status_changed_at END
+WHERE id = sqlc.arg(id) AND deleted_at IS NULL
+RETURNING *;
+
+-- name: PurgeDeletedUsers :execrows
+DELETE FROM users
+WHERE deleted_at < sqlc.arg(deleted_before);
+
+-- name: GetUserStatus :one
+SELECT status, suspended_until FROM users
+WHERE id = $1 LIMIT 1;
+
+-- name: UpdateUserStatus :one
+UPDATE users
+SET status = sqlc.arg(status), status_reason = sqlc.narg(status_reason),
+    suspended_until = sqlc.narg(suspended_until), status_changed_at = NOW()
+WHERE id = sqlc.arg(id) AND status = sqlc.arg(current_status) AND deleted_at IS NULL
+RETURNING *;
@@ -0,0 +1,70 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/suryansh74/auth-package/internal/db/sqlc"
+	"github.com/suryansh74/auth-package/token"
+)
+
+// RevocationStorePsql is a token.RevocationStore backed by the
+// revoked_tokens table, shared by every instance using the same database
+type RevocationStorePsql struct {
+	auth Auth
+}
+
+func NewRevocationStore(auth Auth) token.RevocationStore {
+	return &RevocationStorePsql{
+		auth: auth,
+	}
+}
+
+func (store *RevocationStorePsql) Revoke(ctx context.Context, id uuid.UUID, expiresAt time.Time) error {
+	return store.auth.RevokeToken(ctx, sqlc.RevokeTokenParams{
+		TokenID:   pgtype.UUID{Bytes: id, Valid: true},
+		ExpiresAt: pgtype.Timestamp{Time: expiresAt, Valid: true},
+	})
+}
+
+func (store *RevocationStorePsql) RevokeUserTokens(ctx context.Context, userID pgtype.UUID, issuedBefore, expiresAt time.Time) error {
+	return store.auth.RevokeUserTokens(ctx, sqlc.RevokeUserTokensParams{
+		UserID:       userID,
+		IssuedBefore: pgtype.Timestamp{Time: issuedBefore, Valid: true},
+		ExpiresAt:    pgtype.Timestamp{Time: expiresAt, Valid: true},
+	})
+}
+
+func (store *RevocationStorePsql) UserRevocationCutoff(ctx context.Context, userID pgtype.UUID) (time.Time, error) {
+	issuedBefore, err := store.auth.GetUserTokenRevocation(ctx, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return time.Time{}, nil
+		}
+		return time.Time{}, err
+	}
+	return issuedBefore.Time, nil
+}
+
+func (store *RevocationStorePsql) IsRevoked(ctx context.Context, payload *token.Payload) (bool, error) {
+	revoked, err := store.auth.IsTokenRevoked(ctx, pgtype.UUID{Bytes: payload.ID, Valid: true})
+	if err != nil || revoked {
+		return revoked, err
+	}
+	return store.auth.IsUserTokenRevoked(ctx, sqlc.IsUserTokenRevokedParams{
+		UserID:       payload.UserID,
+		IssuedBefore: pgtype.Timestamp{Time: payload.IssuedAt, Valid: true},
+	})
+}
+
+func (store *RevocationStorePsql) DeleteExpired(ctx context.Context) error {
+	now := pgtype.Timestamp{Time: time.Now(), Valid: true}
+	if err := store.auth.DeleteExpiredRevokedTokens(ctx, now); err != nil {
+		return err
+	}
+	return store.auth.DeleteExpiredRevokedUserTokens(ctx, now)
+}
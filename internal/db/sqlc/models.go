@@ -8,12 +8,118 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
-type User struct {
+type EmailChangeRequest struct {
+	ID               pgtype.UUID      `json:"id"`
+	UserID           pgtype.UUID      `json:"user_id"`
+	OldEmail         string           `json:"old_email"`
+	NewEmail         string           `json:"new_email"`
+	ConfirmTokenHash string           `json:"confirm_token_hash"`
+	CancelTokenHash  string           `json:"cancel_token_hash"`
+	ExpiresAt        pgtype.Timestamp `json:"expires_at"`
+	ConfirmedAt      pgtype.Timestamp `json:"confirmed_at"`
+	CancelledAt      pgtype.Timestamp `json:"cancelled_at"`
+	CreatedAt        pgtype.Timestamp `json:"created_at"`
+}
+
+type Organization struct {
 	ID        pgtype.UUID      `json:"id"`
 	Name      string           `json:"name"`
-	Email     string           `json:"email"`
-	Password  string           `json:"password"`
+	Slug      string           `json:"slug"`
+	CreatedAt pgtype.Timestamp `json:"created_at"`
+}
+
+type OrganizationInvitation struct {
+	ID             pgtype.UUID      `json:"id"`
+	OrganizationID pgtype.UUID      `json:"organization_id"`
+	Email          string           `json:"email"`
+	Role           string           `json:"role"`
+	TokenHash      string           `json:"token_hash"`
+	InvitedBy      pgtype.UUID      `json:"invited_by"`
+	ExpiresAt      pgtype.Timestamp `json:"expires_at"`
+	AcceptedAt     pgtype.Timestamp `json:"accepted_at"`
+	CreatedAt      pgtype.Timestamp `json:"created_at"`
+}
+
+type OrganizationMember struct {
+	OrganizationID pgtype.UUID      `json:"organization_id"`
+	UserID         pgtype.UUID      `json:"user_id"`
+	Role           string           `json:"role"`
+	CreatedAt      pgtype.Timestamp `json:"created_at"`
+}
+
+type PasswordResetToken struct {
+	ID        pgtype.UUID      `json:"id"`
+	UserID    pgtype.UUID      `json:"user_id"`
+	TokenHash string           `json:"token_hash"`
+	ExpiresAt pgtype.Timestamp `json:"expires_at"`
+	UsedAt    pgtype.Timestamp `json:"used_at"`
+	CreatedAt pgtype.Timestamp `json:"created_at"`
+}
+
+type Permission struct {
+	ID          pgtype.UUID      `json:"id"`
+	Name        string           `json:"name"`
+	Description string           `json:"description"`
+	CreatedAt   pgtype.Timestamp `json:"created_at"`
+}
+
+type RevokedToken struct {
+	TokenID   pgtype.UUID      `json:"token_id"`
+	ExpiresAt pgtype.Timestamp `json:"expires_at"`
+	RevokedAt pgtype.Timestamp `json:"revoked_at"`
+}
+
+type RevokedUserToken struct {
+	UserID       pgtype.UUID      `json:"user_id"`
+	IssuedBefore pgtype.Timestamp `json:"issued_before"`
+	ExpiresAt    pgtype.Timestamp `json:"expires_at"`
+}
+
+type Role struct {
+	ID          pgtype.UUID      `json:"id"`
+	Name        string           `json:"name"`
+	Description string           `json:"description"`
+	CreatedAt   pgtype.Timestamp `json:"created_at"`
+}
+
+type RolePermission struct {
+	RoleID       pgtype.UUID      `json:"role_id"`
+	PermissionID pgtype.UUID      `json:"permission_id"`
+	CreatedAt    pgtype.Timestamp `json:"created_at"`
+}
+
+type Session struct {
+	ID               pgtype.UUID      `json:"id"`
+	UserID           pgtype.UUID      `json:"user_id"`
+	FamilyID         pgtype.UUID      `json:"family_id"`
+	RefreshTokenHash string           `json:"refresh_token_hash"`
+	UserAgent        string           `json:"user_agent"`
+	ClientIp         string           `json:"client_ip"`
+	ExpiresAt        pgtype.Timestamp `json:"expires_at"`
+	UsedAt           pgtype.Timestamp `json:"used_at"`
+	RevokedAt        pgtype.Timestamp `json:"revoked_at"`
+	CreatedAt        pgtype.Timestamp `json:"created_at"`
+	OrganizationID   pgtype.UUID      `json:"organization_id"`
+}
+
+type User struct {
+	ID                pgtype.UUID      `json:"id"`
+	Name              string           `json:"name"`
+	Email             string           `json:"email"`
+	Password          string           `json:"password"`
+	CreatedAt         pgtype.Timestamp `json:"created_at"`
+	UpdatedAt         pgtype.Timestamp `json:"updated_at"`
+	DeletedAt         pgtype.Timestamp `json:"deleted_at"`
+	PasswordChangedAt pgtype.Timestamp `json:"password_changed_at"`
+	EmailVerifiedAt   pgtype.Timestamp `json:"email_verified_at"`
+	Status            string           `json:"status"`
+	StatusReason      pgtype.Text      `json:"status_reason"`
+	StatusChangedAt   pgtype.Timestamp `json:"status_changed_at"`
+	SuspendedUntil    pgtype.Timestamp `json:"suspended_until"`
+}
+
+type UserRole struct {
+	UserID    pgtype.UUID      `json:"user_id"`
+	RoleID    pgtype.UUID      `json:"role_id"`
 	CreatedAt pgtype.Timestamp `json:"created_at"`
-	UpdatedAt pgtype.Timestamp `json:"updated_at"`
-	DeletedAt pgtype.Timestamp `json:"deleted_at"`
 }
@@ -11,9 +11,76 @@ import (
 )
 
 type Querier interface {
+	AcceptOrganizationInvitation(ctx context.Context, id pgtype.UUID) (OrganizationInvitation, error)
+	AddOrganizationMember(ctx context.Context, arg AddOrganizationMemberParams) (OrganizationMember, error)
+	AssignUserRole(ctx context.Context, arg AssignUserRoleParams) error
+	CancelEmailChangeRequest(ctx context.Context, id pgtype.UUID) (EmailChangeRequest, error)
+	CancelOtherUserEmailChangeRequests(ctx context.Context, arg CancelOtherUserEmailChangeRequestsParams) error
+	CancelUserEmailChangeRequests(ctx context.Context, userID pgtype.UUID) error
+	ConfirmEmailChangeRequest(ctx context.Context, id pgtype.UUID) (EmailChangeRequest, error)
+	CountOrganizationOwners(ctx context.Context, organizationID pgtype.UUID) (int64, error)
+	CountRoleUsers(ctx context.Context, roleID pgtype.UUID) (int64, error)
+	CreateEmailChangeRequest(ctx context.Context, arg CreateEmailChangeRequestParams) (EmailChangeRequest, error)
+	CreateOrganization(ctx context.Context, arg CreateOrganizationParams) (Organization, error)
+	CreateOrganizationInvitation(ctx context.Context, arg CreateOrganizationInvitationParams) (OrganizationInvitation, error)
+	CreatePasswordResetToken(ctx context.Context, arg CreatePasswordResetTokenParams) (PasswordResetToken, error)
+	CreatePermission(ctx context.Context, arg CreatePermissionParams) (Permission, error)
+	CreateRole(ctx context.Context, arg CreateRoleParams) (Role, error)
+	CreateSession(ctx context.Context, arg CreateSessionParams) (Session, error)
 	CreateUser(ctx context.Context, arg CreateUserParams) (User, error)
+	DeleteExpiredRevokedTokens(ctx context.Context, expiresAt pgtype.Timestamp) error
+	DeleteExpiredRevokedUserTokens(ctx context.Context, expiresAt pgtype.Timestamp) error
+	DeletePermission(ctx context.Context, id pgtype.UUID) error
+	DeleteRole(ctx context.Context, id pgtype.UUID) error
+	GetDeletedUserByEmail(ctx context.Context, email string) (User, error)
+	GetEmailChangeRequestByCancelTokenHash(ctx context.Context, cancelTokenHash string) (EmailChangeRequest, error)
+	GetEmailChangeRequestByConfirmTokenHash(ctx context.Context, confirmTokenHash string) (EmailChangeRequest, error)
+	GetOrganization(ctx context.Context, id pgtype.UUID) (Organization, error)
+	GetOrganizationBySlug(ctx context.Context, slug string) (Organization, error)
+	GetOrganizationInvitationByTokenHash(ctx context.Context, tokenHash string) (OrganizationInvitation, error)
+	GetOrganizationMember(ctx context.Context, arg GetOrganizationMemberParams) (OrganizationMember, error)
+	GetPasswordResetTokenByHash(ctx context.Context, tokenHash string) (PasswordResetToken, error)
+	GetPermissionByName(ctx context.Context, name string) (Permission, error)
+	GetRoleByName(ctx context.Context, name string) (Role, error)
+	GetSessionByRefreshTokenHash(ctx context.Context, refreshTokenHash string) (Session, error)
 	GetUser(ctx context.Context, id pgtype.UUID) (User, error)
 	GetUserByEmail(ctx context.Context, email string) (User, error)
+	GetUserStatus(ctx context.Context, id pgtype.UUID) (GetUserStatusRow, error)
+	GetUserTokenRevocation(ctx context.Context, userID pgtype.UUID) (pgtype.Timestamp, error)
+	GrantRolePermission(ctx context.Context, arg GrantRolePermissionParams) error
+	InvalidateUserPasswordResetTokens(ctx context.Context, userID pgtype.UUID) error
+	IsTokenRevoked(ctx context.Context, tokenID pgtype.UUID) (bool, error)
+	IsUserTokenRevoked(ctx context.Context, arg IsUserTokenRevokedParams) (bool, error)
+	ListOrganizationMembers(ctx context.Context, organizationID pgtype.UUID) ([]ListOrganizationMembersRow, error)
+	ListPermissions(ctx context.Context) ([]Permission, error)
+	ListRolePermissions(ctx context.Context, roleID pgtype.UUID) ([]Permission, error)
+	ListRoles(ctx context.Context) ([]Role, error)
+	ListUserOrganizations(ctx context.Context, userID pgtype.UUID) ([]ListUserOrganizationsRow, error)
+	ListUserPermissions(ctx context.Context, userID pgtype.UUID) ([]string, error)
+	ListUserRoles(ctx context.Context, userID pgtype.UUID) ([]string, error)
+	LockOrganization(ctx context.Context, id pgtype.UUID) (pgtype.UUID, error)
+	LockOwnedOrganizations(ctx context.Context, userID pgtype.UUID) ([]pgtype.UUID, error)
+	MarkSessionUsed(ctx context.Context, id pgtype.UUID) (Session, error)
+	PurgeDeletedUsers(ctx context.Context, deletedBefore pgtype.Timestamp) (int64, error)
+	RemoveOrganizationMember(ctx context.Context, arg RemoveOrganizationMemberParams) error
+	RestoreUser(ctx context.Context, arg RestoreUserParams) (User, error)
+	RestoreUserEmail(ctx context.Context, arg RestoreUserEmailParams) (User, error)
+	RevokeOtherUserSessions(ctx context.Context, arg RevokeOtherUserSessionsParams) error
+	RevokeRolePermission(ctx context.Context, arg RevokeRolePermissionParams) error
+	RevokeSessionFamily(ctx context.Context, familyID pgtype.UUID) error
+	RevokeToken(ctx context.Context, arg RevokeTokenParams) error
+	RevokeUserRole(ctx context.Context, arg RevokeUserRoleParams) error
+	RevokeUserSessions(ctx context.Context, userID pgtype.UUID) error
+	RevokeUserTokens(ctx context.Context, arg RevokeUserTokensParams) error
+	SetSessionOrganization(ctx context.Context, arg SetSessionOrganizationParams) error
+	SoftDeleteUser(ctx context.Context, arg SoftDeleteUserParams) (User, error)
+	UpdateOrganizationMemberRole(ctx context.Context, arg UpdateOrganizationMemberRoleParams) (OrganizationMember, error)
+	UpdateUserEmail(ctx context.Context, arg UpdateUserEmailParams) (User, error)
+	UpdateUserPassword(ctx context.Context, arg UpdateUserPasswordParams) (User, error)
+	UpdateUserProfile(ctx context.Context, arg UpdateUserProfileParams) (User, error)
+	UpdateUserStatus(ctx context.Context, arg UpdateUserStatusParams) (User, error)
+	UsePasswordResetToken(ctx context.Context, id pgtype.UUID) (PasswordResetToken, error)
+	VerifyUserEmail(ctx context.Context, arg VerifyUserEmailParams) (User, error)
 }
 
 var _ Querier = (*Queries)(nil)
@@ -17,7 +17,7 @@ INSERT INTO users (
 ) VALUES (
   $1, $2, $3
 )
-RETURNING id, name, email, password, created_at, updated_at, deleted_at
+RETURNING id, name, email, password, created_at, updated_at, deleted_at, password_changed_at, email_verified_at, status, status_reason, status_changed_at, suspended_until
 `
 
 type CreateUserParams struct {
@@ -37,13 +37,45 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.DeletedAt,
+		&i.PasswordChangedAt,
+		&i.EmailVerifiedAt,
+		&i.Status,
+		&i.StatusReason,
+		&i.StatusChangedAt,
+		&i.SuspendedUntil,
+	)
+	return i, err
+}
+
+const getDeletedUserByEmail = `-- name: GetDeletedUserByEmail :one
+SELECT id, name, email, password, created_at, updated_at, deleted_at, password_changed_at, email_verified_at, status, status_reason, status_changed_at, suspended_until FROM users
+WHERE email = $1 AND deleted_at IS NOT NULL LIMIT 1
+`
+
+func (q *Queries) GetDeletedUserByEmail(ctx context.Context, email string) (User, error) {
+	row := q.db.QueryRow(ctx, getDeletedUserByEmail, email)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Email,
+		&i.Password,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.PasswordChangedAt,
+		&i.EmailVerifiedAt,
+		&i.Status,
+		&i.StatusReason,
+		&i.StatusChangedAt,
+		&i.SuspendedUntil,
 	)
 	return i, err
 }
 
 const getUser = `-- name: GetUser :one
-SELECT id, name, email, password, created_at, updated_at, deleted_at FROM users
-WHERE id = $1 LIMIT 1
+SELECT id, name, email, password, created_at, updated_at, deleted_at, password_changed_at, email_verified_at, status, status_reason, status_changed_at, suspended_until FROM users
+WHERE id = $1 AND deleted_at IS NULL LIMIT 1
 `
 
 func (q *Queries) GetUser(ctx context.Context, id pgtype.UUID) (User, error) {
@@ -57,13 +89,19 @@ func (q *Queries) GetUser(ctx context.Context, id pgtype.UUID) (User, error) {
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.DeletedAt,
+		&i.PasswordChangedAt,
+		&i.EmailVerifiedAt,
+		&i.Status,
+		&i.StatusReason,
+		&i.StatusChangedAt,
+		&i.SuspendedUntil,
 	)
 	return i, err
 }
 
 const getUserByEmail = `-- name: GetUserByEmail :one
-SELECT id, name, email, password, created_at, updated_at, deleted_at FROM users
-WHERE email = $1 LIMIT 1
+SELECT id, name, email, password, created_at, updated_at, deleted_at, password_changed_at, email_verified_at, status, status_reason, status_changed_at, suspended_until FROM users
+WHERE email = $1 AND deleted_at IS NULL LIMIT 1
 `
 
 func (q *Queries) GetUserByEmail(ctx context.Context, email string) (User, error) {
@@ -77,6 +115,329 @@ func (q *Queries) GetUserByEmail(ctx context.Context, email string) (User, error
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.DeletedAt,
+		&i.PasswordChangedAt,
+		&i.EmailVerifiedAt,
+		&i.Status,
+		&i.StatusReason,
+		&i.StatusChangedAt,
+		&i.SuspendedUntil,
+	)
+	return i, err
+}
+
+const getUserStatus = `-- name: GetUserStatus :one
+SELECT status, suspended_until FROM users
+WHERE id = $1 LIMIT 1
+`
+
+type GetUserStatusRow struct {
+	Status         string           `json:"status"`
+	SuspendedUntil pgtype.Timestamp `json:"suspended_until"`
+}
+
+func (q *Queries) GetUserStatus(ctx context.Context, id pgtype.UUID) (GetUserStatusRow, error) {
+	row := q.db.QueryRow(ctx, getUserStatus, id)
+	var i GetUserStatusRow
+	err := row.Scan(&i.Status, &i.SuspendedUntil)
+	return i, err
+}
+
+const purgeDeletedUsers = `-- name: PurgeDeletedUsers :execrows
+DELETE FROM users
+WHERE deleted_at < $1
+`
+
+func (q *Queries) PurgeDeletedUsers(ctx context.Context, deletedBefore pgtype.Timestamp) (int64, error) {
+	result, err := q.db.Exec(ctx, purgeDeletedUsers, deletedBefore)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const restoreUser = `-- name: RestoreUser :one
+UPDATE users
+SET deleted_at = NULL,
+    status = CASE WHEN email_verified_at IS NULL THEN 'pending' ELSE 'active' END,
+    status_reason = 'account restored',
+    status_changed_at = NOW()
+WHERE id = $1 AND deleted_at > $2
+RETURNING id, name, email, password, created_at, updated_at, deleted_at, password_changed_at, email_verified_at, status, status_reason, status_changed_at, suspended_until
+`
+
+type RestoreUserParams struct {
+	ID           pgtype.UUID      `json:"id"`
+	DeletedAfter pgtype.Timestamp `json:"deleted_after"`
+}
+
+func (q *Queries) RestoreUser(ctx context.Context, arg RestoreUserParams) (User, error) {
+	row := q.db.QueryRow(ctx, restoreUser, arg.ID, arg.DeletedAfter)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Email,
+		&i.Password,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.PasswordChangedAt,
+		&i.EmailVerifiedAt,
+		&i.Status,
+		&i.StatusReason,
+		&i.StatusChangedAt,
+		&i.SuspendedUntil,
+	)
+	return i, err
+}
+
+const restoreUserEmail = `-- name: RestoreUserEmail :one
+UPDATE users
+SET email = $1,
+    email_verified_at = NOW(),
+    status = CASE WHEN status = 'pending' THEN 'active' ELSE status END,
+    status_changed_at = CASE WHEN status = 'pending' THEN NOW() ELSE status_changed_at END
+WHERE id = $2 AND deleted_at IS NULL
+RETURNING id, name, email, password, created_at, updated_at, deleted_at, password_changed_at, email_verified_at, status, status_reason, status_changed_at, suspended_until
+`
+
+type RestoreUserEmailParams struct {
+	Email string      `json:"email"`
+	ID    pgtype.UUID `json:"id"`
+}
+
+func (q *Queries) RestoreUserEmail(ctx context.Context, arg RestoreUserEmailParams) (User, error) {
+	row := q.db.QueryRow(ctx, restoreUserEmail, arg.Email, arg.ID)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Email,
+		&i.Password,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.PasswordChangedAt,
+		&i.EmailVerifiedAt,
+		&i.Status,
+		&i.StatusReason,
+		&i.StatusChangedAt,
+		&i.SuspendedUntil,
+	)
+	return i, err
+}
+
+const softDeleteUser = `-- name: SoftDeleteUser :one
+UPDATE users
+SET deleted_at = $2, status = 'deleted', status_changed_at = $2
+WHERE id = $1 AND deleted_at IS NULL
+RETURNING id, name, email, password, created_at, updated_at, deleted_at, password_changed_at, email_verified_at, status, status_reason, status_changed_at, suspended_until
+`
+
+type SoftDeleteUserParams struct {
+	ID        pgtype.UUID      `json:"id"`
+	DeletedAt pgtype.Timestamp `json:"deleted_at"`
+}
+
+func (q *Queries) SoftDeleteUser(ctx context.Context, arg SoftDeleteUserParams) (User, error) {
+	row := q.db.QueryRow(ctx, softDeleteUser, arg.ID, arg.DeletedAt)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Email,
+		&i.Password,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.PasswordChangedAt,
+		&i.EmailVerifiedAt,
+		&i.Status,
+		&i.StatusReason,
+		&i.StatusChangedAt,
+		&i.SuspendedUntil,
+	)
+	return i, err
+}
+
+const updateUserEmail = `-- name: UpdateUserEmail :one
+UPDATE users
+SET email = $1, email_verified_at = NOW(),
+    status = CASE WHEN status = 'pending' THEN 'active' ELSE status END,
+    status_changed_at = CASE WHEN status = 'pending' THEN NOW() ELSE status_changed_at END
+WHERE id = $2 AND email = $3 AND deleted_at IS NULL
+RETURNING id, name, email, password, created_at, updated_at, deleted_at, password_changed_at, email_verified_at, status, status_reason, status_changed_at, suspended_until
+`
+
+type UpdateUserEmailParams struct {
+	NewEmail string      `json:"new_email"`
+	ID       pgtype.UUID `json:"id"`
+	OldEmail string      `json:"old_email"`
+}
+
+func (q *Queries) UpdateUserEmail(ctx context.Context, arg UpdateUserEmailParams) (User, error) {
+	row := q.db.QueryRow(ctx, updateUserEmail, arg.NewEmail, arg.ID, arg.OldEmail)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Email,
+		&i.Password,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.PasswordChangedAt,
+		&i.EmailVerifiedAt,
+		&i.Status,
+		&i.StatusReason,
+		&i.StatusChangedAt,
+		&i.SuspendedUntil,
+	)
+	return i, err
+}
+
+const updateUserPassword = `-- name: UpdateUserPassword :one
+UPDATE users
+SET password = $2, password_changed_at = $3
+WHERE id = $1 AND deleted_at IS NULL
+RETURNING id, name, email, password, created_at, updated_at, deleted_at, password_changed_at, email_verified_at, status, status_reason, status_changed_at, suspended_until
+`
+
+type UpdateUserPasswordParams struct {
+	ID                pgtype.UUID      `json:"id"`
+	Password          string           `json:"password"`
+	PasswordChangedAt pgtype.Timestamp `json:"password_changed_at"`
+}
+
+func (q *Queries) UpdateUserPassword(ctx context.Context, arg UpdateUserPasswordParams) (User, error) {
+	row := q.db.QueryRow(ctx, updateUserPassword, arg.ID, arg.Password, arg.PasswordChangedAt)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Email,
+		&i.Password,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.PasswordChangedAt,
+		&i.EmailVerifiedAt,
+		&i.Status,
+		&i.StatusReason,
+		&i.StatusChangedAt,
+		&i.SuspendedUntil,
+	)
+	return i, err
+}
+
+const updateUserProfile = `-- name: UpdateUserProfile :one
+UPDATE users
+SET name = COALESCE($1, name)
+WHERE id = $2 AND updated_at = $3 AND deleted_at IS NULL
+RETURNING id, name, email, password, created_at, updated_at, deleted_at, password_changed_at, email_verified_at, status, status_reason, status_changed_at, suspended_until
+`
+
+type UpdateUserProfileParams struct {
+	Name      pgtype.Text      `json:"name"`
+	ID        pgtype.UUID      `json:"id"`
+	UpdatedAt pgtype.Timestamp `json:"updated_at"`
+}
+
+func (q *Queries) UpdateUserProfile(ctx context.Context, arg UpdateUserProfileParams) (User, error) {
+	row := q.db.QueryRow(ctx, updateUserProfile, arg.Name, arg.ID, arg.UpdatedAt)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Email,
+		&i.Password,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.PasswordChangedAt,
+		&i.EmailVerifiedAt,
+		&i.Status,
+		&i.StatusReason,
+		&i.StatusChangedAt,
+		&i.SuspendedUntil,
+	)
+	return i, err
+}
+
+const updateUserStatus = `-- name: UpdateUserStatus :one
+UPDATE users
+SET status = $1, status_reason = $2,
+    suspended_until = $3, status_changed_at = NOW()
+WHERE id = $4 AND status = $5 AND deleted_at IS NULL
+RETURNING id, name, email, password, created_at, updated_at, deleted_at, password_changed_at, email_verified_at, status, status_reason, status_changed_at, suspended_until
+`
+
+type UpdateUserStatusParams struct {
+	Status         string           `json:"status"`
+	StatusReason   pgtype.Text      `json:"status_reason"`
+	SuspendedUntil pgtype.Timestamp `json:"suspended_until"`
+	ID             pgtype.UUID      `json:"id"`
+	CurrentStatus  string           `json:"current_status"`
+}
+
+func (q *Queries) UpdateUserStatus(ctx context.Context, arg UpdateUserStatusParams) (User, error) {
+	row := q.db.QueryRow(ctx, updateUserStatus,
+		arg.Status,
+		arg.StatusReason,
+		arg.SuspendedUntil,
+		arg.ID,
+		arg.CurrentStatus,
+	)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Email,
+		&i.Password,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.PasswordChangedAt,
+		&i.EmailVerifiedAt,
+		&i.Status,
+		&i.StatusReason,
+		&i.StatusChangedAt,
+		&i.SuspendedUntil,
+	)
+	return i, err
+}
+
+const verifyUserEmail = `-- name: VerifyUserEmail :one
+UPDATE users
+SET email_verified_at = COALESCE(email_verified_at, NOW()),
+    status = CASE WHEN status = 'pending' THEN 'active' ELSE status END,
+    status_changed_at = CASE WHEN status = 'pending' THEN NOW() ELSE status_changed_at END
+WHERE id = $1 AND email = $2 AND deleted_at IS NULL
+RETURNING id, name, email, password, created_at, updated_at, deleted_at, password_changed_at, email_verified_at, status, status_reason, status_changed_at, suspended_until
+`
+
+type VerifyUserEmailParams struct {
+	ID    pgtype.UUID `json:"id"`
+	Email string      `json:"email"`
+}
+
+func (q *Queries) VerifyUserEmail(ctx context.Context, arg VerifyUserEmailParams) (User, error) {
+	row := q.db.QueryRow(ctx, verifyUserEmail, arg.ID, arg.Email)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Email,
+		&i.Password,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.PasswordChangedAt,
+		&i.EmailVerifiedAt,
+		&i.Status,
+		&i.StatusReason,
+		&i.StatusChangedAt,
+		&i.SuspendedUntil,
 	)
 	return i, err
 }
@@ -5,6 +5,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/stretchr/testify/require"
 	"github.com/suryansh74/auth-package/internal/db/sqlc"
 	"github.com/suryansh74/auth-package/internal/utils"
@@ -23,6 +24,7 @@ func createRandomUser(t *testing.T) sqlc.User {
 	require.NotZero(t, user.ID)
 	require.NotZero(t, user.CreatedAt)
 	require.NotZero(t, user.UpdatedAt)
+	require.Equal(t, "pending", user.Status)
 
 	// checking wheater args and inserted values are same or not
 	require.Equal(t, arg.Name, user.Name)
@@ -65,3 +67,185 @@ func TestGetUserByID(t *testing.T) {
 	require.WithinDuration(t, user.CreatedAt.Time, returnedUser.CreatedAt.Time, time.Second)
 	require.WithinDuration(t, user.UpdatedAt.Time, returnedUser.UpdatedAt.Time, time.Second)
 }
+
+func TestUpdateUserPassword(t *testing.T) {
+	user := createRandomUser(t)
+	require.False(t, user.PasswordChangedAt.Valid)
+
+	arg := sqlc.UpdateUserPasswordParams{
+		ID:                user.ID,
+		Password:          utils.RandomPassword(10),
+		PasswordChangedAt: pgtype.Timestamp{Time: time.Now().Truncate(time.Second), Valid: true},
+	}
+	updatedUser, err := testQueries.UpdateUserPassword(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.Password, updatedUser.Password)
+	require.WithinDuration(t, arg.PasswordChangedAt.Time, updatedUser.PasswordChangedAt.Time, time.Second)
+}
+
+func TestVerifyUserEmail(t *testing.T) {
+	user := createRandomUser(t)
+	require.False(t, user.EmailVerifiedAt.Valid)
+
+	verifiedUser, err := testQueries.VerifyUserEmail(context.Background(), sqlc.VerifyUserEmailParams{
+		ID:    user.ID,
+		Email: user.Email,
+	})
+	require.NoError(t, err)
+	require.True(t, verifiedUser.EmailVerifiedAt.Valid)
+	require.Equal(t, "active", verifiedUser.Status)
+
+	// verifying again keeps the first verification time
+	again, err := testQueries.VerifyUserEmail(context.Background(), sqlc.VerifyUserEmailParams{
+		ID:    user.ID,
+		Email: user.Email,
+	})
+	require.NoError(t, err)
+	require.Equal(t, verifiedUser.EmailVerifiedAt, again.EmailVerifiedAt)
+
+	// a link sent to another address does not verify the current one
+	_, err = testQueries.VerifyUserEmail(context.Background(), sqlc.VerifyUserEmailParams{
+		ID:    user.ID,
+		Email: utils.RandomEmail(),
+	})
+	require.Error(t, err)
+}
+
+func TestUpdateUserProfile(t *testing.T) {
+	user := createRandomUser(t)
+
+	arg := sqlc.UpdateUserProfileParams{
+		Name:      pgtype.Text{String: utils.RandomString(8), Valid: true},
+		ID:        user.ID,
+		UpdatedAt: user.UpdatedAt,
+	}
+	updatedUser, err := testQueries.UpdateUserProfile(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.Name.String, updatedUser.Name)
+	require.Equal(t, user.Email, updatedUser.Email)
+
+	// a second edit based on the same read loses
+	_, err = testQueries.UpdateUserProfile(context.Background(), arg)
+	require.Error(t, err)
+
+	// fields left out keep their value
+	unchanged, err := testQueries.UpdateUserProfile(context.Background(), sqlc.UpdateUserProfileParams{
+		ID:        user.ID,
+		UpdatedAt: updatedUser.UpdatedAt,
+	})
+	require.NoError(t, err)
+	require.Equal(t, updatedUser.Name, unchanged.Name)
+}
+
+func TestSoftDeleteUser(t *testing.T) {
+	user := createRandomUser(t)
+
+	deletedAt := time.Now().Add(-2 * time.Hour)
+	deleted, err := testQueries.SoftDeleteUser(context.Background(), sqlc.SoftDeleteUserParams{
+		ID:        user.ID,
+		DeletedAt: pgtype.Timestamp{Time: deletedAt, Valid: true},
+	})
+	require.NoError(t, err)
+	require.True(t, deleted.DeletedAt.Valid)
+	require.Equal(t, "deleted", deleted.Status)
+
+	// deleted users are hidden from lookups
+	_, err = testQueries.GetUser(context.Background(), user.ID)
+	require.Error(t, err)
+	_, err = testQueries.GetUserByEmail(context.Background(), user.Email)
+	require.Error(t, err)
+	found, err := testQueries.GetDeletedUserByEmail(context.Background(), user.Email)
+	require.NoError(t, err)
+	require.Equal(t, user.ID, found.ID)
+
+	// not restorable once the grace period is over
+	_, err = testQueries.RestoreUser(context.Background(), sqlc.RestoreUserParams{
+		ID:           user.ID,
+		DeletedAfter: pgtype.Timestamp{Time: time.Now().Add(-time.Hour), Valid: true},
+	})
+	require.Error(t, err)
+
+	restored, err := testQueries.RestoreUser(context.Background(), sqlc.RestoreUserParams{
+		ID:           user.ID,
+		DeletedAfter: pgtype.Timestamp{Time: time.Now().Add(-3 * time.Hour), Valid: true},
+	})
+	require.NoError(t, err)
+	require.False(t, restored.DeletedAt.Valid)
+	require.Equal(t, "pending", restored.Status)
+	// the transition is recorded like the status changes of admins
+	require.Equal(t, pgtype.Text{String: "account restored", Valid: true}, restored.StatusReason)
+	require.True(t, restored.StatusChangedAt.Time.After(deleted.StatusChangedAt.Time))
+	_, err = testQueries.GetUser(context.Background(), user.ID)
+	require.NoError(t, err)
+}
+
+func TestUpdateUserStatus(t *testing.T) {
+	user := createRandomUser(t)
+
+	suspendedUntil := time.Now().Add(time.Hour).UTC()
+	suspended, err := testQueries.UpdateUserStatus(context.Background(), sqlc.UpdateUserStatusParams{
+		Status:         "suspended",
+		StatusReason:   pgtype.Text{String: "spam", Valid: true},
+		SuspendedUntil: pgtype.Timestamp{Time: suspendedUntil, Valid: true},
+		ID:             user.ID,
+		CurrentStatus:  user.Status,
+	})
+	require.NoError(t, err)
+	require.Equal(t, "suspended", suspended.Status)
+	require.Equal(t, "spam", suspended.StatusReason.String)
+	require.WithinDuration(t, suspendedUntil, suspended.SuspendedUntil.Time, time.Millisecond)
+	require.True(t, suspended.StatusChangedAt.Valid)
+
+	status, err := testQueries.GetUserStatus(context.Background(), user.ID)
+	require.NoError(t, err)
+	require.Equal(t, suspended.Status, status.Status)
+	require.Equal(t, suspended.SuspendedUntil, status.SuspendedUntil)
+
+	// an update based on a stale status does nothing
+	_, err = testQueries.UpdateUserStatus(context.Background(), sqlc.UpdateUserStatusParams{
+		Status:        "locked",
+		StatusReason:  pgtype.Text{String: "leaked", Valid: true},
+		ID:            user.ID,
+		CurrentStatus: user.Status,
+	})
+	require.Error(t, err)
+
+	// reinstating clears the reason and the suspension end
+	active, err := testQueries.UpdateUserStatus(context.Background(), sqlc.UpdateUserStatusParams{
+		Status:        "active",
+		ID:            user.ID,
+		CurrentStatus: suspended.Status,
+	})
+	require.NoError(t, err)
+	require.Equal(t, "active", active.Status)
+	require.False(t, active.StatusReason.Valid)
+	require.False(t, active.SuspendedUntil.Valid)
+
+	// unknown statuses are refused by the table
+	_, err = testQueries.UpdateUserStatus(context.Background(), sqlc.UpdateUserStatusParams{
+		Status:        "banned",
+		ID:            user.ID,
+		CurrentStatus: active.Status,
+	})
+	require.Error(t, err)
+}
+
+func TestPurgeDeletedUsers(t *testing.T) {
+	user := createRandomUser(t)
+	active := createRandomUser(t)
+
+	_, err := testQueries.SoftDeleteUser(context.Background(), sqlc.SoftDeleteUserParams{
+		ID:        user.ID,
+		DeletedAt: pgtype.Timestamp{Time: time.Now().Add(-2 * time.Hour), Valid: true},
+	})
+	require.NoError(t, err)
+
+	purged, err := testQueries.PurgeDeletedUsers(context.Background(), pgtype.Timestamp{Time: time.Now().Add(-time.Hour), Valid: true})
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, purged, int64(1))
+
+	_, err = testQueries.GetDeletedUserByEmail(context.Background(), user.Email)
+	require.Error(t, err)
+	_, err = testQueries.GetUser(context.Background(), active.ID)
+	require.NoError(t, err)
+}
@@ -0,0 +1,125 @@
+package tests
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+	"github.com/suryansh74/auth-package/internal/db/sqlc"
+	"github.com/suryansh74/auth-package/internal/utils"
+)
+
+func createRandomEmailChangeRequest(t *testing.T, user sqlc.User) sqlc.EmailChangeRequest {
+	arg := sqlc.CreateEmailChangeRequestParams{
+		UserID:           user.ID,
+		OldEmail:         user.Email,
+		NewEmail:         utils.RandomEmail(),
+		ConfirmTokenHash: utils.HashToken(utils.RandomString(32)),
+		CancelTokenHash:  utils.HashToken(utils.RandomString(32)),
+		ExpiresAt:        pgtype.Timestamp{Time: time.Now().Add(time.Hour), Valid: true},
+	}
+	request, err := testQueries.CreateEmailChangeRequest(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotZero(t, request.ID)
+	require.Equal(t, arg.NewEmail, request.NewEmail)
+	require.False(t, request.ConfirmedAt.Valid)
+	require.False(t, request.CancelledAt.Valid)
+
+	return request
+}
+
+func TestConfirmEmailChangeRequest(t *testing.T) {
+	user := createRandomUser(t)
+	request := createRandomEmailChangeRequest(t, user)
+
+	found, err := testQueries.GetEmailChangeRequestByConfirmTokenHash(context.Background(), request.ConfirmTokenHash)
+	require.NoError(t, err)
+	require.Equal(t, request.ID, found.ID)
+
+	confirmed, err := testQueries.ConfirmEmailChangeRequest(context.Background(), request.ID)
+	require.NoError(t, err)
+	require.True(t, confirmed.ConfirmedAt.Valid)
+
+	// a change is confirmed once
+	_, err = testQueries.ConfirmEmailChangeRequest(context.Background(), request.ID)
+	require.Error(t, err)
+
+	updatedUser, err := testQueries.UpdateUserEmail(context.Background(), sqlc.UpdateUserEmailParams{
+		NewEmail: request.NewEmail,
+		ID:       user.ID,
+		OldEmail: request.OldEmail,
+	})
+	require.NoError(t, err)
+	require.Equal(t, request.NewEmail, updatedUser.Email)
+	require.True(t, updatedUser.EmailVerifiedAt.Valid)
+
+	// the user no longer has the old email
+	_, err = testQueries.UpdateUserEmail(context.Background(), sqlc.UpdateUserEmailParams{
+		NewEmail: utils.RandomEmail(),
+		ID:       user.ID,
+		OldEmail: request.OldEmail,
+	})
+	require.Error(t, err)
+}
+
+func TestCancelUserEmailChangeRequests(t *testing.T) {
+	user := createRandomUser(t)
+	request := createRandomEmailChangeRequest(t, user)
+
+	err := testQueries.CancelUserEmailChangeRequests(context.Background(), user.ID)
+	require.NoError(t, err)
+
+	found, err := testQueries.GetEmailChangeRequestByCancelTokenHash(context.Background(), request.CancelTokenHash)
+	require.NoError(t, err)
+	require.True(t, found.CancelledAt.Valid)
+
+	// cancelled requests can neither be confirmed nor cancelled again
+	_, err = testQueries.ConfirmEmailChangeRequest(context.Background(), request.ID)
+	require.Error(t, err)
+	_, err = testQueries.CancelEmailChangeRequest(context.Background(), request.ID)
+	require.Error(t, err)
+}
+
+func TestCancelOtherUserEmailChangeRequests(t *testing.T) {
+	user := createRandomUser(t)
+	kept := createRandomEmailChangeRequest(t, user)
+	other := createRandomEmailChangeRequest(t, user)
+	_, err := testQueries.ConfirmEmailChangeRequest(context.Background(), other.ID)
+	require.NoError(t, err)
+
+	err = testQueries.CancelOtherUserEmailChangeRequests(context.Background(), sqlc.CancelOtherUserEmailChangeRequestsParams{
+		UserID: user.ID,
+		ID:     kept.ID,
+	})
+	require.NoError(t, err)
+
+	// confirmed requests are cancelled as well
+	found, err := testQueries.GetEmailChangeRequestByCancelTokenHash(context.Background(), other.CancelTokenHash)
+	require.NoError(t, err)
+	require.True(t, found.CancelledAt.Valid)
+	found, err = testQueries.GetEmailChangeRequestByCancelTokenHash(context.Background(), kept.CancelTokenHash)
+	require.NoError(t, err)
+	require.False(t, found.CancelledAt.Valid)
+}
+
+func TestRestoreUserEmail(t *testing.T) {
+	user := createRandomUser(t)
+	changed, err := testQueries.UpdateUserEmail(context.Background(), sqlc.UpdateUserEmailParams{
+		NewEmail: utils.RandomEmail(),
+		ID:       user.ID,
+		OldEmail: user.Email,
+	})
+	require.NoError(t, err)
+
+	// the email is restored whatever the current one is
+	restored, err := testQueries.RestoreUserEmail(context.Background(), sqlc.RestoreUserEmailParams{
+		Email: user.Email,
+		ID:    user.ID,
+	})
+	require.NoError(t, err)
+	require.NotEqual(t, changed.Email, restored.Email)
+	require.Equal(t, user.Email, restored.Email)
+	require.True(t, restored.EmailVerifiedAt.Valid)
+}
@@ -0,0 +1,62 @@
+package tests
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/require"
+	"github.com/suryansh74/auth-package/internal/utils"
+)
+
+// applyMigrations runs the up migrations numbered from first to last, in
+// order, within tx
+func applyMigrations(t *testing.T, tx pgx.Tx, first, last int) {
+	files, err := filepath.Glob(filepath.Join("..", "migration", "*.up.sql"))
+	require.NoError(t, err)
+	sort.Strings(files)
+	for i, file := range files {
+		if version := i + 1; version < first || version > last {
+			continue
+		}
+		migration, err := os.ReadFile(file)
+		require.NoError(t, err)
+		_, err = tx.Exec(context.Background(), string(migration))
+		require.NoError(t, err, file)
+	}
+}
+
+func TestMigrationVerifiesExistingUsers(t *testing.T) {
+	ctx := context.Background()
+	tx, err := testDB.Begin(ctx)
+	require.NoError(t, err)
+	defer tx.Rollback(ctx)
+
+	// the migrations run in a schema of their own, dropped with the
+	// transaction
+	schema := "migration_" + strings.ToLower(utils.RandomString(8))
+	_, err = tx.Exec(ctx, "CREATE SCHEMA "+schema+"; SET LOCAL search_path TO "+schema+", public")
+	require.NoError(t, err)
+
+	// a user registered before email verification existed
+	applyMigrations(t, tx, 1, 8)
+	createdAt := time.Now().Add(-time.Hour).Truncate(time.Second)
+	email := utils.RandomEmail()
+	_, err = tx.Exec(ctx, "INSERT INTO users (name, email, password, created_at) VALUES ($1, $2, $3, $4)",
+		utils.RandomString(6), email, utils.RandomString(20), createdAt)
+	require.NoError(t, err)
+
+	applyMigrations(t, tx, 9, 11)
+	var verifiedAt *time.Time
+	var status string
+	err = tx.QueryRow(ctx, "SELECT email_verified_at, status FROM users WHERE email = $1", email).Scan(&verifiedAt, &status)
+	require.NoError(t, err)
+	require.NotNil(t, verifiedAt)
+	require.True(t, createdAt.Equal(*verifiedAt))
+	require.Equal(t, "active", status)
+}
@@ -0,0 +1,115 @@
+package tests
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+	"github.com/suryansh74/auth-package/internal/db/sqlc"
+	"github.com/suryansh74/auth-package/internal/utils"
+)
+
+func createRandomOrganization(t *testing.T, owner sqlc.User) sqlc.Organization {
+	arg := sqlc.CreateOrganizationParams{
+		Name: utils.RandomString(10),
+		Slug: utils.RandomString(12),
+	}
+	org, err := testQueries.CreateOrganization(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotZero(t, org.ID)
+	require.Equal(t, arg.Name, org.Name)
+	require.Equal(t, arg.Slug, org.Slug)
+
+	member, err := testQueries.AddOrganizationMember(context.Background(), sqlc.AddOrganizationMemberParams{
+		OrganizationID: org.ID,
+		UserID:         owner.ID,
+		Role:           "owner",
+	})
+	require.NoError(t, err)
+	require.Equal(t, "owner", member.Role)
+
+	return org
+}
+
+func TestListUserOrganizations(t *testing.T) {
+	user := createRandomUser(t)
+	org := createRandomOrganization(t, user)
+
+	orgs, err := testQueries.ListUserOrganizations(context.Background(), user.ID)
+	require.NoError(t, err)
+	require.Len(t, orgs, 1)
+	require.Equal(t, org.ID, orgs[0].ID)
+	require.Equal(t, "owner", orgs[0].Role)
+
+	owners, err := testQueries.CountOrganizationOwners(context.Background(), org.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), owners)
+
+	locked, err := testQueries.LockOrganization(context.Background(), org.ID)
+	require.NoError(t, err)
+	require.Equal(t, org.ID, locked)
+
+	owned, err := testQueries.LockOwnedOrganizations(context.Background(), user.ID)
+	require.NoError(t, err)
+	require.Equal(t, []pgtype.UUID{org.ID}, owned)
+
+	// deleted users no longer count as owners
+	_, err = testQueries.SoftDeleteUser(context.Background(), sqlc.SoftDeleteUserParams{
+		ID:        user.ID,
+		DeletedAt: pgtype.Timestamp{Time: time.Now(), Valid: true},
+	})
+	require.NoError(t, err)
+	owners, err = testQueries.CountOrganizationOwners(context.Background(), org.ID)
+	require.NoError(t, err)
+	require.Zero(t, owners)
+}
+
+func TestAcceptOrganizationInvitation(t *testing.T) {
+	ctx := context.Background()
+	owner := createRandomUser(t)
+	org := createRandomOrganization(t, owner)
+
+	invitation, err := testQueries.CreateOrganizationInvitation(ctx, sqlc.CreateOrganizationInvitationParams{
+		OrganizationID: org.ID,
+		Email:          utils.RandomEmail(),
+		Role:           "member",
+		TokenHash:      utils.HashToken(utils.RandomString(32)),
+		InvitedBy:      owner.ID,
+		ExpiresAt:      pgtype.Timestamp{Time: time.Now().Add(time.Hour), Valid: true},
+	})
+	require.NoError(t, err)
+	require.False(t, invitation.AcceptedAt.Valid)
+
+	found, err := testQueries.GetOrganizationInvitationByTokenHash(ctx, invitation.TokenHash)
+	require.NoError(t, err)
+	require.Equal(t, invitation.ID, found.ID)
+
+	accepted, err := testQueries.AcceptOrganizationInvitation(ctx, invitation.ID)
+	require.NoError(t, err)
+	require.True(t, accepted.AcceptedAt.Valid)
+
+	// an invitation can only be accepted once
+	_, err = testQueries.AcceptOrganizationInvitation(ctx, invitation.ID)
+	require.Error(t, err)
+}
+
+func TestSetSessionOrganization(t *testing.T) {
+	ctx := context.Background()
+	user := createRandomUser(t)
+	org := createRandomOrganization(t, user)
+	session := createRandomSession(t, user, pgtype.UUID{Bytes: uuid.New(), Valid: true})
+	require.False(t, session.OrganizationID.Valid)
+
+	err := testQueries.SetSessionOrganization(ctx, sqlc.SetSessionOrganizationParams{
+		FamilyID:       session.FamilyID,
+		OrganizationID: org.ID,
+	})
+	require.NoError(t, err)
+
+	updated, err := testQueries.GetSessionByRefreshTokenHash(ctx, session.RefreshTokenHash)
+	require.NoError(t, err)
+	require.Equal(t, org.ID, updated.OrganizationID)
+}
@@ -0,0 +1,60 @@
+package tests
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+	"github.com/suryansh74/auth-package/internal/db/sqlc"
+	"github.com/suryansh74/auth-package/internal/utils"
+)
+
+func createRandomPasswordResetToken(t *testing.T, user sqlc.User) sqlc.PasswordResetToken {
+	arg := sqlc.CreatePasswordResetTokenParams{
+		UserID:    user.ID,
+		TokenHash: utils.HashToken(utils.RandomString(32)),
+		ExpiresAt: pgtype.Timestamp{Time: time.Now().Add(time.Hour), Valid: true},
+	}
+	reset, err := testQueries.CreatePasswordResetToken(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotZero(t, reset.ID)
+	require.Equal(t, arg.UserID, reset.UserID)
+	require.Equal(t, arg.TokenHash, reset.TokenHash)
+	require.False(t, reset.UsedAt.Valid)
+
+	return reset
+}
+
+func TestUsePasswordResetToken(t *testing.T) {
+	user := createRandomUser(t)
+	reset := createRandomPasswordResetToken(t, user)
+
+	found, err := testQueries.GetPasswordResetTokenByHash(context.Background(), reset.TokenHash)
+	require.NoError(t, err)
+	require.Equal(t, reset.ID, found.ID)
+
+	used, err := testQueries.UsePasswordResetToken(context.Background(), reset.ID)
+	require.NoError(t, err)
+	require.True(t, used.UsedAt.Valid)
+
+	// a token can only be used once
+	_, err = testQueries.UsePasswordResetToken(context.Background(), reset.ID)
+	require.Error(t, err)
+}
+
+func TestInvalidateUserPasswordResetTokens(t *testing.T) {
+	user := createRandomUser(t)
+	first := createRandomPasswordResetToken(t, user)
+	second := createRandomPasswordResetToken(t, user)
+
+	err := testQueries.InvalidateUserPasswordResetTokens(context.Background(), user.ID)
+	require.NoError(t, err)
+
+	for _, reset := range []sqlc.PasswordResetToken{first, second} {
+		found, err := testQueries.GetPasswordResetTokenByHash(context.Background(), reset.TokenHash)
+		require.NoError(t, err)
+		require.True(t, found.UsedAt.Valid)
+	}
+}
@@ -0,0 +1,120 @@
+package tests
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+	"github.com/suryansh74/auth-package/internal/db/sqlc"
+	"github.com/suryansh74/auth-package/internal/utils"
+)
+
+func createRandomRole(t *testing.T) sqlc.Role {
+	arg := sqlc.CreateRoleParams{
+		Name:        "role_" + utils.RandomString(8),
+		Description: utils.RandomString(20),
+	}
+	role, err := testQueries.CreateRole(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotZero(t, role.ID)
+	require.Equal(t, arg.Name, role.Name)
+	require.Equal(t, arg.Description, role.Description)
+	require.NotZero(t, role.CreatedAt)
+
+	return role
+}
+
+func createRandomPermission(t *testing.T) sqlc.Permission {
+	arg := sqlc.CreatePermissionParams{
+		Name:        "permission:" + utils.RandomString(8),
+		Description: utils.RandomString(20),
+	}
+	permission, err := testQueries.CreatePermission(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotZero(t, permission.ID)
+	require.Equal(t, arg.Name, permission.Name)
+	require.Equal(t, arg.Description, permission.Description)
+
+	return permission
+}
+
+func TestCreateRole(t *testing.T) {
+	role := createRandomRole(t)
+
+	found, err := testQueries.GetRoleByName(context.Background(), role.Name)
+	require.NoError(t, err)
+	require.Equal(t, role, found)
+
+	// role names are unique
+	_, err = testQueries.CreateRole(context.Background(), sqlc.CreateRoleParams{Name: role.Name})
+	require.Error(t, err)
+}
+
+func TestUserRolesAndPermissions(t *testing.T) {
+	ctx := context.Background()
+	user := createRandomUser(t)
+	role := createRandomRole(t)
+	permission := createRandomPermission(t)
+
+	grant := sqlc.GrantRolePermissionParams{RoleID: role.ID, PermissionID: permission.ID}
+	require.NoError(t, testQueries.GrantRolePermission(ctx, grant))
+	// granting twice is a no-op
+	require.NoError(t, testQueries.GrantRolePermission(ctx, grant))
+
+	assign := sqlc.AssignUserRoleParams{UserID: user.ID, RoleID: role.ID}
+	require.NoError(t, testQueries.AssignUserRole(ctx, assign))
+	require.NoError(t, testQueries.AssignUserRole(ctx, assign))
+
+	roles, err := testQueries.ListUserRoles(ctx, user.ID)
+	require.NoError(t, err)
+	require.Equal(t, []string{role.Name}, roles)
+
+	permissions, err := testQueries.ListUserPermissions(ctx, user.ID)
+	require.NoError(t, err)
+	require.Equal(t, []string{permission.Name}, permissions)
+
+	count, err := testQueries.CountRoleUsers(ctx, role.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), count)
+
+	// deleted users have no roles left
+	deleted := createRandomUser(t)
+	require.NoError(t, testQueries.AssignUserRole(ctx, sqlc.AssignUserRoleParams{UserID: deleted.ID, RoleID: role.ID}))
+	_, err = testQueries.SoftDeleteUser(ctx, sqlc.SoftDeleteUserParams{
+		ID:        deleted.ID,
+		DeletedAt: pgtype.Timestamp{Time: time.Now(), Valid: true},
+	})
+	require.NoError(t, err)
+	roles, err = testQueries.ListUserRoles(ctx, deleted.ID)
+	require.NoError(t, err)
+	require.Empty(t, roles)
+	permissions, err = testQueries.ListUserPermissions(ctx, deleted.ID)
+	require.NoError(t, err)
+	require.Empty(t, permissions)
+
+	// deleting the role removes its grants and assignments
+	require.NoError(t, testQueries.DeleteRole(ctx, role.ID))
+
+	roles, err = testQueries.ListUserRoles(ctx, user.ID)
+	require.NoError(t, err)
+	require.Empty(t, roles)
+
+	permissions, err = testQueries.ListUserPermissions(ctx, user.ID)
+	require.NoError(t, err)
+	require.Empty(t, permissions)
+}
+
+func TestRevokeUserRole(t *testing.T) {
+	ctx := context.Background()
+	user := createRandomUser(t)
+	role := createRandomRole(t)
+
+	require.NoError(t, testQueries.AssignUserRole(ctx, sqlc.AssignUserRoleParams{UserID: user.ID, RoleID: role.ID}))
+	require.NoError(t, testQueries.RevokeUserRole(ctx, sqlc.RevokeUserRoleParams{UserID: user.ID, RoleID: role.ID}))
+
+	roles, err := testQueries.ListUserRoles(ctx, user.ID)
+	require.NoError(t, err)
+	require.Empty(t, roles)
+}
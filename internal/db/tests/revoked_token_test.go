@@ -0,0 +1,67 @@
+package tests
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+	"github.com/suryansh74/auth-package/internal/db/sqlc"
+)
+
+func TestRevokeToken(t *testing.T) {
+	tokenID := pgtype.UUID{Bytes: uuid.New(), Valid: true}
+
+	revoked, err := testQueries.IsTokenRevoked(context.Background(), tokenID)
+	require.NoError(t, err)
+	require.False(t, revoked)
+
+	arg := sqlc.RevokeTokenParams{
+		TokenID:   tokenID,
+		ExpiresAt: pgtype.Timestamp{Time: time.Now().Add(time.Minute), Valid: true},
+	}
+	require.NoError(t, testQueries.RevokeToken(context.Background(), arg))
+	// revoking twice is a no-op
+	require.NoError(t, testQueries.RevokeToken(context.Background(), arg))
+
+	revoked, err = testQueries.IsTokenRevoked(context.Background(), tokenID)
+	require.NoError(t, err)
+	require.True(t, revoked)
+}
+
+func TestDeleteExpiredRevokedTokens(t *testing.T) {
+	tokenID := pgtype.UUID{Bytes: uuid.New(), Valid: true}
+	err := testQueries.RevokeToken(context.Background(), sqlc.RevokeTokenParams{
+		TokenID:   tokenID,
+		ExpiresAt: pgtype.Timestamp{Time: time.Now().Add(-time.Minute), Valid: true},
+	})
+	require.NoError(t, err)
+
+	err = testQueries.DeleteExpiredRevokedTokens(context.Background(), pgtype.Timestamp{Time: time.Now(), Valid: true})
+	require.NoError(t, err)
+
+	revoked, err := testQueries.IsTokenRevoked(context.Background(), tokenID)
+	require.NoError(t, err)
+	require.False(t, revoked)
+}
+
+func TestGetUserTokenRevocation(t *testing.T) {
+	user := createRandomUser(t)
+
+	_, err := testQueries.GetUserTokenRevocation(context.Background(), user.ID)
+	require.Error(t, err)
+
+	issuedBefore := time.Now().Truncate(time.Second).Add(time.Second)
+	err = testQueries.RevokeUserTokens(context.Background(), sqlc.RevokeUserTokensParams{
+		UserID:       user.ID,
+		IssuedBefore: pgtype.Timestamp{Time: issuedBefore, Valid: true},
+		ExpiresAt:    pgtype.Timestamp{Time: issuedBefore.Add(time.Minute), Valid: true},
+	})
+	require.NoError(t, err)
+
+	revokedBefore, err := testQueries.GetUserTokenRevocation(context.Background(), user.ID)
+	require.NoError(t, err)
+	require.True(t, issuedBefore.Equal(revokedBefore.Time))
+}
@@ -0,0 +1,90 @@
+package tests
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+	"github.com/suryansh74/auth-package/internal/db/sqlc"
+	"github.com/suryansh74/auth-package/internal/utils"
+)
+
+func createRandomSession(t *testing.T, user sqlc.User, familyID pgtype.UUID) sqlc.Session {
+	arg := sqlc.CreateSessionParams{
+		UserID:           user.ID,
+		FamilyID:         familyID,
+		RefreshTokenHash: utils.HashToken(utils.RandomString(32)),
+		UserAgent:        utils.RandomString(10),
+		ClientIp:         "127.0.0.1",
+		ExpiresAt:        pgtype.Timestamp{Time: time.Now().Add(time.Hour), Valid: true},
+	}
+	session, err := testQueries.CreateSession(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotEmpty(t, session)
+
+	require.NotZero(t, session.ID)
+	require.Equal(t, arg.UserID, session.UserID)
+	require.Equal(t, arg.FamilyID, session.FamilyID)
+	require.Equal(t, arg.RefreshTokenHash, session.RefreshTokenHash)
+	require.False(t, session.UsedAt.Valid)
+	require.False(t, session.RevokedAt.Valid)
+
+	return session
+}
+
+func TestCreateSession(t *testing.T) {
+	user := createRandomUser(t)
+	createRandomSession(t, user, pgtype.UUID{Bytes: uuid.New(), Valid: true})
+}
+
+func TestMarkSessionUsed(t *testing.T) {
+	user := createRandomUser(t)
+	session := createRandomSession(t, user, pgtype.UUID{Bytes: uuid.New(), Valid: true})
+
+	usedSession, err := testQueries.MarkSessionUsed(context.Background(), session.ID)
+	require.NoError(t, err)
+	require.True(t, usedSession.UsedAt.Valid)
+
+	// a session can only be rotated once
+	_, err = testQueries.MarkSessionUsed(context.Background(), session.ID)
+	require.Error(t, err)
+}
+
+func TestRevokeSessionFamily(t *testing.T) {
+	user := createRandomUser(t)
+	familyID := pgtype.UUID{Bytes: uuid.New(), Valid: true}
+	first := createRandomSession(t, user, familyID)
+	second := createRandomSession(t, user, familyID)
+
+	err := testQueries.RevokeSessionFamily(context.Background(), familyID)
+	require.NoError(t, err)
+
+	for _, session := range []sqlc.Session{first, second} {
+		revoked, err := testQueries.GetSessionByRefreshTokenHash(context.Background(), session.RefreshTokenHash)
+		require.NoError(t, err)
+		require.True(t, revoked.RevokedAt.Valid)
+	}
+}
+
+func TestRevokeOtherUserSessions(t *testing.T) {
+	user := createRandomUser(t)
+	current := createRandomSession(t, user, pgtype.UUID{Bytes: uuid.New(), Valid: true})
+	other := createRandomSession(t, user, pgtype.UUID{Bytes: uuid.New(), Valid: true})
+
+	err := testQueries.RevokeOtherUserSessions(context.Background(), sqlc.RevokeOtherUserSessionsParams{
+		UserID:   user.ID,
+		FamilyID: current.FamilyID,
+	})
+	require.NoError(t, err)
+
+	kept, err := testQueries.GetSessionByRefreshTokenHash(context.Background(), current.RefreshTokenHash)
+	require.NoError(t, err)
+	require.False(t, kept.RevokedAt.Valid)
+
+	revoked, err := testQueries.GetSessionByRefreshTokenHash(context.Background(), other.RefreshTokenHash)
+	require.NoError(t, err)
+	require.True(t, revoked.RevokedAt.Valid)
+}
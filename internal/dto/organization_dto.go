@@ -0,0 +1,61 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type CreateOrganizationRequest struct {
+	Name string `json:"name"`
+	Slug string `json:"slug"`
+}
+
+type OrganizationResponse struct {
+	ID        pgtype.UUID `json:"id"`
+	Name      string      `json:"name"`
+	Slug      string      `json:"slug"`
+	Role      string      `json:"role"`
+	CreatedAt time.Time   `json:"created_at"`
+}
+
+type OrganizationMemberResponse struct {
+	UserID    pgtype.UUID `json:"user_id"`
+	Name      string      `json:"name,omitempty"`
+	Email     string      `json:"email,omitempty"`
+	Role      string      `json:"role"`
+	CreatedAt time.Time   `json:"created_at"`
+}
+
+type UpdateMemberRoleRequest struct {
+	Role string `json:"role"`
+}
+
+type InviteMemberRequest struct {
+	Email string `json:"email"`
+	Role  string `json:"role"`
+}
+
+type InvitationResponse struct {
+	ID             pgtype.UUID `json:"id"`
+	OrganizationID pgtype.UUID `json:"organization_id"`
+	Email          string      `json:"email"`
+	Role           string      `json:"role"`
+	Token          string      `json:"token"`
+	ExpiresAt      time.Time   `json:"expires_at"`
+}
+
+type AcceptInvitationRequest struct {
+	Token string `json:"token"`
+}
+
+type SwitchOrganizationRequest struct {
+	OrgID pgtype.UUID `json:"org_id"`
+}
+
+type SwitchOrganizationResponse struct {
+	UserID      pgtype.UUID `json:"user_id"`
+	OrgID       pgtype.UUID `json:"org_id"`
+	Role        string      `json:"role"`
+	AccessToken string      `json:"token,omitempty"`
+}
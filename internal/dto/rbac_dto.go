@@ -0,0 +1,45 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type CreateRoleRequest struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+type CreatePermissionRequest struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+type GrantPermissionRequest struct {
+	Permission string `json:"permission"`
+}
+
+type AssignRoleRequest struct {
+	Role string `json:"role"`
+}
+
+type RoleResponse struct {
+	ID          pgtype.UUID `json:"id"`
+	Name        string      `json:"name"`
+	Description string      `json:"description"`
+	CreatedAt   time.Time   `json:"created_at"`
+}
+
+type PermissionResponse struct {
+	ID          pgtype.UUID `json:"id"`
+	Name        string      `json:"name"`
+	Description string      `json:"description"`
+	CreatedAt   time.Time   `json:"created_at"`
+}
+
+type UserRolesResponse struct {
+	UserID      pgtype.UUID `json:"user_id"`
+	Roles       []string    `json:"roles"`
+	Permissions []string    `json:"permissions"`
+}
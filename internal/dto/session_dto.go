@@ -0,0 +1,25 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// SessionMetadata describes the client a refresh session was issued to
+type SessionMetadata struct {
+	UserAgent string
+	ClientIP  string
+}
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+type RefreshTokenResponse struct {
+	UserID                pgtype.UUID `json:"user_id"`
+	AccessToken           string      `json:"token,omitempty"`
+	RefreshToken          string      `json:"refresh_token,omitempty"`
+	CSRFToken             string      `json:"csrf_token,omitempty"`
+	RefreshTokenExpiresAt time.Time   `json:"refresh_token_expires_at"`
+}
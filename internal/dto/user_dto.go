@@ -18,24 +18,103 @@ type UserLoginRequest struct {
 }
 
 type UserRegisterResponse struct {
-	UserID      pgtype.UUID `json:"user_id"`
-	Name        string      `json:"name"`
-	Email       string      `json:"email"`
-	CreatedAt   time.Time   `json:"created_at"`
-	UpdatedAt   time.Time   `json:"updated_at"`
-	AccessToken string      `json:"token"`
+	UserID        pgtype.UUID `json:"user_id"`
+	Name          string      `json:"name"`
+	Email         string      `json:"email"`
+	EmailVerified bool        `json:"email_verified"`
+	CreatedAt     time.Time   `json:"created_at"`
+	UpdatedAt     time.Time   `json:"updated_at"`
+	AccessToken   string      `json:"token,omitempty"`
+	RefreshToken  string      `json:"refresh_token,omitempty"`
+	CSRFToken     string      `json:"csrf_token,omitempty"`
 }
 
 type UserLoginResponse struct {
-	UserID      pgtype.UUID `json:"user_id"`
-	Email       string      `json:"email"`
-	AccessToken string      `json:"token"`
+	UserID        pgtype.UUID `json:"user_id"`
+	Email         string      `json:"email"`
+	EmailVerified bool        `json:"email_verified"`
+	AccessToken   string      `json:"token,omitempty"`
+	RefreshToken  string      `json:"refresh_token,omitempty"`
+	CSRFToken     string      `json:"csrf_token,omitempty"`
 }
 
 type UserResponse struct {
-	UserID    pgtype.UUID `json:"user_id"`
-	Name      string      `json:"name"`
-	Email     string      `json:"email"`
-	CreatedAt time.Time   `json:"created_at"`
-	UpdatedAt time.Time   `json:"updated_at"`
+	UserID        pgtype.UUID `json:"user_id"`
+	Name          string      `json:"name"`
+	Email         string      `json:"email"`
+	EmailVerified bool        `json:"email_verified"`
+	Status        string      `json:"status"`
+	CreatedAt     time.Time   `json:"created_at"`
+	UpdatedAt     time.Time   `json:"updated_at"`
+}
+
+// UpdateProfileRequest changes the fields that are set and leaves the
+// others as they are. UpdatedAt is the updated_at last read by the client,
+// so edits made since then are not overwritten.
+type UpdateProfileRequest struct {
+	Name      *string   `json:"name"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password"`
+	NewPassword     string `json:"new_password"`
+	// KeepSession keeps the current session signed in, with a new access
+	// token, while every other session is signed out
+	KeepSession bool `json:"keep_session"`
+}
+
+type ChangePasswordResponse struct {
+	UserID            pgtype.UUID `json:"user_id"`
+	PasswordChangedAt time.Time   `json:"password_changed_at"`
+	AccessToken       string      `json:"token,omitempty"`
+}
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email"`
+}
+
+type ResetPasswordRequest struct {
+	Token       string `json:"token"`
+	NewPassword string `json:"new_password"`
+}
+
+// LinkTokenRequest is the body of endpoints behind email links, which
+// may carry the token in the query instead
+type LinkTokenRequest struct {
+	Token string `json:"token"`
+}
+
+type ResendVerificationRequest struct {
+	Email string `json:"email"`
+}
+
+type ChangeEmailRequest struct {
+	NewEmail        string `json:"new_email"`
+	CurrentPassword string `json:"current_password"`
+}
+
+type DeleteAccountRequest struct {
+	CurrentPassword string `json:"current_password"`
+}
+
+type RestoreAccountRequest struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+// UpdateUserStatusRequest moves an account to active, suspended or locked.
+// SuspendedUntil optionally ends a suspension by itself.
+type UpdateUserStatusRequest struct {
+	Status         string     `json:"status"`
+	Reason         string     `json:"reason"`
+	SuspendedUntil *time.Time `json:"suspended_until"`
+}
+
+type UserStatusResponse struct {
+	UserID          pgtype.UUID `json:"user_id"`
+	Status          string      `json:"status"`
+	Reason          string      `json:"reason,omitempty"`
+	SuspendedUntil  *time.Time  `json:"suspended_until,omitempty"`
+	StatusChangedAt time.Time   `json:"status_changed_at"`
 }
@@ -0,0 +1,175 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	customError "github.com/suryansh74/auth-package/internal/apperrors"
+	"github.com/suryansh74/auth-package/internal/db"
+	"github.com/suryansh74/auth-package/internal/dto"
+	"github.com/suryansh74/auth-package/internal/middleware"
+	"github.com/suryansh74/auth-package/internal/services"
+	"github.com/suryansh74/auth-package/mailer"
+	"github.com/suryansh74/auth-package/token"
+)
+
+// ErrRestoreThrottled is returned when an address or client tried to
+// restore an account too many times recently
+var ErrRestoreThrottled = errors.New("too many restore attempts, try again later")
+
+type AccountHandler interface {
+	DeleteAccount(ctx *fiber.Ctx) error
+	RestoreAccount(ctx *fiber.Ctx) error
+}
+
+// AccountDeletionConfig holds the settings of account deletion
+type AccountDeletionConfig struct {
+	Mailer mailer.Mailer
+	// GracePeriod is how long a deleted account can be restored before the
+	// purge removes it
+	GracePeriod time.Duration
+	// AccessTokenDuration is the lifetime of access tokens
+	AccessTokenDuration time.Duration
+	// Cookies is set when tokens are sent in cookies, which are cleared
+	Cookies *middleware.CookieConfig
+	// Queue sends the deletion notices
+	Queue *MailQueue
+	// RestoreLimitPerAddress and RestoreLimitPerIP are how many restores
+	// may be attempted for one email, or by one client, within
+	// RestoreLimitWindow. Limits of zero or less are not enforced.
+	RestoreLimitPerAddress int
+	RestoreLimitPerIP      int
+	RestoreLimitWindow     time.Duration
+}
+
+type accountHandler struct {
+	accounts          services.AccountService
+	sessions          services.SessionService
+	revocations       token.RevocationStore
+	restoresByAddress *throttle
+	restoresByIP      *throttle
+	config            AccountDeletionConfig
+}
+
+func NewAccountHandler(db db.Auth, revocations token.RevocationStore, config AccountDeletionConfig) AccountHandler {
+	return &accountHandler{
+		accounts:          services.NewAccountManager(db, config.GracePeriod),
+		sessions:          services.NewSessionManager(db, 0),
+		revocations:       revocations,
+		restoresByAddress: newThrottle(config.RestoreLimitPerAddress, config.RestoreLimitWindow),
+		restoresByIP:      newThrottle(config.RestoreLimitPerIP, config.RestoreLimitWindow),
+		config:            config,
+	}
+}
+
+// DeleteAccount deletes the account of the current user, who must send
+// their password. Every token and session is revoked at once, while the
+// data is kept until the grace period is over so the account can be
+// restored.
+func (ah *accountHandler) DeleteAccount(ctx *fiber.Ctx) error {
+	payload, err := middleware.GetAuthPayload(ctx)
+	if err != nil {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(&fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	var req dto.DeleteAccountRequest
+	err = ctx.BodyParser(&req)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(&fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	user, err := ah.accounts.DeleteAccount(ctx.Context(), payload.UserID, req.CurrentPassword)
+	if err != nil {
+		switch {
+		case errors.Is(err, customError.ErrIncorrectPassword):
+			return ctx.Status(fiber.StatusForbidden).JSON(&fiber.Map{
+				"error": err.Error(),
+			})
+		case errors.Is(err, customError.ErrLastOrganizationOwner):
+			return ctx.Status(fiber.StatusConflict).JSON(&fiber.Map{
+				"error": "transfer the ownership of your organizations before deleting your account",
+			})
+		case errors.Is(err, customError.ErrUserNotFound):
+			return ctx.Status(fiber.StatusNotFound).JSON(&fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		return ctx.Status(fiber.StatusInternalServerError).JSON(&fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	err = revokeUserTokens(ctx.Context(), ah.revocations, user.ID, user.DeletedAt.Time, ah.config.AccessTokenDuration)
+	if err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(&fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	err = ah.sessions.RevokeUserSessions(ctx.Context(), user.ID)
+	if err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(&fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	if ah.config.Cookies != nil {
+		ah.config.Cookies.Clear(ctx)
+	}
+
+	restorableUntil := user.DeletedAt.Time.Add(ah.config.GracePeriod)
+	msg := accountDeletedEmail(user.Email, user.Name, restorableUntil)
+	enqueueMail(ah.config.Queue, "account deletion", user.Email, ctx.IP(), func(bgCtx context.Context) {
+		if err := ah.config.Mailer.Send(bgCtx, msg); err != nil {
+			log.Printf("account deletion: cannot send email: %v", err)
+		}
+	})
+
+	return ctx.Status(fiber.StatusOK).JSON(&fiber.Map{
+		"message":          "account deleted",
+		"deleted_at":       user.DeletedAt.Time,
+		"restorable_until": restorableUntil,
+	})
+}
+
+// RestoreAccount undoes the deletion of an account with its email and
+// password while the grace period lasts. The user logs in afterwards as
+// usual. Attempts are throttled, as they check passwords much like a login.
+func (ah *accountHandler) RestoreAccount(ctx *fiber.Ctx) error {
+	var req dto.RestoreAccountRequest
+	err := ctx.BodyParser(&req)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(&fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	now := time.Now()
+	if !ah.restoresByIP.allow(ctx.IP(), now) || !ah.restoresByAddress.allow(strings.ToLower(req.Email), now) {
+		return ctx.Status(fiber.StatusTooManyRequests).JSON(&fiber.Map{
+			"error": ErrRestoreThrottled.Error(),
+		})
+	}
+
+	user, err := ah.accounts.RestoreAccount(ctx.Context(), req.Email, req.Password)
+	if err != nil {
+		switch {
+		case errors.Is(err, customError.ErrUserNotFound), errors.Is(err, customError.ErrIncorrectPassword):
+			return ctx.Status(fiber.StatusUnauthorized).JSON(&fiber.Map{
+				"error": "invalid email or password",
+			})
+		case errors.Is(err, customError.ErrAccountNotRestorable):
+			return ctx.Status(fiber.StatusGone).JSON(&fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		return ctx.Status(fiber.StatusInternalServerError).JSON(&fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	return ctx.Status(fiber.StatusOK).JSON(userResponse(user))
+}
@@ -0,0 +1,209 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	customError "github.com/suryansh74/auth-package/internal/apperrors"
+	"github.com/suryansh74/auth-package/internal/db"
+	"github.com/suryansh74/auth-package/internal/dto"
+	"github.com/suryansh74/auth-package/internal/middleware"
+	"github.com/suryansh74/auth-package/internal/services"
+	"github.com/suryansh74/auth-package/mailer"
+	"github.com/suryansh74/auth-package/token"
+)
+
+type EmailChangeHandler interface {
+	ChangeEmail(ctx *fiber.Ctx) error
+	ConfirmEmailChange(ctx *fiber.Ctx) error
+	CancelEmailChange(ctx *fiber.Ctx) error
+}
+
+// EmailChangeConfig holds the settings of the email change flow
+type EmailChangeConfig struct {
+	Mailer mailer.Mailer
+	// TokenDuration is how long the confirm and cancel links can be used
+	TokenDuration time.Duration
+	// ConfirmURL and CancelURL are the pages of the client the links point
+	// to; the token is added as the token query parameter
+	ConfirmURL string
+	CancelURL  string
+	// AccessTokenDuration is the lifetime of access tokens
+	AccessTokenDuration time.Duration
+	// Queue sends the confirm and cancel emails
+	Queue *MailQueue
+}
+
+type emailChangeHandler struct {
+	srv         services.AuthService
+	changes     services.EmailChangeService
+	sessions    services.SessionService
+	revocations token.RevocationStore
+	config      EmailChangeConfig
+}
+
+func NewEmailChangeHandler(db db.Auth, revocations token.RevocationStore, config EmailChangeConfig) EmailChangeHandler {
+	return &emailChangeHandler{
+		srv:         services.NewAuthenticator(db),
+		changes:     services.NewEmailChangeManager(db),
+		sessions:    services.NewSessionManager(db, 0),
+		revocations: revocations,
+		config:      config,
+	}
+}
+
+// ChangeEmail starts changing the email of the current user, who must send
+// their password. A confirmation link goes to the new address and a notice
+// with a cancel link to the current one; nothing changes until the new
+// address confirms.
+func (eh *emailChangeHandler) ChangeEmail(ctx *fiber.Ctx) error {
+	payload, err := middleware.GetAuthPayload(ctx)
+	if err != nil {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(&fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	var req dto.ChangeEmailRequest
+	err = ctx.BodyParser(&req)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(&fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	tokens, request, err := eh.changes.RequestEmailChange(ctx.Context(), payload.UserID, req.CurrentPassword, req.NewEmail, eh.config.TokenDuration)
+	if err != nil {
+		switch {
+		case errors.Is(err, customError.ErrIncorrectPassword):
+			return ctx.Status(fiber.StatusForbidden).JSON(&fiber.Map{
+				"error": err.Error(),
+			})
+		case errors.Is(err, customError.ErrInvalidEmail), errors.Is(err, customError.ErrEmailNotChanged):
+			return ctx.Status(fiber.StatusBadRequest).JSON(&fiber.Map{
+				"error": err.Error(),
+			})
+		case errors.Is(err, customError.ErrUserAlreadyExist):
+			return ctx.Status(fiber.StatusConflict).JSON(&fiber.Map{
+				"error": err.Error(),
+			})
+		case errors.Is(err, customError.ErrUserNotFound):
+			return ctx.Status(fiber.StatusNotFound).JSON(&fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		return ctx.Status(fiber.StatusInternalServerError).JSON(&fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	user, err := eh.srv.GetUserByID(ctx.Context(), payload.UserID)
+	if err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(&fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	messages := []mailer.Message{
+		emailChangeConfirmEmail(request.NewEmail, user.Name, linkWithToken(eh.config.ConfirmURL, tokens.Confirm)),
+		emailChangeNoticeEmail(request.OldEmail, user.Name, request.NewEmail, linkWithToken(eh.config.CancelURL, tokens.Cancel)),
+	}
+	enqueueMail(eh.config.Queue, "email change", request.NewEmail, ctx.IP(), func(bgCtx context.Context) {
+		for _, msg := range messages {
+			if err := eh.config.Mailer.Send(bgCtx, msg); err != nil {
+				log.Printf("email change: cannot send email: %v", err)
+			}
+		}
+	})
+
+	return ctx.Status(fiber.StatusAccepted).JSON(&fiber.Map{
+		"message":    "a confirmation link has been sent to the new email",
+		"new_email":  request.NewEmail,
+		"expires_at": request.ExpiresAt.Time,
+	})
+}
+
+// ConfirmEmailChange applies an email change with the token of the link
+// sent to the new address. Access tokens carrying the old email are
+// revoked; sessions stay signed in and get the new email when refreshed.
+func (eh *emailChangeHandler) ConfirmEmailChange(ctx *fiber.Ctx) error {
+	confirmToken, err := linkToken(ctx)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(&fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	user, err := eh.changes.ConfirmEmailChange(ctx.Context(), confirmToken)
+	if err != nil {
+		return eh.changeError(ctx, err)
+	}
+	if err := revokeUserTokens(ctx.Context(), eh.revocations, user.ID, time.Now(), eh.config.AccessTokenDuration); err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(&fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	return ctx.Status(fiber.StatusOK).JSON(&fiber.Map{
+		"user_id": user.ID,
+		"email":   user.Email,
+		"message": "email changed, refresh your tokens",
+	})
+}
+
+// CancelEmailChange drops an email change with the token of the notice
+// sent to the old address. A change that was already confirmed is undone,
+// and every token and session of the user is revoked since whoever
+// confirmed it may control the account.
+func (eh *emailChangeHandler) CancelEmailChange(ctx *fiber.Ctx) error {
+	cancelToken, err := linkToken(ctx)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(&fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	user, reverted, err := eh.changes.CancelEmailChange(ctx.Context(), cancelToken)
+	if err != nil {
+		return eh.changeError(ctx, err)
+	}
+	if !reverted {
+		return ctx.Status(fiber.StatusOK).JSON(&fiber.Map{
+			"user_id": user.ID,
+			"email":   user.Email,
+			"message": "email change cancelled",
+		})
+	}
+
+	if err := revokeUserTokens(ctx.Context(), eh.revocations, user.ID, time.Now(), eh.config.AccessTokenDuration); err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(&fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	if err := eh.sessions.RevokeUserSessions(ctx.Context(), user.ID); err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(&fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	return ctx.Status(fiber.StatusOK).JSON(&fiber.Map{
+		"user_id": user.ID,
+		"email":   user.Email,
+		"message": "email restored and every session signed out, consider resetting your password",
+	})
+}
+
+func (eh *emailChangeHandler) changeError(ctx *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, customError.ErrInvalidEmailChange):
+		return ctx.Status(fiber.StatusBadRequest).JSON(&fiber.Map{
+			"error": err.Error(),
+		})
+	case errors.Is(err, customError.ErrUserAlreadyExist):
+		return ctx.Status(fiber.StatusConflict).JSON(&fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	return ctx.Status(fiber.StatusInternalServerError).JSON(&fiber.Map{
+		"error": err.Error(),
+	})
+}
@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/suryansh74/auth-package/internal/dto"
+	"github.com/suryansh74/auth-package/mailer"
+)
+
+// linkWithToken appends token to baseURL as the token query parameter. With
+// no base URL the token itself is returned, to be pasted into the client.
+func linkWithToken(baseURL, token string) string {
+	if baseURL == "" {
+		return token
+	}
+	link, err := url.Parse(baseURL)
+	if err != nil {
+		return token
+	}
+	query := link.Query()
+	query.Set("token", token)
+	link.RawQuery = query.Encode()
+	return link.String()
+}
+
+// linkToken reads the token of an email link from the token query
+// parameter, where links put it, or from the request body
+func linkToken(ctx *fiber.Ctx) (string, error) {
+	if token := ctx.Query("token"); token != "" {
+		return token, nil
+	}
+	if len(ctx.Body()) > 0 {
+		var req dto.LinkTokenRequest
+		if err := ctx.BodyParser(&req); err != nil {
+			return "", err
+		}
+		if req.Token != "" {
+			return req.Token, nil
+		}
+	}
+	return "", errors.New("token is required")
+}
+
+func passwordResetEmail(to, name, link string) mailer.Message {
+	return mailer.Message{
+		To:      to,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf("Hi %s,\n\n"+
+			"Someone asked to reset the password of your account. If it was you, use this link to choose a new one:\n\n"+
+			"%s\n\n"+
+			"If you did not ask for it, you can ignore this email; your password stays the same.\n", name, link),
+	}
+}
+
+func passwordChangedEmail(to, name string) mailer.Message {
+	return mailer.Message{
+		To:      to,
+		Subject: "Your password was changed",
+		Body: fmt.Sprintf("Hi %s,\n\n"+
+			"The password of your account was just reset and every device was signed out.\n\n"+
+			"If you did not do this, reset your password again right away.\n", name),
+	}
+}
+
+func verificationEmail(to, name, link string) mailer.Message {
+	return mailer.Message{
+		To:      to,
+		Subject: "Verify your email",
+		Body: fmt.Sprintf("Hi %s,\n\n"+
+			"Please confirm that this is your email address by opening this link:\n\n"+
+			"%s\n\n"+
+			"If you did not create an account, you can ignore this email.\n", name, link),
+	}
+}
+
+func emailChangeConfirmEmail(to, name, link string) mailer.Message {
+	return mailer.Message{
+		To:      to,
+		Subject: "Confirm your new email",
+		Body: fmt.Sprintf("Hi %s,\n\n"+
+			"You asked to use this address for your account. Open this link to confirm the change:\n\n"+
+			"%s\n\n"+
+			"Until you do, you keep logging in with your current address.\n", name, link),
+	}
+}
+
+func emailChangeNoticeEmail(to, name, newEmail, link string) mailer.Message {
+	return mailer.Message{
+		To:      to,
+		Subject: "Your email is being changed",
+		Body: fmt.Sprintf("Hi %s,\n\n"+
+			"Someone asked to change the email of your account to %s.\n\n"+
+			"If it was not you, open this link to cancel the change, even if it was already confirmed:\n\n"+
+			"%s\n", name, newEmail, link),
+	}
+}
+
+func accountDeletedEmail(to, name string, restorableUntil time.Time) mailer.Message {
+	return mailer.Message{
+		To:      to,
+		Subject: "Your account was deleted",
+		Body: fmt.Sprintf("Hi %s,\n\n"+
+			"Your account was deleted and every device was signed out.\n\n"+
+			"You can restore it with your email and password until %s. After that it is removed for good.\n",
+			name, restorableUntil.UTC().Format("January 2, 2006 15:04 MST")),
+	}
+}
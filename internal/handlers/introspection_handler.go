@@ -0,0 +1,158 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"encoding/base64"
+	"net/url"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/suryansh74/auth-package/internal/dto"
+	"github.com/suryansh74/auth-package/internal/middleware"
+	"github.com/suryansh74/auth-package/token"
+)
+
+type IntrospectionHandler interface {
+	Introspect(ctx *fiber.Ctx) error
+}
+
+type introspectionHandler struct {
+	verifier      token.Verifier
+	revocations   token.RevocationStore
+	statuses      middleware.AccountStatusStore
+	clients       map[string]string
+	verifyOptions []token.VerifyOption
+}
+
+// NewIntrospectionHandler creates the handler of the introspection endpoint.
+// clients maps the IDs of the services allowed to introspect tokens to their
+// secrets. revocations and statuses are optional.
+func NewIntrospectionHandler(verifier token.Verifier, revocations token.RevocationStore, statuses middleware.AccountStatusStore, clients map[string]string, verifyOptions ...token.VerifyOption) IntrospectionHandler {
+	return &introspectionHandler{
+		verifier:      verifier,
+		revocations:   revocations,
+		statuses:      statuses,
+		clients:       clients,
+		verifyOptions: verifyOptions,
+	}
+}
+
+// Introspect tells an authenticated client whether a token is active
+// (RFC 7662). A token is active when it verifies, has not been revoked and
+// its user may still use the account, as checked by the auth middleware.
+// Any other token is reported as {"active": false} without a reason.
+func (ih *introspectionHandler) Introspect(ctx *fiber.Ctx) error {
+	var req dto.IntrospectionRequest
+	err := ctx.BodyParser(&req)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(&fiber.Map{
+			"error": "invalid_request",
+		})
+	}
+
+	if !ih.authenticateClient(ctx, req) {
+		ctx.Set(fiber.HeaderWWWAuthenticate, `Basic realm="introspect"`)
+		return ctx.Status(fiber.StatusUnauthorized).JSON(&fiber.Map{
+			"error": "invalid_client",
+		})
+	}
+	if req.Token == "" {
+		return ctx.Status(fiber.StatusBadRequest).JSON(&fiber.Map{
+			"error": "invalid_request",
+		})
+	}
+
+	// responses describe a token at a given moment and must not be cached
+	ctx.Set(fiber.HeaderCacheControl, "no-store")
+
+	opts := append(ih.verifyOptions[:len(ih.verifyOptions):len(ih.verifyOptions)], token.VerifyWithContext(ctx.Context()))
+	payload, err := ih.verifier.VerifyToken(req.Token, opts...)
+	if err != nil {
+		return ctx.Status(fiber.StatusOK).JSON(&dto.IntrospectionResponse{Active: false})
+	}
+	if ih.revocations != nil {
+		revoked, err := ih.revocations.IsRevoked(ctx.Context(), payload)
+		if err != nil {
+			return ctx.Status(fiber.StatusInternalServerError).JSON(&fiber.Map{
+				"error": "unable to verify token",
+			})
+		}
+		if revoked {
+			return ctx.Status(fiber.StatusOK).JSON(&dto.IntrospectionResponse{Active: false})
+		}
+	}
+	// suspensions do not revoke tokens, and the revocations of deleted
+	// users expire
+	if ih.statuses != nil {
+		if err := ih.statuses.CheckAccountStatus(ctx.Context(), payload.UserID); err != nil {
+			return ctx.Status(fiber.StatusOK).JSON(&dto.IntrospectionResponse{Active: false})
+		}
+	}
+
+	res := dto.IntrospectionResponse{
+		Active:    true,
+		Scope:     strings.Join(payload.Scopes, " "),
+		Username:  payload.Email,
+		TokenType: "access_token",
+		ExpiresAt: payload.ExpiredAt.Unix(),
+		IssuedAt:  payload.IssuedAt.Unix(),
+		Subject:   uuid.UUID(payload.UserID.Bytes).String(),
+		Audience:  payload.Audience,
+		Issuer:    payload.Issuer,
+		TokenID:   payload.ID.String(),
+		Roles:     payload.Roles,
+	}
+	if !payload.NotBefore.IsZero() {
+		res.NotBefore = payload.NotBefore.Unix()
+	}
+	if payload.SessionID != uuid.Nil {
+		res.SessionID = payload.SessionID.String()
+	}
+	if payload.OrgID != uuid.Nil {
+		res.OrgID = payload.OrgID.String()
+	}
+	return ctx.Status(fiber.StatusOK).JSON(&res)
+}
+
+// authenticateClient checks the client credentials sent with HTTP Basic
+// authentication or, failing that, in the request body
+func (ih *introspectionHandler) authenticateClient(ctx *fiber.Ctx, req dto.IntrospectionRequest) bool {
+	clientID, clientSecret, ok := basicAuth(ctx.Get(fiber.HeaderAuthorization))
+	if !ok {
+		clientID, clientSecret = req.ClientID, req.ClientSecret
+	}
+	if clientID == "" || clientSecret == "" {
+		return false
+	}
+
+	expected, exists := ih.clients[clientID]
+	return exists && subtle.ConstantTimeCompare([]byte(clientSecret), []byte(expected)) == 1
+}
+
+// basicAuth parses an HTTP Basic Authorization header. Client IDs and
+// secrets are form-urlencoded before being joined (RFC 6749 section 2.3.1).
+func basicAuth(header string) (string, string, bool) {
+	scheme, credentials, found := strings.Cut(header, " ")
+	if !found || !strings.EqualFold(scheme, "Basic") {
+		return "", "", false
+	}
+	decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(credentials))
+	if err != nil {
+		return "", "", false
+	}
+	id, secret, found := strings.Cut(string(decoded), ":")
+	if !found {
+		return "", "", false
+	}
+
+	id, err = url.QueryUnescape(id)
+	if err != nil {
+		return "", "", false
+	}
+	secret, err = url.QueryUnescape(secret)
+	if err != nil {
+		return "", "", false
+	}
+	return id, secret, true
+}
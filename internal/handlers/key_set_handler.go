@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/suryansh74/auth-package/token"
+)
+
+type KeySetHandler interface {
+	JWKS(ctx *fiber.Ctx) error
+}
+
+type keySetHandler struct {
+	tokenMaker token.Maker
+	maxAge     time.Duration
+}
+
+func NewKeySetHandler(tokenMaker token.Maker, maxAge time.Duration) KeySetHandler {
+	return &keySetHandler{
+		tokenMaker: tokenMaker,
+		maxAge:     maxAge,
+	}
+}
+
+// JWKS publishes the public keys of the token maker. Makers using a
+// symmetric key have nothing to publish and serve an empty set.
+func (kh *keySetHandler) JWKS(ctx *fiber.Ctx) error {
+	set := token.JSONWebKeySet{Keys: []token.JSONWebKey{}}
+	if publisher, ok := kh.tokenMaker.(token.KeySetPublisher); ok {
+		set = publisher.PublicKeySet()
+	}
+
+	ctx.Set(fiber.HeaderCacheControl, fmt.Sprintf("public, max-age=%d", int(kh.maxAge.Seconds())))
+	return ctx.Status(fiber.StatusOK).JSON(set)
+}
@@ -0,0 +1,156 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// mailTimeout bounds the delivery of an email sent in the background
+const mailTimeout = 30 * time.Second
+
+var (
+	// ErrMailThrottled is returned by MailQueue.Enqueue when the address or
+	// client was sent too many emails recently
+	ErrMailThrottled = errors.New("too many emails requested, try again later")
+	// ErrMailQueueFull is returned by MailQueue.Enqueue when the pending
+	// emails already fill the queue
+	ErrMailQueueFull = errors.New("too many emails pending, try again later")
+)
+
+// MailQueueConfig holds the limits of a MailQueue. Limits of zero or less
+// are not enforced.
+type MailQueueConfig struct {
+	// Workers is how many emails are sent at once, at least one
+	Workers int
+	// Size is how many emails may wait to be sent, at least one
+	Size int
+	// PerAddress and PerIP are how many emails may be requested for one
+	// address, or by one client, within Window
+	PerAddress int
+	PerIP      int
+	Window     time.Duration
+}
+
+// MailQueue runs the email jobs of handlers in the background on a fixed
+// number of workers. Jobs are refused rather than piled up when the queue
+// is full, or when an address or client asks for more emails than allowed,
+// so a flood of requests can neither exhaust the server nor spam a mailbox.
+type MailQueue struct {
+	jobs       chan func(ctx context.Context)
+	perAddress *throttle
+	perIP      *throttle
+	closeOnce  sync.Once
+	wg         sync.WaitGroup
+}
+
+// NewMailQueue starts the workers of a MailQueue
+func NewMailQueue(config MailQueueConfig) *MailQueue {
+	q := &MailQueue{
+		jobs:       make(chan func(ctx context.Context), max(config.Size, 1)),
+		perAddress: newThrottle(config.PerAddress, config.Window),
+		perIP:      newThrottle(config.PerIP, config.Window),
+	}
+	for i := 0; i < max(config.Workers, 1); i++ {
+		q.wg.Add(1)
+		go q.work()
+	}
+	return q
+}
+
+// Enqueue schedules job to send an email to address on behalf of the
+// client at ip. Each job gets its own context, bounded by mailTimeout.
+// Requests for unknown addresses count as well, so refusals tell nothing
+// about which addresses exist.
+func (q *MailQueue) Enqueue(address, ip string, job func(ctx context.Context)) error {
+	now := time.Now()
+	if !q.perIP.allow(ip, now) || !q.perAddress.allow(strings.ToLower(address), now) {
+		return ErrMailThrottled
+	}
+	select {
+	case q.jobs <- job:
+		return nil
+	default:
+		return ErrMailQueueFull
+	}
+}
+
+// Close waits for the queued jobs to finish. Enqueue must not be called
+// afterwards.
+func (q *MailQueue) Close() {
+	q.closeOnce.Do(func() {
+		close(q.jobs)
+	})
+	q.wg.Wait()
+}
+
+func (q *MailQueue) work() {
+	defer q.wg.Done()
+	for job := range q.jobs {
+		ctx, cancel := context.WithTimeout(context.Background(), mailTimeout)
+		job(ctx)
+		cancel()
+	}
+}
+
+// mailRefused answers a request whose email the queue refused
+func mailRefused(ctx *fiber.Ctx, err error) error {
+	status := fiber.StatusServiceUnavailable
+	if errors.Is(err, ErrMailThrottled) {
+		status = fiber.StatusTooManyRequests
+	}
+	return ctx.Status(status).JSON(&fiber.Map{
+		"error": err.Error(),
+	})
+}
+
+// enqueueMail schedules job and logs when it is refused, for emails whose
+// request already succeeded and that the user can ask for again
+func enqueueMail(q *MailQueue, flow, address, ip string, job func(ctx context.Context)) {
+	if err := q.Enqueue(address, ip, job); err != nil {
+		log.Printf("%s: email not sent: %v", flow, err)
+	}
+}
+
+// throttle counts events per key within fixed windows. Counts are dropped
+// as a whole when a window ends, which keeps memory bounded by the keys
+// seen within one window.
+type throttle struct {
+	mu          sync.Mutex
+	limit       int
+	window      time.Duration
+	windowStart time.Time
+	counts      map[string]int
+}
+
+func newThrottle(limit int, window time.Duration) *throttle {
+	return &throttle{
+		limit:  limit,
+		window: window,
+		counts: make(map[string]int),
+	}
+}
+
+// allow records an event for key, reporting whether it is within the limit
+func (t *throttle) allow(key string, now time.Time) bool {
+	if t.limit <= 0 || t.window <= 0 || key == "" {
+		return true
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if now.Sub(t.windowStart) >= t.window {
+		t.windowStart = now
+		clear(t.counts)
+	}
+	if t.counts[key] >= t.limit {
+		return false
+	}
+	t.counts[key]++
+	return true
+}
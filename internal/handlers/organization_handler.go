@@ -0,0 +1,286 @@
+package handlers
+
+import (
+	"errors"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/jackc/pgx/v5/pgtype"
+	customError "github.com/suryansh74/auth-package/internal/apperrors"
+	"github.com/suryansh74/auth-package/internal/db/sqlc"
+	"github.com/suryansh74/auth-package/internal/dto"
+	"github.com/suryansh74/auth-package/internal/middleware"
+	"github.com/suryansh74/auth-package/internal/services"
+)
+
+// OrganizationHandler serves the endpoints managing organizations, their
+// members and invitations
+type OrganizationHandler interface {
+	CreateOrganization(ctx *fiber.Ctx) error
+	ListOrganizations(ctx *fiber.Ctx) error
+	ListMembers(ctx *fiber.Ctx) error
+	UpdateMemberRole(ctx *fiber.Ctx) error
+	RemoveMember(ctx *fiber.Ctx) error
+	Invite(ctx *fiber.Ctx) error
+	AcceptInvitation(ctx *fiber.Ctx) error
+}
+
+type organizationHandler struct {
+	orgs               services.OrganizationService
+	invitationDuration time.Duration
+}
+
+func NewOrganizationHandler(orgs services.OrganizationService, invitationDuration time.Duration) OrganizationHandler {
+	return &organizationHandler{
+		orgs:               orgs,
+		invitationDuration: invitationDuration,
+	}
+}
+
+// CreateOrganization creates an organization owned by the current user
+func (oh *organizationHandler) CreateOrganization(ctx *fiber.Ctx) error {
+	payload, err := middleware.GetAuthPayload(ctx)
+	if err != nil {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(&fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	var req dto.CreateOrganizationRequest
+	err = ctx.BodyParser(&req)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(&fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	org, err := oh.orgs.CreateOrganization(ctx.Context(), payload.UserID, req.Name, req.Slug)
+	if err != nil {
+		return organizationError(ctx, err)
+	}
+	return ctx.Status(fiber.StatusCreated).JSON(&dto.OrganizationResponse{
+		ID:        org.ID,
+		Name:      org.Name,
+		Slug:      org.Slug,
+		Role:      services.OrganizationRoleOwner,
+		CreatedAt: org.CreatedAt.Time,
+	})
+}
+
+// ListOrganizations returns the organizations of the current user
+func (oh *organizationHandler) ListOrganizations(ctx *fiber.Ctx) error {
+	payload, err := middleware.GetAuthPayload(ctx)
+	if err != nil {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(&fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	orgs, err := oh.orgs.ListUserOrganizations(ctx.Context(), payload.UserID)
+	if err != nil {
+		return organizationError(ctx, err)
+	}
+
+	res := make([]dto.OrganizationResponse, 0, len(orgs))
+	for _, org := range orgs {
+		res = append(res, dto.OrganizationResponse{
+			ID:        org.ID,
+			Name:      org.Name,
+			Slug:      org.Slug,
+			Role:      org.Role,
+			CreatedAt: org.CreatedAt.Time,
+		})
+	}
+	return ctx.Status(fiber.StatusOK).JSON(&res)
+}
+
+func (oh *organizationHandler) ListMembers(ctx *fiber.Ctx) error {
+	payload, err := middleware.GetAuthPayload(ctx)
+	if err != nil {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(&fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	orgID, err := uuidParam(ctx, "org_id")
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(&fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	members, err := oh.orgs.ListMembers(ctx.Context(), payload.UserID, orgID)
+	if err != nil {
+		return organizationError(ctx, err)
+	}
+
+	res := make([]dto.OrganizationMemberResponse, 0, len(members))
+	for _, member := range members {
+		res = append(res, dto.OrganizationMemberResponse{
+			UserID:    member.UserID,
+			Name:      member.Name,
+			Email:     member.Email,
+			Role:      member.Role,
+			CreatedAt: member.CreatedAt.Time,
+		})
+	}
+	return ctx.Status(fiber.StatusOK).JSON(&res)
+}
+
+func (oh *organizationHandler) UpdateMemberRole(ctx *fiber.Ctx) error {
+	payload, err := middleware.GetAuthPayload(ctx)
+	if err != nil {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(&fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	orgID, userID, err := memberParams(ctx)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(&fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	var req dto.UpdateMemberRoleRequest
+	err = ctx.BodyParser(&req)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(&fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	member, err := oh.orgs.UpdateMemberRole(ctx.Context(), payload.UserID, orgID, userID, req.Role)
+	if err != nil {
+		return organizationError(ctx, err)
+	}
+	return ctx.Status(fiber.StatusOK).JSON(memberResponse(*member))
+}
+
+func (oh *organizationHandler) RemoveMember(ctx *fiber.Ctx) error {
+	payload, err := middleware.GetAuthPayload(ctx)
+	if err != nil {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(&fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	orgID, userID, err := memberParams(ctx)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(&fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	err = oh.orgs.RemoveMember(ctx.Context(), payload.UserID, orgID, userID)
+	if err != nil {
+		return organizationError(ctx, err)
+	}
+	return ctx.SendStatus(fiber.StatusNoContent)
+}
+
+// Invite creates an invitation to the organization. The response carries
+// the invitation token, which the invited user presents to accept it.
+func (oh *organizationHandler) Invite(ctx *fiber.Ctx) error {
+	payload, err := middleware.GetAuthPayload(ctx)
+	if err != nil {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(&fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	orgID, err := uuidParam(ctx, "org_id")
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(&fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	var req dto.InviteMemberRequest
+	err = ctx.BodyParser(&req)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(&fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	if req.Email == "" {
+		return ctx.Status(fiber.StatusBadRequest).JSON(&fiber.Map{
+			"error": "email is required",
+		})
+	}
+
+	invitationToken, invitation, err := oh.orgs.Invite(ctx.Context(), payload.UserID, orgID, req.Email, req.Role, oh.invitationDuration)
+	if err != nil {
+		return organizationError(ctx, err)
+	}
+	return ctx.Status(fiber.StatusCreated).JSON(&dto.InvitationResponse{
+		ID:             invitation.ID,
+		OrganizationID: invitation.OrganizationID,
+		Email:          invitation.Email,
+		Role:           invitation.Role,
+		Token:          invitationToken,
+		ExpiresAt:      invitation.ExpiresAt.Time,
+	})
+}
+
+// AcceptInvitation adds the current user to the organization they were
+// invited to
+func (oh *organizationHandler) AcceptInvitation(ctx *fiber.Ctx) error {
+	payload, err := middleware.GetAuthPayload(ctx)
+	if err != nil {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(&fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	var req dto.AcceptInvitationRequest
+	err = ctx.BodyParser(&req)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(&fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	if req.Token == "" {
+		return ctx.Status(fiber.StatusBadRequest).JSON(&fiber.Map{
+			"error": "token is required",
+		})
+	}
+
+	member, err := oh.orgs.AcceptInvitation(ctx.Context(), payload.UserID, payload.Email, req.Token)
+	if err != nil {
+		return organizationError(ctx, err)
+	}
+	return ctx.Status(fiber.StatusOK).JSON(memberResponse(*member))
+}
+
+// organizationError maps organization service errors to responses
+func organizationError(ctx *fiber.Ctx, err error) error {
+	status := fiber.StatusInternalServerError
+	switch {
+	case errors.Is(err, customError.ErrInvalidOrganizationName),
+		errors.Is(err, customError.ErrInvalidOrganizationSlug),
+		errors.Is(err, customError.ErrInvalidOrganizationRole),
+		errors.Is(err, customError.ErrInvalidInvitation):
+		status = fiber.StatusBadRequest
+	case errors.Is(err, customError.ErrNotOrganizationMember),
+		errors.Is(err, customError.ErrInsufficientOrganizationRole),
+		errors.Is(err, customError.ErrInvitationEmailMismatch):
+		status = fiber.StatusForbidden
+	case errors.Is(err, customError.ErrOrganizationNotFound):
+		status = fiber.StatusNotFound
+	case errors.Is(err, customError.ErrOrganizationAlreadyExist),
+		errors.Is(err, customError.ErrAlreadyOrganizationMember),
+		errors.Is(err, customError.ErrLastOrganizationOwner):
+		status = fiber.StatusConflict
+	}
+	return ctx.Status(status).JSON(&fiber.Map{
+		"error": err.Error(),
+	})
+}
+
+func memberParams(ctx *fiber.Ctx) (pgtype.UUID, pgtype.UUID, error) {
+	orgID, err := uuidParam(ctx, "org_id")
+	if err != nil {
+		return orgID, pgtype.UUID{}, err
+	}
+	userID, err := uuidParam(ctx, "user_id")
+	return orgID, userID, err
+}
+
+func memberResponse(member sqlc.OrganizationMember) dto.OrganizationMemberResponse {
+	return dto.OrganizationMemberResponse{
+		UserID:    member.UserID,
+		Role:      member.Role,
+		CreatedAt: member.CreatedAt.Time,
+	}
+}
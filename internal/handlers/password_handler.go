@@ -0,0 +1,140 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	customError "github.com/suryansh74/auth-package/internal/apperrors"
+	"github.com/suryansh74/auth-package/internal/db"
+	"github.com/suryansh74/auth-package/internal/dto"
+	"github.com/suryansh74/auth-package/internal/services"
+	"github.com/suryansh74/auth-package/mailer"
+	"github.com/suryansh74/auth-package/token"
+)
+
+type PasswordHandler interface {
+	ForgotPassword(ctx *fiber.Ctx) error
+	ResetPassword(ctx *fiber.Ctx) error
+}
+
+// PasswordResetConfig holds the settings of the forgot password flow
+type PasswordResetConfig struct {
+	// TokenDuration is how long a reset link can be used
+	TokenDuration time.Duration
+	// URL is the page of the client where a new password is entered; the
+	// token is added as the token query parameter
+	URL string
+	// AccessTokenDuration is the lifetime of access tokens
+	AccessTokenDuration time.Duration
+	// Queue sends the reset emails
+	Queue *MailQueue
+}
+
+type passwordHandler struct {
+	resets      services.PasswordResetService
+	sessions    services.SessionService
+	revocations token.RevocationStore
+	mailer      mailer.Mailer
+	config      PasswordResetConfig
+}
+
+func NewPasswordHandler(db db.Auth, revocations token.RevocationStore, m mailer.Mailer, config PasswordResetConfig) PasswordHandler {
+	return &passwordHandler{
+		resets:      services.NewPasswordResetManager(db),
+		sessions:    services.NewSessionManager(db, 0),
+		revocations: revocations,
+		mailer:      m,
+		config:      config,
+	}
+}
+
+// ForgotPassword emails a reset link to the user registered with the given
+// email. The response is the same whether or not such a user exists, and
+// the email is sent in the background so response times do not tell either.
+// Requests beyond the limits of the mail queue are refused with 429, or 503
+// when the queue is full.
+func (ph *passwordHandler) ForgotPassword(ctx *fiber.Ctx) error {
+	var req dto.ForgotPasswordRequest
+	err := ctx.BodyParser(&req)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(&fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	res := &fiber.Map{
+		"message": "if an account exists for this email, a reset link has been sent",
+	}
+	if req.Email == "" {
+		return ctx.Status(fiber.StatusOK).JSON(res)
+	}
+
+	// the request context is recycled once the handler returns
+	email := req.Email
+	err = ph.config.Queue.Enqueue(email, ctx.IP(), func(bgCtx context.Context) {
+		resetToken, user, err := ph.resets.RequestReset(bgCtx, email, ph.config.TokenDuration)
+		if err != nil {
+			if !errors.Is(err, customError.ErrUserNotFound) {
+				log.Printf("password reset: %v", err)
+			}
+			return
+		}
+		msg := passwordResetEmail(user.Email, user.Name, linkWithToken(ph.config.URL, resetToken))
+		if err := ph.mailer.Send(bgCtx, msg); err != nil {
+			log.Printf("password reset: cannot send email: %v", err)
+		}
+	})
+	if err != nil {
+		return mailRefused(ctx, err)
+	}
+	return ctx.Status(fiber.StatusOK).JSON(res)
+}
+
+// ResetPassword sets a new password with a token from a reset email. Every
+// token and session of the user issued before is revoked, since whoever
+// knew the old password may be using them.
+func (ph *passwordHandler) ResetPassword(ctx *fiber.Ctx) error {
+	var req dto.ResetPasswordRequest
+	err := ctx.BodyParser(&req)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(&fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	user, err := ph.resets.ResetPassword(ctx.Context(), req.Token, req.NewPassword)
+	if err != nil {
+		switch {
+		case errors.Is(err, customError.ErrInvalidResetToken), errors.Is(err, customError.ErrWeakPassword):
+			return ctx.Status(fiber.StatusBadRequest).JSON(&fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		return ctx.Status(fiber.StatusInternalServerError).JSON(&fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	err = revokeUserTokens(ctx.Context(), ph.revocations, user.ID, user.PasswordChangedAt.Time, ph.config.AccessTokenDuration)
+	if err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(&fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	err = ph.sessions.RevokeUserSessions(ctx.Context(), user.ID)
+	if err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(&fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	if err := ph.mailer.Send(ctx.Context(), passwordChangedEmail(user.Email, user.Name)); err != nil {
+		log.Printf("password reset: cannot send email: %v", err)
+	}
+	return ctx.Status(fiber.StatusOK).JSON(&fiber.Map{
+		"message": "password has been reset, please log in again",
+	})
+}
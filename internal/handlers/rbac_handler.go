@@ -0,0 +1,252 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/jackc/pgx/v5/pgtype"
+	customError "github.com/suryansh74/auth-package/internal/apperrors"
+	"github.com/suryansh74/auth-package/internal/db/sqlc"
+	"github.com/suryansh74/auth-package/internal/dto"
+	"github.com/suryansh74/auth-package/internal/services"
+)
+
+// RBACHandler serves the admin endpoints that manage roles, permissions and
+// the roles assigned to users
+type RBACHandler interface {
+	ListRoles(ctx *fiber.Ctx) error
+	CreateRole(ctx *fiber.Ctx) error
+	DeleteRole(ctx *fiber.Ctx) error
+	ListRolePermissions(ctx *fiber.Ctx) error
+	GrantPermission(ctx *fiber.Ctx) error
+	RevokePermission(ctx *fiber.Ctx) error
+	ListPermissions(ctx *fiber.Ctx) error
+	CreatePermission(ctx *fiber.Ctx) error
+	DeletePermission(ctx *fiber.Ctx) error
+	ListUserRoles(ctx *fiber.Ctx) error
+	AssignRole(ctx *fiber.Ctx) error
+	RevokeRole(ctx *fiber.Ctx) error
+}
+
+type rbacHandler struct {
+	roles services.RoleService
+}
+
+func NewRBACHandler(roles services.RoleService) RBACHandler {
+	return &rbacHandler{
+		roles: roles,
+	}
+}
+
+func (rh *rbacHandler) ListRoles(ctx *fiber.Ctx) error {
+	roles, err := rh.roles.ListRoles(ctx.Context())
+	if err != nil {
+		return rbacError(ctx, err)
+	}
+	res := make([]dto.RoleResponse, 0, len(roles))
+	for _, role := range roles {
+		res = append(res, roleResponse(role))
+	}
+	return ctx.Status(fiber.StatusOK).JSON(&res)
+}
+
+func (rh *rbacHandler) CreateRole(ctx *fiber.Ctx) error {
+	var req dto.CreateRoleRequest
+	err := ctx.BodyParser(&req)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(&fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	role, err := rh.roles.CreateRole(ctx.Context(), req.Name, req.Description)
+	if err != nil {
+		return rbacError(ctx, err)
+	}
+	return ctx.Status(fiber.StatusCreated).JSON(roleResponse(*role))
+}
+
+func (rh *rbacHandler) DeleteRole(ctx *fiber.Ctx) error {
+	err := rh.roles.DeleteRole(ctx.Context(), ctx.Params("role"))
+	if err != nil {
+		return rbacError(ctx, err)
+	}
+	return ctx.SendStatus(fiber.StatusNoContent)
+}
+
+func (rh *rbacHandler) ListRolePermissions(ctx *fiber.Ctx) error {
+	permissions, err := rh.roles.ListRolePermissions(ctx.Context(), ctx.Params("role"))
+	if err != nil {
+		return rbacError(ctx, err)
+	}
+	return ctx.Status(fiber.StatusOK).JSON(permissionResponses(permissions))
+}
+
+func (rh *rbacHandler) GrantPermission(ctx *fiber.Ctx) error {
+	var req dto.GrantPermissionRequest
+	err := ctx.BodyParser(&req)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(&fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	err = rh.roles.GrantPermission(ctx.Context(), ctx.Params("role"), req.Permission)
+	if err != nil {
+		return rbacError(ctx, err)
+	}
+	return ctx.SendStatus(fiber.StatusNoContent)
+}
+
+func (rh *rbacHandler) RevokePermission(ctx *fiber.Ctx) error {
+	err := rh.roles.RevokePermission(ctx.Context(), ctx.Params("role"), ctx.Params("permission"))
+	if err != nil {
+		return rbacError(ctx, err)
+	}
+	return ctx.SendStatus(fiber.StatusNoContent)
+}
+
+func (rh *rbacHandler) ListPermissions(ctx *fiber.Ctx) error {
+	permissions, err := rh.roles.ListPermissions(ctx.Context())
+	if err != nil {
+		return rbacError(ctx, err)
+	}
+	return ctx.Status(fiber.StatusOK).JSON(permissionResponses(permissions))
+}
+
+func (rh *rbacHandler) CreatePermission(ctx *fiber.Ctx) error {
+	var req dto.CreatePermissionRequest
+	err := ctx.BodyParser(&req)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(&fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	permission, err := rh.roles.CreatePermission(ctx.Context(), req.Name, req.Description)
+	if err != nil {
+		return rbacError(ctx, err)
+	}
+	return ctx.Status(fiber.StatusCreated).JSON(permissionResponse(*permission))
+}
+
+func (rh *rbacHandler) DeletePermission(ctx *fiber.Ctx) error {
+	err := rh.roles.DeletePermission(ctx.Context(), ctx.Params("permission"))
+	if err != nil {
+		return rbacError(ctx, err)
+	}
+	return ctx.SendStatus(fiber.StatusNoContent)
+}
+
+// ListUserRoles returns the roles of the user and the permissions they grant
+func (rh *rbacHandler) ListUserRoles(ctx *fiber.Ctx) error {
+	userID, err := uuidParam(ctx, "id")
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(&fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	roles, err := rh.roles.UserRoles(ctx.Context(), userID)
+	if err != nil {
+		return rbacError(ctx, err)
+	}
+	permissions, err := rh.roles.UserPermissions(ctx.Context(), userID)
+	if err != nil {
+		return rbacError(ctx, err)
+	}
+	return ctx.Status(fiber.StatusOK).JSON(&dto.UserRolesResponse{
+		UserID:      userID,
+		Roles:       roles,
+		Permissions: permissions,
+	})
+}
+
+// AssignRole assigns a role to the user. It takes effect in the user's next
+// access token, and immediately for checks that load roles from the store.
+func (rh *rbacHandler) AssignRole(ctx *fiber.Ctx) error {
+	userID, err := uuidParam(ctx, "id")
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(&fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	var req dto.AssignRoleRequest
+	err = ctx.BodyParser(&req)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(&fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	err = rh.roles.AssignRole(ctx.Context(), userID, req.Role)
+	if err != nil {
+		return rbacError(ctx, err)
+	}
+	return ctx.SendStatus(fiber.StatusNoContent)
+}
+
+func (rh *rbacHandler) RevokeRole(ctx *fiber.Ctx) error {
+	userID, err := uuidParam(ctx, "id")
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(&fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	err = rh.roles.RevokeRole(ctx.Context(), userID, ctx.Params("role"))
+	if err != nil {
+		return rbacError(ctx, err)
+	}
+	return ctx.SendStatus(fiber.StatusNoContent)
+}
+
+// rbacError maps role service errors to responses
+func rbacError(ctx *fiber.Ctx, err error) error {
+	status := fiber.StatusInternalServerError
+	switch {
+	case errors.Is(err, customError.ErrInvalidRBACName):
+		status = fiber.StatusBadRequest
+	case errors.Is(err, customError.ErrRoleNotFound),
+		errors.Is(err, customError.ErrPermissionNotFound),
+		errors.Is(err, customError.ErrUserNotFound):
+		status = fiber.StatusNotFound
+	case errors.Is(err, customError.ErrRoleAlreadyExist),
+		errors.Is(err, customError.ErrPermissionAlreadyExist):
+		status = fiber.StatusConflict
+	}
+	return ctx.Status(status).JSON(&fiber.Map{
+		"error": err.Error(),
+	})
+}
+
+// uuidParam parses the route parameter name as a UUID
+func uuidParam(ctx *fiber.Ctx, name string) (pgtype.UUID, error) {
+	var id pgtype.UUID
+	err := id.Scan(ctx.Params(name))
+	if err != nil {
+		return id, fmt.Errorf("invalid %s", name)
+	}
+	return id, nil
+}
+
+func roleResponse(role sqlc.Role) dto.RoleResponse {
+	return dto.RoleResponse{
+		ID:          role.ID,
+		Name:        role.Name,
+		Description: role.Description,
+		CreatedAt:   role.CreatedAt.Time,
+	}
+}
+
+func permissionResponse(permission sqlc.Permission) dto.PermissionResponse {
+	return dto.PermissionResponse{
+		ID:          permission.ID,
+		Name:        permission.Name,
+		Description: permission.Description,
+		CreatedAt:   permission.CreatedAt.Time,
+	}
+}
+
+func permissionResponses(permissions []sqlc.Permission) []dto.PermissionResponse {
+	res := make([]dto.PermissionResponse, 0, len(permissions))
+	for _, permission := range permissions {
+		res = append(res, permissionResponse(permission))
+	}
+	return res
+}
@@ -0,0 +1,24 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/suryansh74/auth-package/token"
+)
+
+// revokeUserTokens revokes every access token of the user issued up to t,
+// see token.RevocationCutoff. Tokens issued before the cutoff expire within
+// accessTokenDuration, so the revocation is kept as long. Tokens issued
+// afterwards are stamped as issued at the cutoff at the earliest by
+// createAccessToken, so they survive it.
+func revokeUserTokens(ctx context.Context, revocations token.RevocationStore, userID pgtype.UUID, t time.Time, accessTokenDuration time.Duration) error {
+	cutoff := token.RevocationCutoff(t)
+	err := revocations.RevokeUserTokens(ctx, userID, cutoff, cutoff.Add(accessTokenDuration))
+	if err != nil {
+		return errors.New("unable to revoke tokens")
+	}
+	return nil
+}
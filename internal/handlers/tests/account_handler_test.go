@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/suryansh74/auth-package/internal/db/mock"
+	"github.com/suryansh74/auth-package/internal/db/sqlc"
+	"github.com/suryansh74/auth-package/internal/handlers"
+)
+
+func TestRestoreAccountThrottles(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockAuth := mock.NewMockAuth(ctrl)
+	// throttled attempts do not reach the database
+	mockAuth.EXPECT().
+		GetDeletedUserByEmail(gomock.Any(), gomock.Any()).
+		Times(3).
+		Return(sqlc.User{}, sql.ErrNoRows)
+
+	accounts := handlers.NewAccountHandler(mockAuth, nil, handlers.AccountDeletionConfig{
+		RestoreLimitPerAddress: 2,
+		RestoreLimitPerIP:      4,
+		RestoreLimitWindow:     time.Hour,
+	})
+	app := fiber.New()
+	app.Post("/restore", accounts.RestoreAccount)
+
+	// every request comes from the same client
+	restore := func(email string) int {
+		body := `{"email":"` + email + `","password":"password123"}`
+		req := httptest.NewRequest(http.MethodPost, "/restore", strings.NewReader(body))
+		req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+		res, err := app.Test(req)
+		require.NoError(t, err)
+		return res.StatusCode
+	}
+
+	require.Equal(t, http.StatusUnauthorized, restore("a@example.com"))
+	// emails are counted whatever their case
+	require.Equal(t, http.StatusUnauthorized, restore("A@Example.com"))
+	require.Equal(t, http.StatusTooManyRequests, restore("a@example.com"))
+
+	require.Equal(t, http.StatusUnauthorized, restore("b@example.com"))
+	// throttled attempts count against the client as well
+	require.Equal(t, http.StatusTooManyRequests, restore("c@example.com"))
+}
@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+
+	"github.com/suryansh74/auth-package/internal/db"
+	"github.com/suryansh74/auth-package/internal/db/mock"
+	"github.com/suryansh74/auth-package/internal/db/sqlc"
+	"github.com/suryansh74/auth-package/internal/dto"
+	"github.com/suryansh74/auth-package/internal/handlers"
+	"github.com/suryansh74/auth-package/internal/middleware"
+	"github.com/suryansh74/auth-package/internal/utils"
+	"github.com/suryansh74/auth-package/token"
+)
+
+func TestLoginRightAfterEmailChange(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	maker, err := token.NewPasetoMaker(utils.RandomString(32))
+	require.NoError(t, err)
+	revocations := token.NewMemoryRevocationStore()
+
+	password := "password123"
+	hashedPassword, err := utils.HashedPassword(password)
+	require.NoError(t, err)
+	user := sqlc.User{
+		ID:              pgtype.UUID{Bytes: uuid.New(), Valid: true},
+		Email:           utils.RandomEmail(),
+		Password:        hashedPassword,
+		Status:          "active",
+		EmailVerifiedAt: pgtype.Timestamp{Time: time.Now(), Valid: true},
+	}
+	request := sqlc.EmailChangeRequest{
+		ID:        pgtype.UUID{Bytes: uuid.New(), Valid: true},
+		UserID:    user.ID,
+		OldEmail:  user.Email,
+		NewEmail:  utils.RandomEmail(),
+		ExpiresAt: pgtype.Timestamp{Time: time.Now().Add(time.Hour), Valid: true},
+	}
+
+	mockAuth := mock.NewMockAuth(ctrl)
+	mockAuth.EXPECT().
+		GetEmailChangeRequestByConfirmTokenHash(gomock.Any(), gomock.Eq(utils.HashToken("confirm-token"))).
+		Times(1).
+		Return(request, nil)
+	mockAuth.EXPECT().GetUserByEmail(gomock.Any(), gomock.Eq(request.NewEmail)).Times(1).Return(sqlc.User{}, sql.ErrNoRows)
+	mockAuth.EXPECT().GetDeletedUserByEmail(gomock.Any(), gomock.Eq(request.NewEmail)).Times(1).Return(sqlc.User{}, sql.ErrNoRows)
+	mockAuth.EXPECT().
+		ExecTx(gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(ctx context.Context, fn func(db.Auth) error) error {
+			return fn(mockAuth)
+		})
+	mockAuth.EXPECT().ConfirmEmailChangeRequest(gomock.Any(), gomock.Eq(request.ID)).Times(1).Return(request, nil)
+	mockAuth.EXPECT().
+		UpdateUserEmail(gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(ctx interface{}, params sqlc.UpdateUserEmailParams) (sqlc.User, error) {
+			user.Email = params.NewEmail
+			return user, nil
+		})
+	mockAuth.EXPECT().GetUserByEmail(gomock.Any(), gomock.Eq(request.NewEmail)).Times(1).DoAndReturn(
+		func(ctx interface{}, email string) (sqlc.User, error) {
+			return user, nil
+		})
+	mockAuth.EXPECT().
+		CreateSession(gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(ctx interface{}, params sqlc.CreateSessionParams) (sqlc.Session, error) {
+			return sqlc.Session{UserID: params.UserID, FamilyID: params.FamilyID, ExpiresAt: params.ExpiresAt}, nil
+		})
+	mockAuth.EXPECT().ListUserRoles(gomock.Any(), gomock.Eq(user.ID)).Times(1).Return([]string{}, nil)
+
+	app := fiber.New()
+	users := handlers.NewUserHandler(app, mockAuth, maker, revocations, handlers.TokenConfig{
+		AccessTokenDuration:  time.Minute,
+		RefreshTokenDuration: time.Hour,
+	}, handlers.EmailVerificationConfig{})
+	changes := handlers.NewEmailChangeHandler(mockAuth, revocations, handlers.EmailChangeConfig{
+		AccessTokenDuration: time.Minute,
+	})
+	app.Post("/login", users.Login)
+	app.Post("/email/confirm", changes.ConfirmEmailChange)
+	app.Get("/me", middleware.AuthMiddleware(maker, middleware.WithRevocationStore(revocations)), func(ctx *fiber.Ctx) error {
+		return ctx.SendStatus(fiber.StatusOK)
+	})
+
+	send := func(req *http.Request) *http.Response {
+		res, err := app.Test(req)
+		require.NoError(t, err)
+		t.Cleanup(func() { res.Body.Close() })
+		return res
+	}
+
+	res := send(httptest.NewRequest(http.MethodPost, "/email/confirm?token=confirm-token", nil))
+	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	// logging in with the new email within the second of the change gives
+	// a token the revocation does not cover
+	req := httptest.NewRequest(http.MethodPost, "/login",
+		strings.NewReader(`{"email":"`+request.NewEmail+`","password":"`+password+`"}`))
+	req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	res = send(req)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	var login dto.UserLoginResponse
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&login))
+
+	req = httptest.NewRequest(http.MethodGet, "/me", nil)
+	req.Header.Set(fiber.HeaderAuthorization, "Bearer "+login.AccessToken)
+	require.Equal(t, http.StatusOK, send(req).StatusCode)
+}
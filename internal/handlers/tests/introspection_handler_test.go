@@ -0,0 +1,281 @@
+package handlers
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+
+	customError "github.com/suryansh74/auth-package/internal/apperrors"
+	"github.com/suryansh74/auth-package/internal/dto"
+	"github.com/suryansh74/auth-package/internal/handlers"
+	"github.com/suryansh74/auth-package/internal/utils"
+	"github.com/suryansh74/auth-package/token"
+)
+
+const (
+	clientID     = "billing"
+	clientSecret = "s3cret:with&symbols"
+)
+
+// introspectionRequest builds a form encoded introspection request for
+// tokenString, with extra form fields such as body credentials
+func introspectionRequest(tokenString string, fields url.Values) *http.Request {
+	form := url.Values{}
+	if tokenString != "" {
+		form.Set("token", tokenString)
+	}
+	for key, values := range fields {
+		form[key] = values
+	}
+	req := httptest.NewRequest(http.MethodPost, "/introspect", strings.NewReader(form.Encode()))
+	req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationForm)
+	return req
+}
+
+// withBasicAuth sets client credentials the way RFC 6749 section 2.3.1
+// encodes them
+func withBasicAuth(req *http.Request, id, secret string) *http.Request {
+	credentials := url.QueryEscape(id) + ":" + url.QueryEscape(secret)
+	req.Header.Set(fiber.HeaderAuthorization, "Basic "+base64.StdEncoding.EncodeToString([]byte(credentials)))
+	return req
+}
+
+func TestIntrospect(t *testing.T) {
+	maker, err := token.NewPasetoMaker(utils.RandomString(32))
+	require.NoError(t, err)
+	userID := pgtype.UUID{Bytes: uuid.New(), Valid: true}
+	sessionID := uuid.New()
+	email := utils.RandomEmail()
+
+	newToken := func(t *testing.T, duration time.Duration) (string, *token.Payload) {
+		tokenString, err := maker.CreateToken(userID, email, duration,
+			token.WithSessionID(sessionID), token.WithScopes("read", "write"))
+		require.NoError(t, err)
+		payload, err := maker.VerifyToken(tokenString)
+		if duration > 0 {
+			require.NoError(t, err)
+		}
+		return tokenString, payload
+	}
+
+	testCases := []struct {
+		name string
+		// accountStatus is what the status check reports for the user
+		accountStatus error
+		buildRequest  func(t *testing.T, revocations *token.MemoryRevocationStore) *http.Request
+		checkResponse func(t *testing.T, resp *http.Response)
+	}{
+		{
+			name: "ActiveWithBasicAuth",
+			buildRequest: func(t *testing.T, revocations *token.MemoryRevocationStore) *http.Request {
+				tokenString, _ := newToken(t, time.Minute)
+				return withBasicAuth(introspectionRequest(tokenString, nil), clientID, clientSecret)
+			},
+			checkResponse: func(t *testing.T, resp *http.Response) {
+				require.Equal(t, http.StatusOK, resp.StatusCode)
+				require.Equal(t, "no-store", resp.Header.Get(fiber.HeaderCacheControl))
+				res := decodeIntrospection(t, resp)
+				require.True(t, res.Active)
+				require.Equal(t, uuid.UUID(userID.Bytes).String(), res.Subject)
+				require.Equal(t, email, res.Username)
+				require.Equal(t, "read write", res.Scope)
+				require.Equal(t, "access_token", res.TokenType)
+				require.Equal(t, sessionID.String(), res.SessionID)
+				require.NotZero(t, res.ExpiresAt)
+			},
+		},
+		{
+			name: "ActiveWithBodyCredentials",
+			buildRequest: func(t *testing.T, revocations *token.MemoryRevocationStore) *http.Request {
+				tokenString, _ := newToken(t, time.Minute)
+				return introspectionRequest(tokenString, url.Values{
+					"client_id":     {clientID},
+					"client_secret": {clientSecret},
+				})
+			},
+			checkResponse: func(t *testing.T, resp *http.Response) {
+				require.Equal(t, http.StatusOK, resp.StatusCode)
+				require.True(t, decodeIntrospection(t, resp).Active)
+			},
+		},
+		{
+			name: "BasicAuthSchemeIsCaseInsensitive",
+			buildRequest: func(t *testing.T, revocations *token.MemoryRevocationStore) *http.Request {
+				tokenString, _ := newToken(t, time.Minute)
+				req := withBasicAuth(introspectionRequest(tokenString, nil), clientID, clientSecret)
+				req.Header.Set(fiber.HeaderAuthorization, strings.Replace(req.Header.Get(fiber.HeaderAuthorization), "Basic", "basic", 1))
+				return req
+			},
+			checkResponse: func(t *testing.T, resp *http.Response) {
+				require.Equal(t, http.StatusOK, resp.StatusCode)
+				require.True(t, decodeIntrospection(t, resp).Active)
+			},
+		},
+		{
+			name: "WrongSecret",
+			buildRequest: func(t *testing.T, revocations *token.MemoryRevocationStore) *http.Request {
+				tokenString, _ := newToken(t, time.Minute)
+				return withBasicAuth(introspectionRequest(tokenString, nil), clientID, "wrong")
+			},
+			checkResponse: func(t *testing.T, resp *http.Response) {
+				requireInvalidClient(t, resp)
+			},
+		},
+		{
+			name: "UnknownClient",
+			buildRequest: func(t *testing.T, revocations *token.MemoryRevocationStore) *http.Request {
+				tokenString, _ := newToken(t, time.Minute)
+				return introspectionRequest(tokenString, url.Values{
+					"client_id":     {"unknown"},
+					"client_secret": {clientSecret},
+				})
+			},
+			checkResponse: func(t *testing.T, resp *http.Response) {
+				requireInvalidClient(t, resp)
+			},
+		},
+		{
+			name: "NoCredentials",
+			buildRequest: func(t *testing.T, revocations *token.MemoryRevocationStore) *http.Request {
+				tokenString, _ := newToken(t, time.Minute)
+				return introspectionRequest(tokenString, nil)
+			},
+			checkResponse: func(t *testing.T, resp *http.Response) {
+				requireInvalidClient(t, resp)
+			},
+		},
+		{
+			name: "MalformedBasicAuth",
+			buildRequest: func(t *testing.T, revocations *token.MemoryRevocationStore) *http.Request {
+				tokenString, _ := newToken(t, time.Minute)
+				req := introspectionRequest(tokenString, nil)
+				req.Header.Set(fiber.HeaderAuthorization, "Basic not-base64!")
+				return req
+			},
+			checkResponse: func(t *testing.T, resp *http.Response) {
+				requireInvalidClient(t, resp)
+			},
+		},
+		{
+			name: "MissingToken",
+			buildRequest: func(t *testing.T, revocations *token.MemoryRevocationStore) *http.Request {
+				return withBasicAuth(introspectionRequest("", nil), clientID, clientSecret)
+			},
+			checkResponse: func(t *testing.T, resp *http.Response) {
+				require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+			},
+		},
+		{
+			name: "InvalidToken",
+			buildRequest: func(t *testing.T, revocations *token.MemoryRevocationStore) *http.Request {
+				return withBasicAuth(introspectionRequest("not-a-token", nil), clientID, clientSecret)
+			},
+			checkResponse: func(t *testing.T, resp *http.Response) {
+				requireInactive(t, resp)
+			},
+		},
+		{
+			name: "ExpiredToken",
+			buildRequest: func(t *testing.T, revocations *token.MemoryRevocationStore) *http.Request {
+				tokenString, _ := newToken(t, -time.Minute)
+				return withBasicAuth(introspectionRequest(tokenString, nil), clientID, clientSecret)
+			},
+			checkResponse: func(t *testing.T, resp *http.Response) {
+				requireInactive(t, resp)
+			},
+		},
+		{
+			name: "RevokedToken",
+			buildRequest: func(t *testing.T, revocations *token.MemoryRevocationStore) *http.Request {
+				tokenString, payload := newToken(t, time.Minute)
+				require.NoError(t, revocations.Revoke(context.Background(), payload.ID, payload.ExpiredAt))
+				return withBasicAuth(introspectionRequest(tokenString, nil), clientID, clientSecret)
+			},
+			checkResponse: func(t *testing.T, resp *http.Response) {
+				requireInactive(t, resp)
+			},
+		},
+		{
+			// suspensions do not revoke tokens
+			name:          "SuspendedUser",
+			accountStatus: customError.ErrAccountSuspended,
+			buildRequest: func(t *testing.T, revocations *token.MemoryRevocationStore) *http.Request {
+				tokenString, _ := newToken(t, time.Minute)
+				return withBasicAuth(introspectionRequest(tokenString, nil), clientID, clientSecret)
+			},
+			checkResponse: func(t *testing.T, resp *http.Response) {
+				requireInactive(t, resp)
+			},
+		},
+		{
+			name:          "DeletedUser",
+			accountStatus: customError.ErrAccountDeleted,
+			buildRequest: func(t *testing.T, revocations *token.MemoryRevocationStore) *http.Request {
+				tokenString, _ := newToken(t, time.Minute)
+				return withBasicAuth(introspectionRequest(tokenString, nil), clientID, clientSecret)
+			},
+			checkResponse: func(t *testing.T, resp *http.Response) {
+				requireInactive(t, resp)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			revocations := token.NewMemoryRevocationStore()
+			statuses := accountStatuses{userID: tc.accountStatus}
+			handler := handlers.NewIntrospectionHandler(maker, revocations, statuses, map[string]string{clientID: clientSecret})
+			app := fiber.New()
+			app.Post("/introspect", handler.Introspect)
+
+			resp, err := app.Test(tc.buildRequest(t, revocations))
+			require.NoError(t, err)
+			defer resp.Body.Close()
+
+			tc.checkResponse(t, resp)
+		})
+	}
+}
+
+// accountStatuses is an account status store reporting the error mapped to
+// each user, and nil for the others
+type accountStatuses map[pgtype.UUID]error
+
+func (s accountStatuses) CheckAccountStatus(ctx context.Context, userID pgtype.UUID) error {
+	return s[userID]
+}
+
+func decodeIntrospection(t *testing.T, resp *http.Response) dto.IntrospectionResponse {
+	var res dto.IntrospectionResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&res))
+	return res
+}
+
+// requireInactive checks that nothing but the inactive state is revealed
+func requireInactive(t *testing.T, resp *http.Response) {
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var res map[string]interface{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&res))
+	require.Equal(t, map[string]interface{}{"active": false}, res)
+}
+
+func requireInvalidClient(t *testing.T, resp *http.Response) {
+	require.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	require.Equal(t, `Basic realm="introspect"`, resp.Header.Get(fiber.HeaderWWWAuthenticate))
+	var res map[string]string
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&res))
+	require.Equal(t, "invalid_client", res["error"])
+}
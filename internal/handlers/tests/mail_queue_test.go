@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/suryansh74/auth-package/internal/handlers"
+)
+
+func TestMailQueueThrottles(t *testing.T) {
+	q := handlers.NewMailQueue(handlers.MailQueueConfig{
+		Size:       10,
+		PerAddress: 2,
+		PerIP:      3,
+		Window:     time.Hour,
+	})
+	defer q.Close()
+	job := func(ctx context.Context) {}
+
+	require.NoError(t, q.Enqueue("a@example.com", "10.0.0.1", job))
+	// addresses are counted whatever their case
+	require.NoError(t, q.Enqueue("A@Example.com", "10.0.0.2", job))
+	require.ErrorIs(t, q.Enqueue("a@example.com", "10.0.0.3", job), handlers.ErrMailThrottled)
+
+	require.NoError(t, q.Enqueue("b@example.com", "10.0.0.1", job))
+	require.NoError(t, q.Enqueue("c@example.com", "10.0.0.1", job))
+	require.ErrorIs(t, q.Enqueue("d@example.com", "10.0.0.1", job), handlers.ErrMailThrottled)
+}
+
+func TestMailQueueIsBounded(t *testing.T) {
+	q := handlers.NewMailQueue(handlers.MailQueueConfig{Workers: 1, Size: 1})
+	started := make(chan struct{})
+	release := make(chan struct{})
+	done := make(chan string, 2)
+	hasDeadline := make(chan bool, 1)
+
+	require.NoError(t, q.Enqueue("a@example.com", "10.0.0.1", func(ctx context.Context) {
+		close(started)
+		<-release
+		_, ok := ctx.Deadline()
+		hasDeadline <- ok
+		done <- "first"
+	}))
+	<-started
+	require.NoError(t, q.Enqueue("b@example.com", "10.0.0.1", func(ctx context.Context) {
+		done <- "second"
+	}))
+	// the worker is busy and the queue holds one job
+	require.ErrorIs(t, q.Enqueue("c@example.com", "10.0.0.1", func(ctx context.Context) {}), handlers.ErrMailQueueFull)
+
+	close(release)
+	q.Close()
+	require.Equal(t, "first", <-done)
+	require.Equal(t, "second", <-done)
+	// each job gets its own bounded context
+	require.True(t, <-hasDeadline)
+}
@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+
+	"github.com/suryansh74/auth-package/internal/db/mock"
+	"github.com/suryansh74/auth-package/internal/db/sqlc"
+	"github.com/suryansh74/auth-package/internal/dto"
+	"github.com/suryansh74/auth-package/internal/handlers"
+	"github.com/suryansh74/auth-package/internal/middleware"
+	"github.com/suryansh74/auth-package/internal/utils"
+	"github.com/suryansh74/auth-package/token"
+)
+
+func TestLoginRightAfterPasswordChange(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// PASETO keeps issue times below the second, which the revocation
+	// cutoff is rounded up from
+	maker, err := token.NewPasetoMaker(utils.RandomString(32))
+	require.NoError(t, err)
+	revocations := token.NewMemoryRevocationStore()
+
+	password, newPassword := "password123", "password456"
+	hashedPassword, err := utils.HashedPassword(password)
+	require.NoError(t, err)
+	user := sqlc.User{
+		ID:              pgtype.UUID{Bytes: uuid.New(), Valid: true},
+		Email:           utils.RandomEmail(),
+		Password:        hashedPassword,
+		Status:          "active",
+		EmailVerifiedAt: pgtype.Timestamp{Time: time.Now(), Valid: true},
+	}
+
+	mockAuth := mock.NewMockAuth(ctrl)
+	mockAuth.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.ID)).Times(1).Return(user, nil)
+	mockAuth.EXPECT().
+		UpdateUserPassword(gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(ctx interface{}, params sqlc.UpdateUserPasswordParams) (sqlc.User, error) {
+			user.Password = params.Password
+			user.PasswordChangedAt = params.PasswordChangedAt
+			return user, nil
+		})
+	mockAuth.EXPECT().RevokeUserSessions(gomock.Any(), gomock.Eq(user.ID)).Times(1).Return(nil)
+	mockAuth.EXPECT().GetUserByEmail(gomock.Any(), gomock.Eq(user.Email)).Times(1).DoAndReturn(
+		func(ctx interface{}, email string) (sqlc.User, error) {
+			return user, nil
+		})
+	mockAuth.EXPECT().
+		CreateSession(gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(ctx interface{}, params sqlc.CreateSessionParams) (sqlc.Session, error) {
+			return sqlc.Session{UserID: params.UserID, FamilyID: params.FamilyID, ExpiresAt: params.ExpiresAt}, nil
+		})
+	mockAuth.EXPECT().ListUserRoles(gomock.Any(), gomock.Eq(user.ID)).Times(1).Return([]string{}, nil)
+
+	app := fiber.New()
+	users := handlers.NewUserHandler(app, mockAuth, maker, revocations, handlers.TokenConfig{
+		AccessTokenDuration:  time.Minute,
+		RefreshTokenDuration: time.Hour,
+	}, handlers.EmailVerificationConfig{})
+	authMiddleware := middleware.AuthMiddleware(maker, middleware.WithRevocationStore(revocations))
+	app.Post("/login", users.Login)
+	app.Post("/password", authMiddleware, users.ChangePassword)
+	app.Get("/me", authMiddleware, func(ctx *fiber.Ctx) error {
+		return ctx.SendStatus(fiber.StatusOK)
+	})
+
+	request := func(method, path, accessToken, body string) *http.Response {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+		if accessToken != "" {
+			req.Header.Set(fiber.HeaderAuthorization, "Bearer "+accessToken)
+		}
+		res, err := app.Test(req)
+		require.NoError(t, err)
+		t.Cleanup(func() { res.Body.Close() })
+		return res
+	}
+
+	oldToken, err := maker.CreateToken(user.ID, user.Email, time.Minute)
+	require.NoError(t, err)
+	res := request(http.MethodPost, "/password", oldToken,
+		`{"current_password":"`+password+`","new_password":"`+newPassword+`"}`)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	// logging in at once, within the second of the change, gives a token
+	// the revocation does not cover
+	res = request(http.MethodPost, "/login", "", `{"email":"`+user.Email+`","password":"`+newPassword+`"}`)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	var login dto.UserLoginResponse
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&login))
+	require.NotEmpty(t, login.AccessToken)
+
+	require.Equal(t, http.StatusOK, request(http.MethodGet, "/me", login.AccessToken, "").StatusCode)
+	require.Equal(t, http.StatusUnauthorized, request(http.MethodGet, "/me", oldToken, "").StatusCode)
+}
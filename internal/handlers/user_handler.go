@@ -1,13 +1,20 @@
 package handlers
 
 import (
+	"context"
+	"errors"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	customError "github.com/suryansh74/auth-package/internal/apperrors"
 	"github.com/suryansh74/auth-package/internal/db"
+	"github.com/suryansh74/auth-package/internal/db/sqlc"
 	"github.com/suryansh74/auth-package/internal/dto"
 	"github.com/suryansh74/auth-package/internal/middleware"
 	"github.com/suryansh74/auth-package/internal/services"
+	"github.com/suryansh74/auth-package/internal/utils"
 	"github.com/suryansh74/auth-package/token"
 )
 
@@ -15,22 +22,55 @@ type UserHandler interface {
 	Register(ctx *fiber.Ctx) error
 	Login(ctx *fiber.Ctx) error
 	CheckAuthUser(ctx *fiber.Ctx) error
+	UpdateProfile(ctx *fiber.Ctx) error
+	RefreshToken(ctx *fiber.Ctx) error
+	Logout(ctx *fiber.Ctx) error
+	LogoutAll(ctx *fiber.Ctx) error
+	SwitchOrganization(ctx *fiber.Ctx) error
+	ChangePassword(ctx *fiber.Ctx) error
 }
 
+// TokenConfig holds the settings used when issuing tokens
+type TokenConfig struct {
+	AccessTokenDuration  time.Duration
+	RefreshTokenDuration time.Duration
+	Issuer               string
+	Audience             []string
+	// Cookies, when set, makes register, login and refresh also send the
+	// tokens in HttpOnly cookies, along with a CSRF token
+	Cookies *middleware.CookieConfig
+	// CookiesOnly leaves the tokens out of response bodies, so scripts
+	// never see them
+	CookiesOnly bool
+}
+
+// csrfTokenBytes is the amount of randomness in a CSRF token
+const csrfTokenBytes = 32
+
 type userHandler struct {
 	app *fiber.App
 	// injecting service in handler
-	srv                 services.AuthService
-	tokenMaker          token.Maker
-	accessTokenDuration time.Duration
+	srv          services.AuthService
+	sessions     services.SessionService
+	roles        services.RoleService
+	orgs         services.OrganizationService
+	tokenMaker   token.Maker
+	revocations  token.RevocationStore
+	tokenConfig  TokenConfig
+	verification EmailVerificationConfig
 }
 
-func NewUserHandler(app *fiber.App, db db.Auth, tokenMaker token.Maker, accessTokenDuration time.Duration) UserHandler {
+func NewUserHandler(app *fiber.App, db db.Auth, tokenMaker token.Maker, revocations token.RevocationStore, tokenConfig TokenConfig, verification EmailVerificationConfig) UserHandler {
 	return &userHandler{
-		app:                 app,
-		srv:                 services.NewAuthenticator(db),
-		tokenMaker:          tokenMaker,
-		accessTokenDuration: accessTokenDuration,
+		app:          app,
+		srv:          services.NewAuthenticator(db),
+		sessions:     services.NewSessionManager(db, tokenConfig.RefreshTokenDuration),
+		roles:        services.NewRoleManager(db),
+		orgs:         services.NewOrganizationManager(db),
+		tokenMaker:   tokenMaker,
+		revocations:  revocations,
+		tokenConfig:  tokenConfig,
+		verification: verification,
 	}
 }
 
@@ -47,17 +87,38 @@ func (uh *userHandler) Register(ctx *fiber.Ctx) error {
 	// call register func
 	res, err := uh.srv.Register(ctx.Context(), req)
 	if err != nil {
+		if errors.Is(err, customError.ErrWeakPassword) {
+			return ctx.Status(fiber.StatusBadRequest).JSON(&fiber.Map{
+				"error": err.Error(),
+			})
+		}
 		return ctx.Status(fiber.StatusInternalServerError).JSON(&fiber.Map{
 			"error": err.Error(),
 		})
 	}
-	accessToken, err := uh.tokenMaker.CreateToken(res.UserID, req.Email, time.Minute)
+	uh.sendVerificationEmail(ctx, res.UserID, res.Name, res.Email)
+
+	// the account exists but cannot be used before the email is verified
+	if uh.verification.Policy == DenyUnverified {
+		return ctx.Status(fiber.StatusCreated).JSON(&res)
+	}
+
+	accessToken, refreshToken, err := uh.startSession(ctx, res.UserID, req.Email, res.EmailVerified)
 	if err != nil {
 		return ctx.Status(fiber.StatusInternalServerError).JSON(&fiber.Map{
-			"error": "unable to create token",
+			"error": err.Error(),
 		})
 	}
-	res.AccessToken = accessToken
+	res.CSRFToken, err = uh.setTokenCookies(ctx, accessToken, refreshToken)
+	if err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(&fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	if !uh.tokenConfig.CookiesOnly {
+		res.AccessToken = accessToken
+		res.RefreshToken = refreshToken
+	}
 
 	return ctx.Status(fiber.StatusCreated).JSON(&res)
 }
@@ -75,18 +136,114 @@ func (uh *userHandler) Login(ctx *fiber.Ctx) error {
 	// call login func
 	res, err := uh.srv.Login(ctx.Context(), req)
 	if err != nil {
+		if code := accountStatusCode(err); code != "" {
+			return accountBlocked(ctx, err, code)
+		}
 		return ctx.Status(fiber.StatusInternalServerError).JSON(&fiber.Map{
 			"error": err.Error(),
 		})
 	}
+	if !res.EmailVerified && uh.verification.Policy == DenyUnverified {
+		return emailNotVerified(ctx)
+	}
 
-	accessToken, err := uh.tokenMaker.CreateToken(res.UserID, req.Email, time.Minute*15)
+	accessToken, refreshToken, err := uh.startSession(ctx, res.UserID, req.Email, res.EmailVerified)
+	if err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(&fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	res.CSRFToken, err = uh.setTokenCookies(ctx, accessToken, refreshToken)
+	if err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(&fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	if !uh.tokenConfig.CookiesOnly {
+		res.AccessToken = accessToken
+		res.RefreshToken = refreshToken
+	}
+	return ctx.Status(fiber.StatusOK).JSON(&res)
+}
+
+// RefreshToken exchanges a refresh token for a new access token.
+//
+// The presented refresh token is rotated: it cannot be used again and a new
+// one is returned with the access token. Reusing an already rotated token
+// revokes every session of its family, forcing the user to log in again.
+func (uh *userHandler) RefreshToken(ctx *fiber.Ctx) error {
+	var req dto.RefreshTokenRequest
+	if len(ctx.Body()) > 0 {
+		err := ctx.BodyParser(&req)
+		if err != nil {
+			return ctx.Status(fiber.StatusBadRequest).JSON(&fiber.Map{
+				"error": err.Error(),
+			})
+		}
+	}
+	// browsers send the refresh token in its cookie, which must come with
+	// the CSRF token
+	if req.RefreshToken == "" && uh.tokenConfig.Cookies != nil {
+		req.RefreshToken = ctx.Cookies(uh.tokenConfig.Cookies.RefreshName)
+		if req.RefreshToken != "" && !uh.tokenConfig.Cookies.ValidCSRF(ctx) {
+			return ctx.Status(fiber.StatusForbidden).JSON(&fiber.Map{
+				"error": "invalid CSRF token",
+			})
+		}
+	}
+	if req.RefreshToken == "" {
+		return ctx.Status(fiber.StatusBadRequest).JSON(&fiber.Map{
+			"error": "refresh_token is required",
+		})
+	}
+
+	refreshToken, session, err := uh.sessions.RefreshSession(ctx.Context(), req.RefreshToken, sessionMetadata(ctx))
+	if err != nil {
+		if errors.Is(err, customError.ErrInvalidRefreshToken) ||
+			errors.Is(err, customError.ErrExpiredRefreshToken) ||
+			errors.Is(err, customError.ErrRefreshTokenReused) {
+			return ctx.Status(fiber.StatusUnauthorized).JSON(&fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		return ctx.Status(fiber.StatusInternalServerError).JSON(&fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	user, err := uh.srv.GetUserByID(ctx.Context(), session.UserID)
+	if err != nil {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(&fiber.Map{
+			"error": customError.ErrInvalidRefreshToken.Error(),
+		})
+	}
+	if err := services.AccountStatusError(user); err != nil {
+		return accountBlocked(ctx, err, accountStatusCode(err))
+	}
+	if !user.EmailVerifiedAt.Valid && uh.verification.Policy == DenyUnverified {
+		return emailNotVerified(ctx)
+	}
+	accessToken, err := uh.createAccessToken(ctx, user.ID, user.Email, user.EmailVerifiedAt.Valid, session.FamilyID, uh.activeOrganization(ctx, session))
 	if err != nil {
 		return ctx.Status(fiber.StatusInternalServerError).JSON(&fiber.Map{
 			"error": "unable to create token",
 		})
 	}
-	res.AccessToken = accessToken
+
+	res := dto.RefreshTokenResponse{
+		UserID:                user.ID,
+		RefreshTokenExpiresAt: session.ExpiresAt.Time,
+	}
+	res.CSRFToken, err = uh.setTokenCookies(ctx, accessToken, refreshToken)
+	if err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(&fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	if !uh.tokenConfig.CookiesOnly {
+		res.AccessToken = accessToken
+		res.RefreshToken = refreshToken
+	}
 	return ctx.Status(fiber.StatusOK).JSON(&res)
 }
 
@@ -110,11 +267,424 @@ func (uh *userHandler) CheckAuthUser(ctx *fiber.Ctx) error {
 			"error": err.Error(),
 		})
 	}
-	return ctx.Status(fiber.StatusOK).JSON(&dto.UserResponse{
-		UserID:    user.ID,
-		Name:      user.Name,
-		Email:     user.Email,
-		CreatedAt: user.CreatedAt.Time,
-		UpdatedAt: user.UpdatedAt.Time,
+	return ctx.Status(fiber.StatusOK).JSON(userResponse(user))
+}
+
+// UpdateProfile changes the profile fields sent in the request and leaves
+// the others untouched. The request carries the updated_at of the profile
+// the client edited; when the profile changed since, 409 is returned along
+// with the current profile so the client can merge and retry.
+func (uh *userHandler) UpdateProfile(ctx *fiber.Ctx) error {
+	payload, err := middleware.GetAuthPayload(ctx)
+	if err != nil {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(&fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	var req dto.UpdateProfileRequest
+	err = ctx.BodyParser(&req)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(&fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	if req.UpdatedAt.IsZero() {
+		return ctx.Status(fiber.StatusBadRequest).JSON(&fiber.Map{
+			"error": "updated_at is required",
+		})
+	}
+
+	user, err := uh.srv.UpdateProfile(ctx.Context(), payload.UserID, req)
+	if err != nil {
+		switch {
+		case errors.Is(err, customError.ErrInvalidName), errors.Is(err, customError.ErrNoProfileChanges):
+			return ctx.Status(fiber.StatusBadRequest).JSON(&fiber.Map{
+				"error": err.Error(),
+			})
+		case errors.Is(err, customError.ErrUserNotFound):
+			return ctx.Status(fiber.StatusNotFound).JSON(&fiber.Map{
+				"error": err.Error(),
+			})
+		case errors.Is(err, customError.ErrProfileConflict):
+			res := fiber.Map{"error": err.Error()}
+			if current, err := uh.srv.GetUserByID(ctx.Context(), payload.UserID); err == nil {
+				res["current"] = userResponse(current)
+			}
+			return ctx.Status(fiber.StatusConflict).JSON(&res)
+		}
+		return ctx.Status(fiber.StatusInternalServerError).JSON(&fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	return ctx.Status(fiber.StatusOK).JSON(userResponse(user))
+}
+
+func userResponse(user *sqlc.User) *dto.UserResponse {
+	return &dto.UserResponse{
+		UserID:        user.ID,
+		Name:          user.Name,
+		Email:         user.Email,
+		EmailVerified: user.EmailVerifiedAt.Valid,
+		Status:        user.Status,
+		CreatedAt:     user.CreatedAt.Time,
+		UpdatedAt:     user.UpdatedAt.Time,
+	}
+}
+
+// Logout revokes the presented access token and ends the refresh session it
+// was issued for, so neither can be used again.
+func (uh *userHandler) Logout(ctx *fiber.Ctx) error {
+	payload, err := middleware.GetAuthPayload(ctx)
+	if err != nil {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(&fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	err = uh.revocations.Revoke(ctx.Context(), payload.ID, payload.ExpiredAt)
+	if err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(&fiber.Map{
+			"error": "unable to revoke token",
+		})
+	}
+	if payload.SessionID != uuid.Nil {
+		err = uh.sessions.RevokeSession(ctx.Context(), pgtype.UUID{Bytes: payload.SessionID, Valid: true})
+		if err != nil {
+			return ctx.Status(fiber.StatusInternalServerError).JSON(&fiber.Map{
+				"error": err.Error(),
+			})
+		}
+	}
+
+	if uh.tokenConfig.Cookies != nil {
+		uh.tokenConfig.Cookies.Clear(ctx)
+	}
+	return ctx.Status(fiber.StatusOK).JSON(&fiber.Map{
+		"message": "logged out",
+	})
+}
+
+// LogoutAll invalidates every access token issued to the user before now,
+// including the presented one, and ends all of the user's refresh sessions.
+func (uh *userHandler) LogoutAll(ctx *fiber.Ctx) error {
+	payload, err := middleware.GetAuthPayload(ctx)
+	if err != nil {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(&fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	err = revokeUserTokens(ctx.Context(), uh.revocations, payload.UserID, time.Now(), uh.tokenConfig.AccessTokenDuration)
+	if err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(&fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	err = uh.sessions.RevokeUserSessions(ctx.Context(), payload.UserID)
+	if err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(&fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	if uh.tokenConfig.Cookies != nil {
+		uh.tokenConfig.Cookies.Clear(ctx)
+	}
+	return ctx.Status(fiber.StatusOK).JSON(&fiber.Map{
+		"message": "logged out from all sessions",
 	})
 }
+
+// ChangePassword replaces the password of the current user, who must send
+// the current one. Every token and session issued before the change stops
+// working, so a stolen password or token cannot be used anymore. With
+// keep_session the current session stays signed in and a new access token
+// is returned for it.
+func (uh *userHandler) ChangePassword(ctx *fiber.Ctx) error {
+	payload, err := middleware.GetAuthPayload(ctx)
+	if err != nil {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(&fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	var req dto.ChangePasswordRequest
+	err = ctx.BodyParser(&req)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(&fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	user, err := uh.srv.ChangePassword(ctx.Context(), payload.UserID, req.CurrentPassword, req.NewPassword)
+	if err != nil {
+		switch {
+		case errors.Is(err, customError.ErrIncorrectPassword):
+			return ctx.Status(fiber.StatusForbidden).JSON(&fiber.Map{
+				"error": err.Error(),
+			})
+		case errors.Is(err, customError.ErrWeakPassword), errors.Is(err, customError.ErrPasswordNotChanged):
+			return ctx.Status(fiber.StatusBadRequest).JSON(&fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		return ctx.Status(fiber.StatusInternalServerError).JSON(&fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	keepSession := req.KeepSession && payload.SessionID != uuid.Nil
+	res, err := uh.endSessionsBefore(ctx, payload, user, user.PasswordChangedAt.Time, keepSession)
+	if err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(&fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	res.PasswordChangedAt = user.PasswordChangedAt.Time
+	return ctx.Status(fiber.StatusOK).JSON(res)
+}
+
+// endSessionsBefore revokes every token of user issued up to t along with
+// their sessions. When keepSession is set the session of payload survives
+// and gets a new access token.
+func (uh *userHandler) endSessionsBefore(ctx *fiber.Ctx, payload *token.Payload, user *sqlc.User, t time.Time, keepSession bool) (*dto.ChangePasswordResponse, error) {
+	err := revokeUserTokens(ctx.Context(), uh.revocations, payload.UserID, t, uh.tokenConfig.AccessTokenDuration)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &dto.ChangePasswordResponse{UserID: payload.UserID}
+	if !keepSession {
+		err = uh.sessions.RevokeUserSessions(ctx.Context(), payload.UserID)
+		if err != nil {
+			return nil, err
+		}
+		if uh.tokenConfig.Cookies != nil {
+			uh.tokenConfig.Cookies.Clear(ctx)
+		}
+		return res, nil
+	}
+
+	familyID := pgtype.UUID{Bytes: payload.SessionID, Valid: true}
+	err = uh.sessions.RevokeOtherUserSessions(ctx.Context(), payload.UserID, familyID)
+	if err != nil {
+		return nil, err
+	}
+	orgID := pgtype.UUID{Bytes: payload.OrgID, Valid: payload.OrgID != uuid.Nil}
+	accessToken, err := uh.createAccessToken(ctx, user.ID, user.Email, user.EmailVerifiedAt.Valid, familyID, orgID)
+	if err != nil {
+		return nil, errors.New("unable to create token")
+	}
+	if uh.tokenConfig.Cookies != nil {
+		uh.tokenConfig.Cookies.SetAccessToken(ctx, accessToken, time.Now().Add(uh.tokenConfig.AccessTokenDuration))
+	}
+	if !uh.tokenConfig.CookiesOnly {
+		res.AccessToken = accessToken
+	}
+	return res, nil
+}
+
+// SwitchOrganization makes the current session act for another
+// organization of the user. A new access token carrying the organization is
+// returned and the presented one is revoked; refreshed tokens keep the
+// organization.
+func (uh *userHandler) SwitchOrganization(ctx *fiber.Ctx) error {
+	payload, err := middleware.GetAuthPayload(ctx)
+	if err != nil {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(&fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	var req dto.SwitchOrganizationRequest
+	err = ctx.BodyParser(&req)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(&fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	if !req.OrgID.Valid {
+		return ctx.Status(fiber.StatusBadRequest).JSON(&fiber.Map{
+			"error": "org_id is required",
+		})
+	}
+	if payload.SessionID == uuid.Nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(&fiber.Map{
+			"error": "token is not bound to a session",
+		})
+	}
+
+	familyID := pgtype.UUID{Bytes: payload.SessionID, Valid: true}
+	member, err := uh.orgs.SwitchOrganization(ctx.Context(), payload.UserID, familyID, req.OrgID)
+	if err != nil {
+		if errors.Is(err, customError.ErrNotOrganizationMember) {
+			return ctx.Status(fiber.StatusForbidden).JSON(&fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		return ctx.Status(fiber.StatusInternalServerError).JSON(&fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	// the access purpose of the presented token tells the email is verified
+	// whenever the policy restricts unverified users
+	accessToken, err := uh.createAccessToken(ctx, payload.UserID, payload.Email, payload.Purpose == token.PurposeAccess, familyID, req.OrgID)
+	if err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(&fiber.Map{
+			"error": "unable to create token",
+		})
+	}
+	err = uh.revocations.Revoke(ctx.Context(), payload.ID, payload.ExpiredAt)
+	if err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(&fiber.Map{
+			"error": "unable to revoke token",
+		})
+	}
+
+	res := dto.SwitchOrganizationResponse{
+		UserID: payload.UserID,
+		OrgID:  member.OrganizationID,
+		Role:   member.Role,
+	}
+	if uh.tokenConfig.Cookies != nil {
+		uh.tokenConfig.Cookies.SetAccessToken(ctx, accessToken, time.Now().Add(uh.tokenConfig.AccessTokenDuration))
+	}
+	if !uh.tokenConfig.CookiesOnly {
+		res.AccessToken = accessToken
+	}
+	return ctx.Status(fiber.StatusOK).JSON(&res)
+}
+
+// startSession creates a refresh session for the user and an access token
+// bound to it
+func (uh *userHandler) startSession(ctx *fiber.Ctx, userID pgtype.UUID, email string, emailVerified bool) (string, string, error) {
+	refreshToken, session, err := uh.sessions.CreateSession(ctx.Context(), userID, sessionMetadata(ctx))
+	if err != nil {
+		return "", "", errors.New("unable to create session")
+	}
+	accessToken, err := uh.createAccessToken(ctx, userID, email, emailVerified, session.FamilyID, pgtype.UUID{})
+	if err != nil {
+		return "", "", errors.New("unable to create token")
+	}
+	return accessToken, refreshToken, nil
+}
+
+// activeOrganization returns the organization the session acts for, unless
+// the user has left it since
+func (uh *userHandler) activeOrganization(ctx *fiber.Ctx, session *sqlc.Session) pgtype.UUID {
+	if !session.OrganizationID.Valid {
+		return pgtype.UUID{}
+	}
+	role, err := uh.orgs.MembershipRole(ctx.Context(), session.OrganizationID, session.UserID)
+	if err != nil || role == "" {
+		return pgtype.UUID{}
+	}
+	return session.OrganizationID
+}
+
+// createAccessToken issues an access token bound to the session family,
+// carrying the roles the user currently has and the organization, if any,
+// the session acts for.
+func (uh *userHandler) createAccessToken(ctx *fiber.Ctx, userID pgtype.UUID, email string, emailVerified bool, familyID, orgID pgtype.UUID) (string, error) {
+	roles, err := uh.roles.UserRoles(ctx.Context(), userID)
+	if err != nil {
+		return "", err
+	}
+	// the latest revocation of the user's tokens may reach into the
+	// current second, and must not cover the new token
+	revokedBefore, err := uh.revocations.UserRevocationCutoff(ctx.Context(), userID)
+	if err != nil {
+		return "", err
+	}
+	opts := []token.PayloadOption{token.WithSessionID(familyID.Bytes), token.WithPurpose(uh.tokenPurpose(emailVerified))}
+	if time.Now().Before(revokedBefore) {
+		opts = append(opts, token.WithIssuedAt(revokedBefore))
+	}
+	if len(roles) > 0 {
+		opts = append(opts, token.WithRoles(roles...))
+	}
+	if orgID.Valid {
+		opts = append(opts, token.WithOrgID(orgID.Bytes))
+	}
+	if uh.tokenConfig.Issuer != "" {
+		opts = append(opts, token.WithIssuer(uh.tokenConfig.Issuer))
+	}
+	if len(uh.tokenConfig.Audience) > 0 {
+		opts = append(opts, token.WithAudience(uh.tokenConfig.Audience...))
+	}
+	return uh.tokenMaker.CreateToken(userID, email, uh.tokenConfig.AccessTokenDuration, opts...)
+}
+
+// tokenPurpose returns what the access tokens of the user are for: under
+// RestrictUnverified, users who have not verified their email only get
+// restricted tokens
+func (uh *userHandler) tokenPurpose(emailVerified bool) token.Purpose {
+	if !emailVerified && uh.verification.Policy == RestrictUnverified {
+		return token.PurposeRestricted
+	}
+	return token.PurposeAccess
+}
+
+// sendVerificationEmail emails a verification link in the background
+func (uh *userHandler) sendVerificationEmail(ctx *fiber.Ctx, userID pgtype.UUID, name, email string) {
+	enqueueMail(uh.verification.Queue, "email verification", email, ctx.IP(), func(bgCtx context.Context) {
+		sendVerificationEmail(bgCtx, uh.tokenMaker, uh.verification, userID, name, email)
+	})
+}
+
+// emailNotVerified refuses users who must verify their email first, with the
+// code the authentication middleware uses for restricted tokens
+func emailNotVerified(ctx *fiber.Ctx) error {
+	return ctx.Status(fiber.StatusForbidden).JSON(&fiber.Map{
+		"error": customError.ErrEmailNotVerified.Error(),
+		"code":  middleware.CodeEmailNotVerified,
+	})
+}
+
+// accountStatusCode returns the code the authentication middleware sends
+// for an account blocked with err, or "" when err does not block accounts
+func accountStatusCode(err error) string {
+	switch {
+	case errors.Is(err, customError.ErrAccountSuspended):
+		return middleware.CodeAccountSuspended
+	case errors.Is(err, customError.ErrAccountLocked):
+		return middleware.CodeAccountLocked
+	case errors.Is(err, customError.ErrAccountDeleted):
+		return middleware.CodeAccountDeleted
+	}
+	return ""
+}
+
+// accountBlocked refuses users whose account status keeps them from
+// signing in
+func accountBlocked(ctx *fiber.Ctx, err error, code string) error {
+	return ctx.Status(fiber.StatusForbidden).JSON(&fiber.Map{
+		"error": err.Error(),
+		"code":  code,
+	})
+}
+
+// setTokenCookies sends the tokens in cookies when cookie transport is
+// enabled, along with a new CSRF token that is also returned
+func (uh *userHandler) setTokenCookies(ctx *fiber.Ctx, accessToken, refreshToken string) (string, error) {
+	cookies := uh.tokenConfig.Cookies
+	if cookies == nil {
+		return "", nil
+	}
+
+	csrfToken, err := utils.GenerateSecureToken(csrfTokenBytes)
+	if err != nil {
+		return "", errors.New("unable to create CSRF token")
+	}
+	now := time.Now()
+	refreshExpiresAt := now.Add(uh.tokenConfig.RefreshTokenDuration)
+	cookies.SetAccessToken(ctx, accessToken, now.Add(uh.tokenConfig.AccessTokenDuration))
+	cookies.SetRefreshToken(ctx, refreshToken, refreshExpiresAt)
+	cookies.SetCSRFToken(ctx, csrfToken, refreshExpiresAt)
+	return csrfToken, nil
+}
+
+func sessionMetadata(ctx *fiber.Ctx) dto.SessionMetadata {
+	return dto.SessionMetadata{
+		UserAgent: ctx.Get(fiber.HeaderUserAgent),
+		ClientIP:  ctx.IP(),
+	}
+}
@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"errors"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	customError "github.com/suryansh74/auth-package/internal/apperrors"
+	"github.com/suryansh74/auth-package/internal/db"
+	"github.com/suryansh74/auth-package/internal/db/sqlc"
+	"github.com/suryansh74/auth-package/internal/dto"
+	"github.com/suryansh74/auth-package/internal/services"
+	"github.com/suryansh74/auth-package/token"
+)
+
+type UserStatusHandler interface {
+	UpdateUserStatus(ctx *fiber.Ctx) error
+}
+
+type userStatusHandler struct {
+	statuses    services.UserStatusService
+	sessions    services.SessionService
+	revocations token.RevocationStore
+	// accessTokenDuration is the lifetime of access tokens
+	accessTokenDuration time.Duration
+}
+
+func NewUserStatusHandler(db db.Auth, revocations token.RevocationStore, accessTokenDuration time.Duration) UserStatusHandler {
+	return &userStatusHandler{
+		statuses:            services.NewUserStatusManager(db),
+		sessions:            services.NewSessionManager(db, 0),
+		revocations:         revocations,
+		accessTokenDuration: accessTokenDuration,
+	}
+}
+
+// UpdateUserStatus suspends, locks or reinstates the user of the id
+// parameter. Blocked users are refused by the authentication middleware at
+// once; locking also revokes their tokens and sessions, as the account is
+// assumed to be compromised.
+func (sh *userStatusHandler) UpdateUserStatus(ctx *fiber.Ctx) error {
+	userID, err := uuidParam(ctx, "id")
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(&fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	var req dto.UpdateUserStatusRequest
+	err = ctx.BodyParser(&req)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(&fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	user, err := sh.statuses.UpdateUserStatus(ctx.Context(), userID, req.Status, req.Reason, req.SuspendedUntil)
+	if err != nil {
+		switch {
+		case errors.Is(err, customError.ErrInvalidUserStatus),
+			errors.Is(err, customError.ErrInvalidStatusReason),
+			errors.Is(err, customError.ErrInvalidSuspensionEnd):
+			return ctx.Status(fiber.StatusBadRequest).JSON(&fiber.Map{
+				"error": err.Error(),
+			})
+		case errors.Is(err, customError.ErrUserNotFound):
+			return ctx.Status(fiber.StatusNotFound).JSON(&fiber.Map{
+				"error": err.Error(),
+			})
+		case errors.Is(err, customError.ErrInvalidStatusTransition),
+			errors.Is(err, customError.ErrUserStatusConflict):
+			return ctx.Status(fiber.StatusConflict).JSON(&fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		return ctx.Status(fiber.StatusInternalServerError).JSON(&fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	if user.Status == services.UserStatusLocked {
+		err = revokeUserTokens(ctx.Context(), sh.revocations, user.ID, time.Now(), sh.accessTokenDuration)
+		if err != nil {
+			return ctx.Status(fiber.StatusInternalServerError).JSON(&fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		err = sh.sessions.RevokeUserSessions(ctx.Context(), user.ID)
+		if err != nil {
+			return ctx.Status(fiber.StatusInternalServerError).JSON(&fiber.Map{
+				"error": err.Error(),
+			})
+		}
+	}
+	return ctx.Status(fiber.StatusOK).JSON(userStatusResponse(user))
+}
+
+func userStatusResponse(user *sqlc.User) dto.UserStatusResponse {
+	res := dto.UserStatusResponse{
+		UserID:          user.ID,
+		Status:          user.Status,
+		Reason:          user.StatusReason.String,
+		StatusChangedAt: user.StatusChangedAt.Time,
+	}
+	if user.SuspendedUntil.Valid {
+		res.SuspendedUntil = &user.SuspendedUntil.Time
+	}
+	return res
+}
@@ -0,0 +1,159 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/jackc/pgx/v5/pgtype"
+	customError "github.com/suryansh74/auth-package/internal/apperrors"
+	"github.com/suryansh74/auth-package/internal/db"
+	"github.com/suryansh74/auth-package/internal/dto"
+	"github.com/suryansh74/auth-package/internal/services"
+	"github.com/suryansh74/auth-package/mailer"
+	"github.com/suryansh74/auth-package/token"
+)
+
+// UnverifiedLoginPolicy decides what users who have not verified their
+// email get when they register, log in or refresh their tokens
+type UnverifiedLoginPolicy string
+
+const (
+	// AllowUnverified issues them the same tokens as verified users
+	AllowUnverified UnverifiedLoginPolicy = "allow"
+	// RestrictUnverified issues them restricted tokens, which only routes
+	// allowing restricted tokens accept
+	RestrictUnverified UnverifiedLoginPolicy = "restrict"
+	// DenyUnverified refuses to log them in until they verify their email
+	DenyUnverified UnverifiedLoginPolicy = "deny"
+)
+
+// EmailVerificationConfig holds the settings of email verification
+type EmailVerificationConfig struct {
+	Mailer mailer.Mailer
+	// TokenDuration is how long a verification link can be used
+	TokenDuration time.Duration
+	// URL is the page of the client that confirms the email; the token is
+	// added as the token query parameter
+	URL string
+	// Issuer is set on verification tokens and expected back, see
+	// TokenConfig.Issuer
+	Issuer string
+	Policy UnverifiedLoginPolicy
+	// Queue sends the verification emails
+	Queue *MailQueue
+}
+
+type VerificationHandler interface {
+	VerifyEmail(ctx *fiber.Ctx) error
+	ResendVerification(ctx *fiber.Ctx) error
+}
+
+type verificationHandler struct {
+	srv        services.AuthService
+	tokenMaker token.Maker
+	config     EmailVerificationConfig
+}
+
+func NewVerificationHandler(db db.Auth, tokenMaker token.Maker, config EmailVerificationConfig) VerificationHandler {
+	return &verificationHandler{
+		srv:        services.NewAuthenticator(db),
+		tokenMaker: tokenMaker,
+		config:     config,
+	}
+}
+
+// VerifyEmail confirms the email of a user with the token of a verification
+// link, read from the token query parameter or the request body. Tokens
+// issued afterwards are no longer restricted.
+func (vh *verificationHandler) VerifyEmail(ctx *fiber.Ctx) error {
+	verificationToken, err := linkToken(ctx)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(&fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	opts := []token.VerifyOption{token.ExpectPurpose(token.PurposeEmailVerification)}
+	if vh.config.Issuer != "" {
+		opts = append(opts, token.ExpectIssuer(vh.config.Issuer))
+	}
+	payload, err := vh.tokenMaker.VerifyToken(verificationToken, opts...)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(&fiber.Map{
+			"error": customError.ErrInvalidVerificationToken.Error(),
+		})
+	}
+
+	user, err := vh.srv.VerifyEmail(ctx.Context(), payload.UserID, payload.Email)
+	if err != nil {
+		if errors.Is(err, customError.ErrInvalidVerificationToken) {
+			return ctx.Status(fiber.StatusBadRequest).JSON(&fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		return ctx.Status(fiber.StatusInternalServerError).JSON(&fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	return ctx.Status(fiber.StatusOK).JSON(&fiber.Map{
+		"user_id":           user.ID,
+		"email":             user.Email,
+		"email_verified_at": user.EmailVerifiedAt.Time,
+	})
+}
+
+// ResendVerification emails a new verification link to the given address
+// when it belongs to a user who has not verified it yet. Like forgot
+// password, it answers the same whatever the address, as it is open to
+// users who cannot log in before verifying, and is limited by the mail
+// queue.
+func (vh *verificationHandler) ResendVerification(ctx *fiber.Ctx) error {
+	var req dto.ResendVerificationRequest
+	err := ctx.BodyParser(&req)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(&fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	res := &fiber.Map{
+		"message": "if this email awaits verification, a new link has been sent",
+	}
+	if req.Email == "" {
+		return ctx.Status(fiber.StatusOK).JSON(res)
+	}
+
+	email := req.Email
+	err = vh.config.Queue.Enqueue(email, ctx.IP(), func(bgCtx context.Context) {
+		user, err := vh.srv.GetUserByEmail(bgCtx, email)
+		if err != nil || user.EmailVerifiedAt.Valid {
+			return
+		}
+		sendVerificationEmail(bgCtx, vh.tokenMaker, vh.config, user.ID, user.Name, user.Email)
+	})
+	if err != nil {
+		return mailRefused(ctx, err)
+	}
+	return ctx.Status(fiber.StatusOK).JSON(res)
+}
+
+// sendVerificationEmail emails a link proving that the user owns email.
+// Failures are logged, the user can ask for another link.
+func sendVerificationEmail(ctx context.Context, tokenMaker token.Maker, config EmailVerificationConfig, userID pgtype.UUID, name, email string) {
+	opts := []token.PayloadOption{token.WithPurpose(token.PurposeEmailVerification)}
+	if config.Issuer != "" {
+		opts = append(opts, token.WithIssuer(config.Issuer))
+	}
+	verificationToken, err := tokenMaker.CreateToken(userID, email, config.TokenDuration, opts...)
+	if err != nil {
+		log.Printf("email verification: %v", err)
+		return
+	}
+	msg := verificationEmail(email, name, linkWithToken(config.URL, verificationToken))
+	if err := config.Mailer.Send(ctx, msg); err != nil {
+		log.Printf("email verification: cannot send email: %v", err)
+	}
+}
@@ -0,0 +1,155 @@
+package middleware
+
+import (
+	"context"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// RoleStore loads the current roles and permissions of a user. When an
+// Authorizer has one, it is authoritative: roles granted or revoked after a
+// token was issued take effect immediately.
+type RoleStore interface {
+	UserRoles(ctx context.Context, userID pgtype.UUID) ([]string, error)
+	UserPermissions(ctx context.Context, userID pgtype.UUID) ([]string, error)
+}
+
+// Authorizer builds handlers that check the user authenticated by
+// AuthMiddleware, which must run before them. Failed checks respond 403
+// with the error code and the requirement that was not met.
+type Authorizer struct {
+	store RoleStore
+}
+
+// NewAuthorizer creates an Authorizer. Without a store, roles are read from
+// the token and permissions cannot be checked.
+func NewAuthorizer(store RoleStore) *Authorizer {
+	return &Authorizer{store: store}
+}
+
+// RequireRoles lets the request through when the user has at least one of
+// roles. It panics when roles is empty, so the mistake shows at startup.
+func (a *Authorizer) RequireRoles(roles ...string) fiber.Handler {
+	requireNonEmpty("RequireRoles", roles)
+	return func(c *fiber.Ctx) error {
+		payload, err := GetAuthPayload(c)
+		if err != nil {
+			return tokenError(c, errMissingToken)
+		}
+
+		granted := payload.Roles
+		if a.store != nil {
+			granted, err = a.store.UserRoles(c.Context(), payload.UserID)
+			if err != nil {
+				return authError(c, fiber.StatusInternalServerError, CodeAuthorizationFailed, "Unable to check roles")
+			}
+		}
+		if !containsAny(granted, roles) {
+			return forbidden(c, CodeInsufficientRole, "Insufficient role", roles)
+		}
+		return c.Next()
+	}
+}
+
+// RequirePermissions lets the request through when the user has every one
+// of permissions. Permissions are loaded from the RoleStore. It panics when
+// permissions is empty.
+func (a *Authorizer) RequirePermissions(permissions ...string) fiber.Handler {
+	requireNonEmpty("RequirePermissions", permissions)
+	return func(c *fiber.Ctx) error {
+		payload, err := GetAuthPayload(c)
+		if err != nil {
+			return tokenError(c, errMissingToken)
+		}
+		if a.store == nil {
+			return authError(c, fiber.StatusInternalServerError, CodeAuthorizationFailed, "Permissions are not configured")
+		}
+
+		granted, err := a.store.UserPermissions(c.Context(), payload.UserID)
+		if err != nil {
+			return authError(c, fiber.StatusInternalServerError, CodeAuthorizationFailed, "Unable to check permissions")
+		}
+		if !containsAll(granted, permissions) {
+			return forbidden(c, CodeInsufficientPermission, "Insufficient permission", permissions)
+		}
+		return c.Next()
+	}
+}
+
+// RequireScopes lets the request through when the token was granted every
+// one of scopes
+func (a *Authorizer) RequireScopes(scopes ...string) fiber.Handler {
+	return RequireScopes(scopes...)
+}
+
+// RequireRoles checks roles against the claims of the token, see
+// Authorizer.RequireRoles
+func RequireRoles(roles ...string) fiber.Handler {
+	return NewAuthorizer(nil).RequireRoles(roles...)
+}
+
+// RequireScopes lets the request through when the token was granted every
+// one of scopes. It panics when scopes is empty.
+func RequireScopes(scopes ...string) fiber.Handler {
+	requireNonEmpty("RequireScopes", scopes)
+	return func(c *fiber.Ctx) error {
+		payload, err := GetAuthPayload(c)
+		if err != nil {
+			return tokenError(c, errMissingToken)
+		}
+		if !containsAll(payload.Scopes, scopes) {
+			return forbidden(c, CodeInsufficientScope, "Insufficient scope", scopes)
+		}
+		return c.Next()
+	}
+}
+
+// requireNonEmpty refuses to build a check without requirements, which
+// would otherwise deny everyone for roles and allow everyone for
+// permissions and scopes
+func requireNonEmpty(name string, required []string) {
+	if len(required) == 0 {
+		panic("middleware: " + name + " needs at least one requirement")
+	}
+	for _, value := range required {
+		if value == "" {
+			panic("middleware: " + name + " requirements must not be empty")
+		}
+	}
+}
+
+func forbidden(c *fiber.Ctx, code, message string, required []string) error {
+	return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+		"error":    message,
+		"code":     code,
+		"required": required,
+	})
+}
+
+func containsAny(granted, required []string) bool {
+	for _, value := range required {
+		if contains(granted, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsAll(granted, required []string) bool {
+	for _, value := range required {
+		if !contains(granted, value) {
+			return false
+		}
+	}
+	return true
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,85 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// CSRFHeader carries the CSRF token on state-changing requests that are
+// authenticated by cookie
+const CSRFHeader = "X-CSRF-Token"
+
+// CookieConfig describes the cookies tokens are sent in. Access and refresh
+// tokens are HttpOnly so scripts cannot read them. The CSRF token is not:
+// the client reads it and echoes it in the X-CSRF-Token header
+// (double-submit), which a cross-site request cannot do.
+type CookieConfig struct {
+	AccessName  string
+	RefreshName string
+	CSRFName    string
+	Domain      string
+	Path        string
+	// RefreshPath limits the refresh cookie to the routes that use it
+	RefreshPath string
+	SameSite    string
+}
+
+// SetAccessToken stores the access token in its cookie
+func (cfg CookieConfig) SetAccessToken(c *fiber.Ctx, accessToken string, expires time.Time) {
+	c.Cookie(cfg.cookie(cfg.AccessName, accessToken, cfg.Path, expires, true))
+}
+
+// SetRefreshToken stores the refresh token in its cookie
+func (cfg CookieConfig) SetRefreshToken(c *fiber.Ctx, refreshToken string, expires time.Time) {
+	c.Cookie(cfg.cookie(cfg.RefreshName, refreshToken, cfg.RefreshPath, expires, true))
+}
+
+// SetCSRFToken stores the CSRF token in a cookie readable by scripts
+func (cfg CookieConfig) SetCSRFToken(c *fiber.Ctx, csrfToken string, expires time.Time) {
+	c.Cookie(cfg.cookie(cfg.CSRFName, csrfToken, cfg.Path, expires, false))
+}
+
+// Clear expires every token cookie
+func (cfg CookieConfig) Clear(c *fiber.Ctx) {
+	expired := time.Unix(0, 0)
+	c.Cookie(cfg.cookie(cfg.AccessName, "", cfg.Path, expired, true))
+	c.Cookie(cfg.cookie(cfg.RefreshName, "", cfg.RefreshPath, expired, true))
+	c.Cookie(cfg.cookie(cfg.CSRFName, "", cfg.Path, expired, false))
+}
+
+// ValidCSRF reports whether the X-CSRF-Token header matches the CSRF cookie.
+// Safe methods do not change state and need no CSRF token.
+func (cfg CookieConfig) ValidCSRF(c *fiber.Ctx) bool {
+	if isSafeMethod(c.Method()) {
+		return true
+	}
+	cookie := c.Cookies(cfg.CSRFName)
+	header := c.Get(CSRFHeader)
+	if cookie == "" || header == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(cookie), []byte(header)) == 1
+}
+
+func (cfg CookieConfig) cookie(name, value, path string, expires time.Time, httpOnly bool) *fiber.Cookie {
+	return &fiber.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     path,
+		Domain:   cfg.Domain,
+		Expires:  expires,
+		Secure:   true,
+		HTTPOnly: httpOnly,
+		SameSite: cfg.SameSite,
+	}
+}
+
+func isSafeMethod(method string) bool {
+	switch method {
+	case fiber.MethodGet, fiber.MethodHead, fiber.MethodOptions, fiber.MethodTrace:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,97 @@
+package middleware
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	customError "github.com/suryansh74/auth-package/internal/apperrors"
+	"github.com/suryansh74/auth-package/token"
+)
+
+// Error codes sent in the "code" field of authentication failures. They are
+// part of the API and must not change.
+const (
+	CodeMissingToken      = "missing_token"
+	CodeInvalidAuthHeader = "invalid_authorization_header"
+	CodeMalformedToken    = "token_malformed"
+	CodeInvalidSignature  = "token_invalid_signature"
+	CodeExpiredToken      = "token_expired"
+	CodeTokenNotYetValid  = "token_not_yet_valid"
+	CodeRevokedToken      = "token_revoked"
+	CodeInvalidPurpose    = "token_invalid_purpose"
+	CodeInvalidIssuer     = "token_invalid_issuer"
+	CodeInvalidAudience   = "token_invalid_audience"
+	CodeInsufficientScope = "token_insufficient_scope"
+	CodeInvalidToken      = "token_invalid"
+	CodeInvalidCSRF       = "csrf_token_invalid"
+	CodeTokenCheckFailed  = "token_check_failed"
+	CodeEmailNotVerified  = "email_not_verified"
+
+	CodeAccountSuspended   = "account_suspended"
+	CodeAccountLocked      = "account_locked"
+	CodeAccountDeleted     = "account_deleted"
+	CodeAccountCheckFailed = "account_status_check_failed"
+
+	CodeInsufficientRole       = "insufficient_role"
+	CodeInsufficientPermission = "insufficient_permission"
+	CodeAuthorizationFailed    = "authorization_check_failed"
+
+	CodeTenantRequired         = "tenant_required"
+	CodeTenantAccessDenied     = "tenant_access_denied"
+	CodeInsufficientTenantRole = "insufficient_tenant_role"
+)
+
+var (
+	errMissingToken       = errors.New("missing access token")
+	errTokenCheckFailed   = errors.New("unable to check token revocation")
+	errRestrictedToken    = errors.New("restricted token used where access token is required")
+	errAccountCheckFailed = errors.New("unable to check account status")
+)
+
+type tokenErrorResponse struct {
+	err     error
+	status  int
+	code    string
+	message string
+}
+
+// tokenErrorResponses is checked in order, the first match wins
+var tokenErrorResponses = []tokenErrorResponse{
+	{errMissingToken, fiber.StatusUnauthorized, CodeMissingToken, "Missing access token"},
+	{errTokenCheckFailed, fiber.StatusInternalServerError, CodeTokenCheckFailed, "Unable to verify token"},
+	{ErrInvalidAuthorizationHeader, fiber.StatusUnauthorized, CodeInvalidAuthHeader, "Invalid authorization format"},
+	{ErrInvalidCSRFToken, fiber.StatusForbidden, CodeInvalidCSRF, "Invalid CSRF token"},
+	{errRestrictedToken, fiber.StatusForbidden, CodeEmailNotVerified, "Email address must be verified"},
+	{errAccountCheckFailed, fiber.StatusInternalServerError, CodeAccountCheckFailed, "Unable to verify account status"},
+	{customError.ErrAccountSuspended, fiber.StatusForbidden, CodeAccountSuspended, "Account suspended"},
+	{customError.ErrAccountLocked, fiber.StatusForbidden, CodeAccountLocked, "Account locked, reset the password to unlock it"},
+	{customError.ErrAccountDeleted, fiber.StatusForbidden, CodeAccountDeleted, "Account deleted"},
+	{token.ErrExpiredToken, fiber.StatusUnauthorized, CodeExpiredToken, "Token expired"},
+	{token.ErrTokenNotYetValid, fiber.StatusUnauthorized, CodeTokenNotYetValid, "Token not valid yet"},
+	{token.ErrRevokedToken, fiber.StatusUnauthorized, CodeRevokedToken, "Token revoked"},
+	{token.ErrMalformedToken, fiber.StatusUnauthorized, CodeMalformedToken, "Malformed token"},
+	{token.ErrInvalidSignature, fiber.StatusUnauthorized, CodeInvalidSignature, "Invalid token signature"},
+	{token.ErrInvalidPurpose, fiber.StatusUnauthorized, CodeInvalidPurpose, "Token cannot be used for this request"},
+	{token.ErrInvalidIssuer, fiber.StatusUnauthorized, CodeInvalidIssuer, "Invalid token issuer"},
+	{token.ErrInvalidAudience, fiber.StatusUnauthorized, CodeInvalidAudience, "Invalid token audience"},
+	{token.ErrInsufficientScope, fiber.StatusForbidden, CodeInsufficientScope, "Insufficient scope"},
+}
+
+// tokenError writes the response for a request whose token was not
+// accepted. The error itself is never sent, so no internal detail leaks to
+// clients.
+func tokenError(c *fiber.Ctx, err error) error {
+	for _, resp := range tokenErrorResponses {
+		if errors.Is(err, resp.err) {
+			return authError(c, resp.status, resp.code, resp.message)
+		}
+	}
+	return authError(c, fiber.StatusUnauthorized, CodeInvalidToken, "Invalid token")
+}
+
+func authError(c *fiber.Ctx, status int, code, message string) error {
+	return c.Status(status).JSON(fiber.Map{
+		"error": message,
+		"code":  code,
+	})
+}
@@ -0,0 +1,81 @@
+package middleware
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+var (
+	ErrInvalidAuthorizationHeader = errors.New("invalid authorization header")
+	ErrInvalidCSRFToken           = errors.New("invalid CSRF token")
+)
+
+// Extractor finds the access token in a request. It returns an empty
+// string when the token is not where it looks, so the next extractor of the
+// chain is tried, and an error when the token is there but cannot be used.
+type Extractor func(c *fiber.Ctx) (string, error)
+
+// FromHeader reads the token from header. When scheme is set the header
+// must be "<scheme> <token>", with the scheme matched case-insensitively as
+// RFC 6750 requires; otherwise the whole header value is the token.
+func FromHeader(header, scheme string) Extractor {
+	return func(c *fiber.Ctx) (string, error) {
+		value := c.Get(header)
+		if value == "" || scheme == "" {
+			return value, nil
+		}
+
+		prefix, credentials, found := strings.Cut(value, " ")
+		if !found || !strings.EqualFold(prefix, scheme) {
+			return "", ErrInvalidAuthorizationHeader
+		}
+		credentials = strings.TrimSpace(credentials)
+		if credentials == "" {
+			return "", ErrInvalidAuthorizationHeader
+		}
+		return credentials, nil
+	}
+}
+
+// FromAuthHeader reads the token from "Authorization: Bearer <token>"
+func FromAuthHeader() Extractor {
+	return FromHeader(fiber.HeaderAuthorization, "Bearer")
+}
+
+// FromQuery reads the token from a query parameter, for clients that cannot
+// set headers such as WebSocket handshakes and download links. URLs end up
+// in logs and browser history, so only use it on routes that need it.
+func FromQuery(param string) Extractor {
+	return func(c *fiber.Ctx) (string, error) {
+		return c.Query(param), nil
+	}
+}
+
+// FromCookie reads the token from the access cookie of cfg and rejects
+// state-changing requests without a valid CSRF token. Browsers attach
+// cookies to cross-site requests, so there is no unprotected variant.
+func FromCookie(cfg CookieConfig) Extractor {
+	return func(c *fiber.Ctx) (string, error) {
+		accessToken := c.Cookies(cfg.AccessName)
+		if accessToken == "" {
+			return "", nil
+		}
+		if !cfg.ValidCSRF(c) {
+			return "", ErrInvalidCSRFToken
+		}
+		return accessToken, nil
+	}
+}
+
+// extractToken tries extractors in order and returns the first token found
+func extractToken(c *fiber.Ctx, extractors []Extractor) (string, error) {
+	for _, extract := range extractors {
+		accessToken, err := extract(c)
+		if err != nil || accessToken != "" {
+			return accessToken, err
+		}
+	}
+	return "", nil
+}
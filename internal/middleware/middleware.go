@@ -1,50 +1,108 @@
 package middleware
 
 import (
+	"context"
 	"errors"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/jackc/pgx/v5/pgtype"
+	customError "github.com/suryansh74/auth-package/internal/apperrors"
 	"github.com/suryansh74/auth-package/token"
 )
 
 const (
 	AuthorizationPayloadKey = "authorization_payload"
+	TenantKey               = "tenant"
 )
 
-func AuthMiddleware(maker token.Maker) fiber.Handler {
-	return func(c *fiber.Ctx) error {
-		authHeader := c.Get("Authorization")
+// Option configures AuthMiddleware and OptionalAuthMiddleware
+type Option func(*options)
 
-		if authHeader == "" {
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"error": "Missing authorization header",
-			})
-		}
+type options struct {
+	revocations   token.RevocationStore
+	verifyOptions []token.VerifyOption
+	extractors    []Extractor
+	cookie        *CookieConfig
+	restricted    bool
+	statuses      AccountStatusStore
 
-		// Expecting: Bearer <token>
-		if len(authHeader) < 7 || authHeader[:7] != "Bearer " {
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"error": "Invalid authorization format",
-			})
-		}
+	invalidTokenPolicy InvalidTokenPolicy
+}
 
-		// Extract token string
-		accessToken := authHeader[7:]
+// AccountStatusStore tells whether the account of a user may still be used.
+// CheckAccountStatus returns nil for usable accounts, and
+// ErrAccountSuspended, ErrAccountLocked or ErrAccountDeleted of apperrors
+// for blocked ones.
+type AccountStatusStore interface {
+	CheckAccountStatus(ctx context.Context, userID pgtype.UUID) error
+}
 
-		// Validate token
-		payload, err := maker.VerifyToken(accessToken)
-		if err != nil {
-			if err == token.ErrExpiredToken {
-				return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-					"error":   "Token expired",
-					"details": "login again",
-				})
-			}
+// WithRevocationStore makes the middleware reject tokens revoked in store
+func WithRevocationStore(store token.RevocationStore) Option {
+	return func(o *options) {
+		o.revocations = store
+	}
+}
+
+// WithExtractors sets where the token is looked for, in order. It replaces
+// the default, which is the Authorization header with the Bearer scheme.
+func WithExtractors(extractors ...Extractor) Option {
+	return func(o *options) {
+		o.extractors = append(o.extractors, extractors...)
+	}
+}
+
+// WithCookie also accepts the access token from the cookie described by cfg
+// when no extractor finds one. State-changing requests authenticated this
+// way must carry a valid CSRF token.
+func WithCookie(cfg CookieConfig) Option {
+	return func(o *options) {
+		o.cookie = &cfg
+	}
+}
+
+// WithClock verifies token times against clock instead of the verifier's
+// own clock, mostly useful in tests
+func WithClock(clock token.Clock) Option {
+	return func(o *options) {
+		o.verifyOptions = append(o.verifyOptions, token.VerifyWithClock(clock))
+	}
+}
 
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"error":   "Incorrect token",
-				"details": err.Error(),
-			})
+// WithVerifyOptions adds claim checks, such as the expected audience, to
+// token verification
+func WithVerifyOptions(opts ...token.VerifyOption) Option {
+	return func(o *options) {
+		o.verifyOptions = append(o.verifyOptions, opts...)
+	}
+}
+
+// WithAccountStatusStore makes the middleware reject tokens of accounts
+// blocked after the tokens were issued, at the cost of a lookup per request
+func WithAccountStatusStore(store AccountStatusStore) Option {
+	return func(o *options) {
+		o.statuses = store
+	}
+}
+
+// WithRestrictedTokens also accepts restricted tokens, which are given to
+// users who have not verified their email yet. Handlers tell them apart by
+// the purpose of the payload.
+func WithRestrictedTokens() Option {
+	return func(o *options) {
+		o.restricted = true
+	}
+}
+
+// AuthMiddleware rejects requests without a valid access token and stores
+// the payload of the token for GetAuthPayload
+func AuthMiddleware(verifier token.Verifier, opts ...Option) fiber.Handler {
+	auth := newAuthenticator(verifier, opts)
+
+	return func(c *fiber.Ctx) error {
+		payload, err := auth.authenticate(c)
+		if err != nil {
+			return tokenError(c, err)
 		}
 
 		// Save payload for further handlers
@@ -54,6 +112,111 @@ func AuthMiddleware(maker token.Maker) fiber.Handler {
 	}
 }
 
+type authenticator struct {
+	verifier      token.Verifier
+	revocations   token.RevocationStore
+	verifyOptions []token.VerifyOption
+	extractors    []Extractor
+	statuses      AccountStatusStore
+
+	invalidTokenPolicy InvalidTokenPolicy
+}
+
+func newAuthenticator(verifier token.Verifier, opts []Option) *authenticator {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	extractors := o.extractors
+	if len(extractors) == 0 {
+		extractors = []Extractor{FromAuthHeader()}
+	}
+	if o.cookie != nil {
+		extractors = append(extractors, FromCookie(*o.cookie))
+	}
+
+	// only access tokens authenticate requests, whatever the options say,
+	// along with restricted ones where they are allowed
+	purposes := []token.Purpose{token.PurposeAccess}
+	if o.restricted {
+		purposes = append(purposes, token.PurposeRestricted)
+	}
+
+	return &authenticator{
+		verifier:      verifier,
+		revocations:   o.revocations,
+		verifyOptions: append(o.verifyOptions, token.ExpectPurpose(purposes...)),
+		extractors:    extractors,
+		statuses:      o.statuses,
+
+		invalidTokenPolicy: o.invalidTokenPolicy,
+	}
+}
+
+// authenticate returns the payload of the access token of the request.
+// It returns errMissingToken when the request carries no token.
+func (a *authenticator) authenticate(c *fiber.Ctx) (*token.Payload, error) {
+	accessToken, err := extractToken(c, a.extractors)
+	if err != nil {
+		return nil, err
+	}
+	if accessToken == "" {
+		return nil, errMissingToken
+	}
+
+	payload, err := a.verifier.VerifyToken(accessToken, a.requestVerifyOptions(c)...)
+	if err != nil {
+		// tell users who have not verified their email why they are refused
+		if errors.Is(err, token.ErrInvalidPurpose) && a.isRestricted(c, accessToken) {
+			return nil, errRestrictedToken
+		}
+		return nil, err
+	}
+
+	// Reject tokens of blocked accounts, before revocations so the reason
+	// is told even when their tokens were revoked too
+	if a.statuses != nil {
+		if err := a.statuses.CheckAccountStatus(c.Context(), payload.UserID); err != nil {
+			if blocksAccount(err) {
+				return nil, err
+			}
+			return nil, errAccountCheckFailed
+		}
+	}
+
+	// Reject tokens revoked before their expiry
+	if a.revocations != nil {
+		revoked, err := a.revocations.IsRevoked(c.Context(), payload)
+		if err != nil {
+			return nil, errTokenCheckFailed
+		}
+		if revoked {
+			return nil, token.ErrRevokedToken
+		}
+	}
+	return payload, nil
+}
+
+// isRestricted tells whether accessToken is a valid restricted token
+func (a *authenticator) isRestricted(c *fiber.Ctx, accessToken string) bool {
+	opts := append(a.requestVerifyOptions(c), token.ExpectPurpose(token.PurposeRestricted))
+	_, err := a.verifier.VerifyToken(accessToken, opts...)
+	return err == nil
+}
+
+// requestVerifyOptions returns the verify options bound to the context of
+// the request, so verifiers fetching keys stop with it
+func (a *authenticator) requestVerifyOptions(c *fiber.Ctx) []token.VerifyOption {
+	return append(a.verifyOptions[:len(a.verifyOptions):len(a.verifyOptions)], token.VerifyWithContext(c.Context()))
+}
+
+func blocksAccount(err error) bool {
+	return errors.Is(err, customError.ErrAccountSuspended) ||
+		errors.Is(err, customError.ErrAccountLocked) ||
+		errors.Is(err, customError.ErrAccountDeleted)
+}
+
 // GetAuthPayload retrieves the authenticated user's payload from context
 func GetAuthPayload(c *fiber.Ctx) (*token.Payload, error) {
 	value := c.Locals(AuthorizationPayloadKey)
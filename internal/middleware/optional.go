@@ -0,0 +1,55 @@
+package middleware
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/suryansh74/auth-package/token"
+)
+
+// InvalidTokenPolicy decides what OptionalAuthMiddleware does with a
+// request that carries a token which is not accepted
+type InvalidTokenPolicy string
+
+const (
+	// IgnoreInvalidToken serves the request anonymously
+	IgnoreInvalidToken InvalidTokenPolicy = "ignore"
+	// RejectExpiredToken responds 401 to expired tokens, so clients know to
+	// refresh them, and serves any other invalid token anonymously
+	RejectExpiredToken InvalidTokenPolicy = "reject-expired"
+	// RejectInvalidToken responds to every invalid token like
+	// AuthMiddleware does
+	RejectInvalidToken InvalidTokenPolicy = "reject"
+)
+
+// WithInvalidTokenPolicy sets the policy of OptionalAuthMiddleware,
+// IgnoreInvalidToken by default
+func WithInvalidTokenPolicy(policy InvalidTokenPolicy) Option {
+	return func(o *options) {
+		o.invalidTokenPolicy = policy
+	}
+}
+
+// OptionalAuthMiddleware lets anonymous requests through. When the request
+// carries a valid access token its payload is stored for GetAuthPayload,
+// as AuthMiddleware does; a token that is not accepted is handled by the
+// InvalidTokenPolicy.
+func OptionalAuthMiddleware(verifier token.Verifier, opts ...Option) fiber.Handler {
+	auth := newAuthenticator(verifier, opts)
+	policy := auth.invalidTokenPolicy
+
+	return func(c *fiber.Ctx) error {
+		payload, err := auth.authenticate(c)
+		switch {
+		case err == nil:
+			c.Locals(AuthorizationPayloadKey, payload)
+		case errors.Is(err, errMissingToken):
+		case policy == RejectInvalidToken:
+			return tokenError(c, err)
+		case policy == RejectExpiredToken && errors.Is(err, token.ErrExpiredToken):
+			return tokenError(c, err)
+		}
+
+		return c.Next()
+	}
+}
@@ -0,0 +1,72 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// MembershipStore looks up the role of a user in an organization. It
+// returns an empty role when the user is not a member.
+type MembershipStore interface {
+	MembershipRole(ctx context.Context, orgID, userID pgtype.UUID) (string, error)
+}
+
+// Tenant is the organization a request acts for
+type Tenant struct {
+	OrgID uuid.UUID
+	// Role is the role of the user in the organization, empty when the
+	// middleware has no MembershipStore
+	Role string
+}
+
+// TenantMiddleware scopes the request to the organization of the org_id
+// claim. It must run after AuthMiddleware. Requests whose token carries no
+// organization are rejected. With a store, membership is checked on every
+// request so removed members lose access immediately, and when roles are
+// given the user must have one of them in the organization.
+func TenantMiddleware(store MembershipStore, roles ...string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		payload, err := GetAuthPayload(c)
+		if err != nil {
+			return tokenError(c, errMissingToken)
+		}
+		if payload.OrgID == uuid.Nil {
+			return authError(c, fiber.StatusForbidden, CodeTenantRequired, "No active organization")
+		}
+
+		tenant := &Tenant{OrgID: payload.OrgID}
+		if store == nil {
+			if len(roles) > 0 {
+				return authError(c, fiber.StatusInternalServerError, CodeAuthorizationFailed, "Organization roles are not configured")
+			}
+		} else {
+			orgID := pgtype.UUID{Bytes: payload.OrgID, Valid: true}
+			tenant.Role, err = store.MembershipRole(c.Context(), orgID, payload.UserID)
+			if err != nil {
+				return authError(c, fiber.StatusInternalServerError, CodeAuthorizationFailed, "Unable to check organization membership")
+			}
+			if tenant.Role == "" {
+				return authError(c, fiber.StatusForbidden, CodeTenantAccessDenied, "Not a member of the active organization")
+			}
+			if len(roles) > 0 && !contains(roles, tenant.Role) {
+				return forbidden(c, CodeInsufficientTenantRole, "Insufficient organization role", roles)
+			}
+		}
+
+		c.Locals(TenantKey, tenant)
+		return c.Next()
+	}
+}
+
+// GetTenant returns the organization stored by TenantMiddleware
+func GetTenant(c *fiber.Ctx) (*Tenant, error) {
+	tenant, ok := c.Locals(TenantKey).(*Tenant)
+	if !ok {
+		return nil, errors.New("tenant not found")
+	}
+	return tenant, nil
+}
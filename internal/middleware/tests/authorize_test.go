@@ -0,0 +1,166 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+
+	"github.com/suryansh74/auth-package/internal/middleware"
+	"github.com/suryansh74/auth-package/token"
+)
+
+// fakeRoleStore grants the same roles and permissions to every user
+type fakeRoleStore struct {
+	roles       []string
+	permissions []string
+	err         error
+}
+
+func (store fakeRoleStore) UserRoles(ctx context.Context, userID pgtype.UUID) ([]string, error) {
+	return store.roles, store.err
+}
+
+func (store fakeRoleStore) UserPermissions(ctx context.Context, userID pgtype.UUID) ([]string, error) {
+	return store.permissions, store.err
+}
+
+func TestAuthorizer(t *testing.T) {
+	maker := newTestMaker(t)
+	errStore := errors.New("store unavailable")
+
+	testCases := []struct {
+		name        string
+		tokenOpts   []token.PayloadOption
+		handler     fiber.Handler
+		status      int
+		code        string
+		requirement []string
+	}{
+		{
+			name:      "RoleFromToken",
+			tokenOpts: []token.PayloadOption{token.WithRoles("editor")},
+			handler:   middleware.RequireRoles("admin", "editor"),
+			status:    http.StatusOK,
+		},
+		{
+			name:        "MissingRoleInToken",
+			tokenOpts:   []token.PayloadOption{token.WithRoles("viewer")},
+			handler:     middleware.RequireRoles("admin", "editor"),
+			status:      http.StatusForbidden,
+			code:        middleware.CodeInsufficientRole,
+			requirement: []string{"admin", "editor"},
+		},
+		{
+			// the store is authoritative over the roles of the token
+			name:        "RoleRevokedInStore",
+			tokenOpts:   []token.PayloadOption{token.WithRoles("admin")},
+			handler:     middleware.NewAuthorizer(fakeRoleStore{roles: []string{"viewer"}}).RequireRoles("admin"),
+			status:      http.StatusForbidden,
+			code:        middleware.CodeInsufficientRole,
+			requirement: []string{"admin"},
+		},
+		{
+			name:    "RoleGrantedInStore",
+			handler: middleware.NewAuthorizer(fakeRoleStore{roles: []string{"admin"}}).RequireRoles("admin"),
+			status:  http.StatusOK,
+		},
+		{
+			name:    "RoleStoreError",
+			handler: middleware.NewAuthorizer(fakeRoleStore{err: errStore}).RequireRoles("admin"),
+			status:  http.StatusInternalServerError,
+			code:    middleware.CodeAuthorizationFailed,
+		},
+		{
+			name:    "AllPermissions",
+			handler: middleware.NewAuthorizer(fakeRoleStore{permissions: []string{"posts:read", "posts:write"}}).RequirePermissions("posts:read", "posts:write"),
+			status:  http.StatusOK,
+		},
+		{
+			name:        "SomePermissions",
+			handler:     middleware.NewAuthorizer(fakeRoleStore{permissions: []string{"posts:read"}}).RequirePermissions("posts:read", "posts:write"),
+			status:      http.StatusForbidden,
+			code:        middleware.CodeInsufficientPermission,
+			requirement: []string{"posts:read", "posts:write"},
+		},
+		{
+			name:    "PermissionsWithoutStore",
+			handler: middleware.NewAuthorizer(nil).RequirePermissions("posts:read"),
+			status:  http.StatusInternalServerError,
+			code:    middleware.CodeAuthorizationFailed,
+		},
+		{
+			name:    "PermissionStoreError",
+			handler: middleware.NewAuthorizer(fakeRoleStore{err: errStore}).RequirePermissions("posts:read"),
+			status:  http.StatusInternalServerError,
+			code:    middleware.CodeAuthorizationFailed,
+		},
+		{
+			name:      "AllScopes",
+			tokenOpts: []token.PayloadOption{token.WithScopes("read", "write")},
+			handler:   middleware.RequireScopes("read", "write"),
+			status:    http.StatusOK,
+		},
+		{
+			name:        "SomeScopes",
+			tokenOpts:   []token.PayloadOption{token.WithScopes("read")},
+			handler:     middleware.RequireScopes("read", "write"),
+			status:      http.StatusForbidden,
+			code:        middleware.CodeInsufficientScope,
+			requirement: []string{"read", "write"},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			app := fiber.New()
+			app.Get("/", middleware.AuthMiddleware(maker), tc.handler, okHandler)
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set(fiber.HeaderAuthorization, "Bearer "+newAccessToken(t, maker, tc.tokenOpts...))
+			resp := performRequest(t, app, req)
+
+			require.Equal(t, tc.status, resp.StatusCode)
+			if tc.code == "" {
+				return
+			}
+			var body struct {
+				Code     string   `json:"code"`
+				Required []string `json:"required"`
+			}
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+			require.Equal(t, tc.code, body.Code)
+			require.Equal(t, tc.requirement, body.Required)
+		})
+	}
+}
+
+func TestAuthorizerRequiresAuthentication(t *testing.T) {
+	// without AuthMiddleware before it there is no user to check
+	app := fiber.New()
+	app.Get("/roles", middleware.RequireRoles("admin"), okHandler)
+	app.Get("/scopes", middleware.RequireScopes("read"), okHandler)
+
+	for _, path := range []string{"/roles", "/scopes"} {
+		resp := performRequest(t, app, httptest.NewRequest(http.MethodGet, path, nil))
+		requireAuthError(t, resp, http.StatusUnauthorized, middleware.CodeMissingToken)
+	}
+}
+
+func TestAuthorizerRejectsEmptyRequirements(t *testing.T) {
+	authorizer := middleware.NewAuthorizer(fakeRoleStore{})
+
+	require.Panics(t, func() { authorizer.RequireRoles() })
+	require.Panics(t, func() { authorizer.RequirePermissions() })
+	require.Panics(t, func() { authorizer.RequireScopes() })
+	require.Panics(t, func() { middleware.RequireRoles() })
+	require.Panics(t, func() { middleware.RequireScopes("read", "") })
+}
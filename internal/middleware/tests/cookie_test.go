@@ -0,0 +1,203 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/require"
+
+	"github.com/suryansh74/auth-package/internal/middleware"
+)
+
+var testCookies = middleware.CookieConfig{
+	AccessName:  "access_token",
+	RefreshName: "refresh_token",
+	CSRFName:    "csrf_token",
+	Path:        "/",
+	RefreshPath: "/auth",
+	SameSite:    fiber.CookieSameSiteLaxMode,
+}
+
+func TestValidCSRF(t *testing.T) {
+	testCases := []struct {
+		name   string
+		method string
+		cookie string
+		header string
+		valid  bool
+	}{
+		{name: "Matching", method: http.MethodPost, cookie: "csrf-value", header: "csrf-value", valid: true},
+		{name: "Mismatch", method: http.MethodPost, cookie: "csrf-value", header: "other-value"},
+		{name: "MissingHeader", method: http.MethodPost, cookie: "csrf-value"},
+		{name: "MissingCookie", method: http.MethodDelete, header: "csrf-value"},
+		{name: "MissingBoth", method: http.MethodPatch},
+		// safe methods change nothing and need no token
+		{name: "Get", method: http.MethodGet, valid: true},
+		{name: "Head", method: http.MethodHead, valid: true},
+		{name: "Options", method: http.MethodOptions, valid: true},
+	}
+
+	app := fiber.New()
+	app.All("/", func(c *fiber.Ctx) error {
+		if !testCookies.ValidCSRF(c) {
+			return c.SendStatus(fiber.StatusForbidden)
+		}
+		return c.SendStatus(fiber.StatusOK)
+	})
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(tc.method, "/", nil)
+			if tc.cookie != "" {
+				req.AddCookie(&http.Cookie{Name: testCookies.CSRFName, Value: tc.cookie})
+			}
+			if tc.header != "" {
+				req.Header.Set(middleware.CSRFHeader, tc.header)
+			}
+
+			resp := performRequest(t, app, req)
+			if tc.valid {
+				require.Equal(t, http.StatusOK, resp.StatusCode)
+			} else {
+				require.Equal(t, http.StatusForbidden, resp.StatusCode)
+			}
+		})
+	}
+}
+
+func TestAuthMiddlewareCookie(t *testing.T) {
+	maker := newTestMaker(t)
+	accessToken := newAccessToken(t, maker)
+
+	testCases := []struct {
+		name          string
+		buildRequest  func() *http.Request
+		checkResponse func(t *testing.T, resp *http.Response)
+	}{
+		{
+			name: "SafeMethodWithoutCSRF",
+			buildRequest: func() *http.Request {
+				req := httptest.NewRequest(http.MethodGet, "/", nil)
+				req.AddCookie(&http.Cookie{Name: testCookies.AccessName, Value: accessToken})
+				return req
+			},
+			checkResponse: func(t *testing.T, resp *http.Response) {
+				require.Equal(t, http.StatusOK, resp.StatusCode)
+			},
+		},
+		{
+			name: "StateChangingWithCSRF",
+			buildRequest: func() *http.Request {
+				req := httptest.NewRequest(http.MethodPost, "/", nil)
+				req.AddCookie(&http.Cookie{Name: testCookies.AccessName, Value: accessToken})
+				req.AddCookie(&http.Cookie{Name: testCookies.CSRFName, Value: "csrf-value"})
+				req.Header.Set(middleware.CSRFHeader, "csrf-value")
+				return req
+			},
+			checkResponse: func(t *testing.T, resp *http.Response) {
+				require.Equal(t, http.StatusOK, resp.StatusCode)
+			},
+		},
+		{
+			name: "StateChangingWithoutCSRF",
+			buildRequest: func() *http.Request {
+				req := httptest.NewRequest(http.MethodPost, "/", nil)
+				req.AddCookie(&http.Cookie{Name: testCookies.AccessName, Value: accessToken})
+				req.AddCookie(&http.Cookie{Name: testCookies.CSRFName, Value: "csrf-value"})
+				return req
+			},
+			checkResponse: func(t *testing.T, resp *http.Response) {
+				requireAuthError(t, resp, http.StatusForbidden, middleware.CodeInvalidCSRF)
+			},
+		},
+		{
+			name: "StateChangingWithForgedCSRF",
+			buildRequest: func() *http.Request {
+				req := httptest.NewRequest(http.MethodPut, "/", nil)
+				req.AddCookie(&http.Cookie{Name: testCookies.AccessName, Value: accessToken})
+				req.AddCookie(&http.Cookie{Name: testCookies.CSRFName, Value: "csrf-value"})
+				req.Header.Set(middleware.CSRFHeader, "guessed")
+				return req
+			},
+			checkResponse: func(t *testing.T, resp *http.Response) {
+				requireAuthError(t, resp, http.StatusForbidden, middleware.CodeInvalidCSRF)
+			},
+		},
+		{
+			// tokens sent by header are not attached by browsers, so need
+			// no CSRF token
+			name: "HeaderWithoutCSRF",
+			buildRequest: func() *http.Request {
+				req := httptest.NewRequest(http.MethodPost, "/", nil)
+				req.Header.Set(fiber.HeaderAuthorization, "Bearer "+accessToken)
+				return req
+			},
+			checkResponse: func(t *testing.T, resp *http.Response) {
+				require.Equal(t, http.StatusOK, resp.StatusCode)
+			},
+		},
+		{
+			name: "NoToken",
+			buildRequest: func() *http.Request {
+				return httptest.NewRequest(http.MethodGet, "/", nil)
+			},
+			checkResponse: func(t *testing.T, resp *http.Response) {
+				requireAuthError(t, resp, http.StatusUnauthorized, middleware.CodeMissingToken)
+			},
+		},
+	}
+
+	app := fiber.New()
+	app.All("/", middleware.AuthMiddleware(maker, middleware.WithCookie(testCookies)), okHandler)
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			tc.checkResponse(t, performRequest(t, app, tc.buildRequest()))
+		})
+	}
+}
+
+func TestCookieAttributes(t *testing.T) {
+	expires := time.Now().Add(time.Hour)
+	app := fiber.New()
+	app.Get("/set", func(c *fiber.Ctx) error {
+		testCookies.SetAccessToken(c, "access", expires)
+		testCookies.SetRefreshToken(c, "refresh", expires)
+		testCookies.SetCSRFToken(c, "csrf", expires)
+		return c.SendStatus(fiber.StatusOK)
+	})
+	app.Get("/clear", func(c *fiber.Ctx) error {
+		testCookies.Clear(c)
+		return c.SendStatus(fiber.StatusOK)
+	})
+
+	resp := performRequest(t, app, httptest.NewRequest(http.MethodGet, "/set", nil))
+	cookies := map[string]*http.Cookie{}
+	for _, cookie := range resp.Cookies() {
+		cookies[cookie.Name] = cookie
+	}
+	require.Len(t, cookies, 3)
+	for _, cookie := range cookies {
+		require.True(t, cookie.Secure)
+		require.Equal(t, http.SameSiteLaxMode, cookie.SameSite)
+	}
+	require.True(t, cookies[testCookies.AccessName].HttpOnly)
+	require.True(t, cookies[testCookies.RefreshName].HttpOnly)
+	require.Equal(t, "/auth", cookies[testCookies.RefreshName].Path)
+	// the client must read the CSRF token to echo it
+	require.False(t, cookies[testCookies.CSRFName].HttpOnly)
+
+	resp = performRequest(t, app, httptest.NewRequest(http.MethodGet, "/clear", nil))
+	for _, header := range resp.Header.Values(fiber.HeaderSetCookie) {
+		require.True(t, strings.Contains(header, "expires=Thu, 01 Jan 1970"), header)
+	}
+	require.Len(t, resp.Header.Values(fiber.HeaderSetCookie), 3)
+}
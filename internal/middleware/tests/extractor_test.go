@@ -0,0 +1,177 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/require"
+
+	"github.com/suryansh74/auth-package/internal/middleware"
+)
+
+func TestFromAuthHeader(t *testing.T) {
+	maker := newTestMaker(t)
+	accessToken := newAccessToken(t, maker)
+
+	testCases := []struct {
+		name   string
+		header string
+		status int
+		code   string
+	}{
+		{name: "Bearer", header: "Bearer " + accessToken, status: http.StatusOK},
+		// RFC 6750 schemes are case-insensitive
+		{name: "LowerCase", header: "bearer " + accessToken, status: http.StatusOK},
+		{name: "UpperCase", header: "BEARER " + accessToken, status: http.StatusOK},
+		{name: "ExtraSpaces", header: "Bearer   " + accessToken + " ", status: http.StatusOK},
+		{name: "OtherScheme", header: "Basic " + accessToken, status: http.StatusUnauthorized, code: middleware.CodeInvalidAuthHeader},
+		{name: "NoScheme", header: accessToken, status: http.StatusUnauthorized, code: middleware.CodeInvalidAuthHeader},
+		{name: "NoCredentials", header: "Bearer ", status: http.StatusUnauthorized, code: middleware.CodeInvalidAuthHeader},
+		{name: "Missing", status: http.StatusUnauthorized, code: middleware.CodeMissingToken},
+		{name: "InvalidToken", header: "Bearer not-a-token", status: http.StatusUnauthorized, code: middleware.CodeMalformedToken},
+	}
+
+	app := fiber.New()
+	app.Get("/", middleware.AuthMiddleware(maker), okHandler)
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tc.header != "" {
+				req.Header.Set(fiber.HeaderAuthorization, tc.header)
+			}
+
+			resp := performRequest(t, app, req)
+			if tc.code == "" {
+				require.Equal(t, tc.status, resp.StatusCode)
+			} else {
+				requireAuthError(t, resp, tc.status, tc.code)
+			}
+		})
+	}
+}
+
+func TestExtractorChain(t *testing.T) {
+	maker := newTestMaker(t)
+	accessToken := newAccessToken(t, maker)
+	errCustom := errors.New("custom extractor refused the request")
+
+	testCases := []struct {
+		name         string
+		extractors   []middleware.Extractor
+		buildRequest func() *http.Request
+		status       int
+		code         string
+	}{
+		{
+			name:       "FirstFound",
+			extractors: []middleware.Extractor{middleware.FromAuthHeader(), middleware.FromQuery("access_token")},
+			buildRequest: func() *http.Request {
+				req := httptest.NewRequest(http.MethodGet, "/?access_token=not-a-token", nil)
+				req.Header.Set(fiber.HeaderAuthorization, "Bearer "+accessToken)
+				return req
+			},
+			status: http.StatusOK,
+		},
+		{
+			name:       "FallsThroughToNext",
+			extractors: []middleware.Extractor{middleware.FromAuthHeader(), middleware.FromQuery("access_token")},
+			buildRequest: func() *http.Request {
+				return httptest.NewRequest(http.MethodGet, "/?access_token="+accessToken, nil)
+			},
+			status: http.StatusOK,
+		},
+		{
+			// a header that is there but malformed stops the chain
+			name:       "ErrorStopsChain",
+			extractors: []middleware.Extractor{middleware.FromAuthHeader(), middleware.FromQuery("access_token")},
+			buildRequest: func() *http.Request {
+				req := httptest.NewRequest(http.MethodGet, "/?access_token="+accessToken, nil)
+				req.Header.Set(fiber.HeaderAuthorization, "Token "+accessToken)
+				return req
+			},
+			status: http.StatusUnauthorized,
+			code:   middleware.CodeInvalidAuthHeader,
+		},
+		{
+			name:       "HeaderWithoutScheme",
+			extractors: []middleware.Extractor{middleware.FromHeader("X-Access-Token", "")},
+			buildRequest: func() *http.Request {
+				req := httptest.NewRequest(http.MethodGet, "/", nil)
+				req.Header.Set("X-Access-Token", accessToken)
+				return req
+			},
+			status: http.StatusOK,
+		},
+		{
+			name: "CustomExtractorError",
+			extractors: []middleware.Extractor{
+				func(c *fiber.Ctx) (string, error) { return "", errCustom },
+				middleware.FromAuthHeader(),
+			},
+			buildRequest: func() *http.Request {
+				req := httptest.NewRequest(http.MethodGet, "/", nil)
+				req.Header.Set(fiber.HeaderAuthorization, "Bearer "+accessToken)
+				return req
+			},
+			status: http.StatusUnauthorized,
+			code:   middleware.CodeInvalidToken,
+		},
+		{
+			name:       "NothingFound",
+			extractors: []middleware.Extractor{middleware.FromQuery("access_token")},
+			buildRequest: func() *http.Request {
+				req := httptest.NewRequest(http.MethodGet, "/", nil)
+				// not looked at once other extractors are given
+				req.Header.Set(fiber.HeaderAuthorization, "Bearer "+accessToken)
+				return req
+			},
+			status: http.StatusUnauthorized,
+			code:   middleware.CodeMissingToken,
+		},
+		{
+			name:       "CookieRequiresCSRF",
+			extractors: []middleware.Extractor{middleware.FromCookie(testCookies)},
+			buildRequest: func() *http.Request {
+				req := httptest.NewRequest(http.MethodPost, "/", nil)
+				req.AddCookie(&http.Cookie{Name: testCookies.AccessName, Value: accessToken})
+				return req
+			},
+			status: http.StatusForbidden,
+			code:   middleware.CodeInvalidCSRF,
+		},
+		{
+			name:       "CookieWithCSRF",
+			extractors: []middleware.Extractor{middleware.FromCookie(testCookies)},
+			buildRequest: func() *http.Request {
+				req := httptest.NewRequest(http.MethodPost, "/", nil)
+				req.AddCookie(&http.Cookie{Name: testCookies.AccessName, Value: accessToken})
+				req.AddCookie(&http.Cookie{Name: testCookies.CSRFName, Value: "csrf-value"})
+				req.Header.Set(middleware.CSRFHeader, "csrf-value")
+				return req
+			},
+			status: http.StatusOK,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			app := fiber.New()
+			app.All("/", middleware.AuthMiddleware(maker, middleware.WithExtractors(tc.extractors...)), okHandler)
+
+			resp := performRequest(t, app, tc.buildRequest())
+			if tc.code == "" {
+				require.Equal(t, tc.status, resp.StatusCode)
+			} else {
+				requireAuthError(t, resp, tc.status, tc.code)
+			}
+		})
+	}
+}
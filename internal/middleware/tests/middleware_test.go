@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+
+	"github.com/suryansh74/auth-package/internal/utils"
+	"github.com/suryansh74/auth-package/token"
+)
+
+func newTestMaker(t *testing.T) token.Maker {
+	maker, err := token.NewPasetoMaker(utils.RandomString(32))
+	require.NoError(t, err)
+	return maker
+}
+
+// newAccessToken issues an access token for a random user
+func newAccessToken(t *testing.T, maker token.Maker, opts ...token.PayloadOption) string {
+	userID := pgtype.UUID{Bytes: uuid.New(), Valid: true}
+	accessToken, err := maker.CreateToken(userID, utils.RandomEmail(), time.Minute, opts...)
+	require.NoError(t, err)
+	return accessToken
+}
+
+// okHandler answers 200 once every middleware let the request through
+func okHandler(c *fiber.Ctx) error {
+	return c.SendStatus(fiber.StatusOK)
+}
+
+func performRequest(t *testing.T, app *fiber.App, req *http.Request) *http.Response {
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	t.Cleanup(func() { resp.Body.Close() })
+	return resp
+}
+
+// requireAuthError checks the status and code of a refused request
+func requireAuthError(t *testing.T, resp *http.Response, status int, code string) {
+	require.Equal(t, status, resp.StatusCode)
+	var body map[string]interface{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	require.Equal(t, code, body["code"])
+}
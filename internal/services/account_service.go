@@ -0,0 +1,175 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	customError "github.com/suryansh74/auth-package/internal/apperrors"
+	"github.com/suryansh74/auth-package/internal/db"
+	"github.com/suryansh74/auth-package/internal/db/sqlc"
+	"github.com/suryansh74/auth-package/internal/utils"
+)
+
+// AccountService deletes accounts in two steps. Deleting marks the user as
+// deleted, which hides it from every lookup and login, and the owner can
+// restore it during the grace period. Once the grace period is over the
+// purge removes the user along with everything that references it.
+type AccountService interface {
+	DeleteAccount(ctx context.Context, userID pgtype.UUID, password string) (*sqlc.User, error)
+	RestoreAccount(ctx context.Context, email, password string) (*sqlc.User, error)
+	PurgeDeletedAccounts(ctx context.Context) (int64, error)
+}
+
+// dummyPasswordHash is the bcrypt hash of a random password, checked when
+// no account matches the email
+const dummyPasswordHash = "$2a$10$FOffOW6Dx8.5VgRbrxyqU.2jCm2K8EC9bLlRS.9l6v0Cmt8T/b8Ui"
+
+type AccountManager struct {
+	auth        db.Auth
+	gracePeriod time.Duration
+}
+
+func NewAccountManager(auth db.Auth, gracePeriod time.Duration) AccountService {
+	return &AccountManager{
+		auth:        auth,
+		gracePeriod: gracePeriod,
+	}
+}
+
+// DeleteAccount checks the password of the user and marks it as deleted.
+// The sole owner of an organization must hand it over first, since the
+// organization could not be managed anymore. Pending password resets and
+// email changes are dropped so their links cannot act on the account;
+// tokens and sessions are up to the caller.
+func (a *AccountManager) DeleteAccount(ctx context.Context, userID pgtype.UUID, password string) (*sqlc.User, error) {
+	user, err := a.auth.GetUser(ctx, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, customError.ErrUserNotFound
+		}
+		return nil, customError.UnExpectedError
+	}
+	if utils.CheckPassword(password, user.Password) != nil {
+		return nil, customError.ErrIncorrectPassword
+	}
+
+	err = execTx(ctx, a.auth, func(auth db.Auth) error {
+		// the organizations stay locked until the user is deleted, so
+		// co-owners deleting their accounts at once cannot leave one
+		// without an owner
+		orgIDs, err := auth.LockOwnedOrganizations(ctx, userID)
+		if err != nil {
+			return customError.UnExpectedError
+		}
+		for _, orgID := range orgIDs {
+			owners, err := auth.CountOrganizationOwners(ctx, orgID)
+			if err != nil {
+				return customError.UnExpectedError
+			}
+			if owners <= 1 {
+				return customError.ErrLastOrganizationOwner
+			}
+		}
+
+		user, err = auth.SoftDeleteUser(ctx, sqlc.SoftDeleteUserParams{
+			ID:        userID,
+			DeletedAt: pgtype.Timestamp{Time: time.Now(), Valid: true},
+		})
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return customError.ErrUserNotFound
+			}
+			return customError.UnExpectedError
+		}
+		if err := auth.InvalidateUserPasswordResetTokens(ctx, userID); err != nil {
+			return customError.UnExpectedError
+		}
+		if err := auth.CancelUserEmailChangeRequests(ctx, userID); err != nil {
+			return customError.UnExpectedError
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// RestoreAccount undoes the deletion of the account registered with email
+// when password matches and the grace period is not over
+func (a *AccountManager) RestoreAccount(ctx context.Context, email, password string) (*sqlc.User, error) {
+	user, err := a.auth.GetDeletedUserByEmail(ctx, email)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			// takes as long as a wrong password, so the response time does
+			// not tell which emails belong to deleted accounts
+			_ = utils.CheckPassword(password, dummyPasswordHash)
+			return nil, customError.ErrUserNotFound
+		}
+		return nil, customError.UnExpectedError
+	}
+	if utils.CheckPassword(password, user.Password) != nil {
+		return nil, customError.ErrIncorrectPassword
+	}
+
+	// the grace period is checked by the update, so an account is never
+	// restored while the purge removes it
+	user, err = a.auth.RestoreUser(ctx, sqlc.RestoreUserParams{
+		ID:           user.ID,
+		DeletedAfter: pgtype.Timestamp{Time: time.Now().Add(-a.gracePeriod), Valid: true},
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, customError.ErrAccountNotRestorable
+		}
+		return nil, customError.UnExpectedError
+	}
+	return &user, nil
+}
+
+// PurgeDeletedAccounts removes users deleted before the grace period and
+// returns how many were removed. Sessions, memberships, roles and pending
+// tokens of the users go with them.
+func (a *AccountManager) PurgeDeletedAccounts(ctx context.Context) (int64, error) {
+	return a.auth.PurgeDeletedUsers(ctx, pgtype.Timestamp{Time: time.Now().Add(-a.gracePeriod), Valid: true})
+}
+
+// RunAccountPurge purges accounts whose grace period is over every interval
+// until ctx is cancelled. It blocks, so run it in its own goroutine.
+func RunAccountPurge(ctx context.Context, accounts AccountService, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			purged, err := accounts.PurgeDeletedAccounts(ctx)
+			if err != nil {
+				log.Printf("account purge failed: %v", err)
+				continue
+			}
+			if purged > 0 {
+				log.Printf("account purge: removed %d deleted accounts", purged)
+			}
+		}
+	}
+}
+
+// heldByDeletedUser tells whether email belongs to a deleted user, who
+// keeps it until purged so the account can be restored
+func heldByDeletedUser(ctx context.Context, auth db.Auth, email string) (bool, error) {
+	_, err := auth.GetDeletedUserByEmail(ctx, email)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, nil
+		}
+		return false, customError.UnExpectedError
+	}
+	return true, nil
+}
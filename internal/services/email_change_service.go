@@ -0,0 +1,246 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/mail"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	customError "github.com/suryansh74/auth-package/internal/apperrors"
+	"github.com/suryansh74/auth-package/internal/db"
+	"github.com/suryansh74/auth-package/internal/db/sqlc"
+	"github.com/suryansh74/auth-package/internal/utils"
+)
+
+// emailChangeTokenBytes is the amount of randomness in the confirm and
+// cancel tokens of an email change
+const emailChangeTokenBytes = 32
+
+// EmailChangeTokens are the plaintext tokens of an email change request,
+// the confirm token for the new address and the cancel token for the old one
+type EmailChangeTokens struct {
+	Confirm string
+	Cancel  string
+}
+
+// EmailChangeService changes the email users log in with. A change is only
+// applied once confirmed from the new address, and the old address can
+// cancel it, even after confirmation while the request has not expired.
+// Tokens are opaque and only their SHA-256 hash is stored.
+type EmailChangeService interface {
+	RequestEmailChange(ctx context.Context, userID pgtype.UUID, password, newEmail string, validFor time.Duration) (*EmailChangeTokens, *sqlc.EmailChangeRequest, error)
+	ConfirmEmailChange(ctx context.Context, confirmToken string) (*sqlc.User, error)
+	CancelEmailChange(ctx context.Context, cancelToken string) (*sqlc.User, bool, error)
+}
+
+type EmailChangeManager struct {
+	auth db.Auth
+}
+
+func NewEmailChangeManager(auth db.Auth) EmailChangeService {
+	return &EmailChangeManager{
+		auth: auth,
+	}
+}
+
+// RequestEmailChange checks the password of the user and records a pending
+// change to newEmail, replacing any change requested before
+func (e *EmailChangeManager) RequestEmailChange(ctx context.Context, userID pgtype.UUID, password, newEmail string, validFor time.Duration) (*EmailChangeTokens, *sqlc.EmailChangeRequest, error) {
+	user, err := e.auth.GetUser(ctx, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil, customError.ErrUserNotFound
+		}
+		return nil, nil, customError.UnExpectedError
+	}
+	if utils.CheckPassword(password, user.Password) != nil {
+		return nil, nil, customError.ErrIncorrectPassword
+	}
+	if !validEmail(newEmail) {
+		return nil, nil, customError.ErrInvalidEmail
+	}
+	if newEmail == user.Email {
+		return nil, nil, customError.ErrEmailNotChanged
+	}
+	if err := e.ensureEmailAvailable(ctx, newEmail); err != nil {
+		return nil, nil, err
+	}
+
+	if err := e.auth.CancelUserEmailChangeRequests(ctx, userID); err != nil {
+		return nil, nil, customError.UnExpectedError
+	}
+	var tokens EmailChangeTokens
+	if tokens.Confirm, err = utils.GenerateSecureToken(emailChangeTokenBytes); err != nil {
+		return nil, nil, customError.UnExpectedError
+	}
+	if tokens.Cancel, err = utils.GenerateSecureToken(emailChangeTokenBytes); err != nil {
+		return nil, nil, customError.UnExpectedError
+	}
+	request, err := e.auth.CreateEmailChangeRequest(ctx, sqlc.CreateEmailChangeRequestParams{
+		UserID:           userID,
+		OldEmail:         user.Email,
+		NewEmail:         newEmail,
+		ConfirmTokenHash: utils.HashToken(tokens.Confirm),
+		CancelTokenHash:  utils.HashToken(tokens.Cancel),
+		ExpiresAt:        pgtype.Timestamp{Time: time.Now().Add(validFor), Valid: true},
+	})
+	if err != nil {
+		return nil, nil, customError.UnExpectedError
+	}
+	return &tokens, &request, nil
+}
+
+// ConfirmEmailChange applies the change of the confirm token. The new
+// address counts as verified, since the link was sent to it.
+func (e *EmailChangeManager) ConfirmEmailChange(ctx context.Context, confirmToken string) (*sqlc.User, error) {
+	request, err := e.auth.GetEmailChangeRequestByConfirmTokenHash(ctx, utils.HashToken(confirmToken))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, customError.ErrInvalidEmailChange
+		}
+		return nil, customError.UnExpectedError
+	}
+	if request.ConfirmedAt.Valid || request.CancelledAt.Valid || time.Now().After(request.ExpiresAt.Time) {
+		return nil, customError.ErrInvalidEmailChange
+	}
+	// someone may have registered the address in the meantime
+	if err := e.ensureEmailAvailable(ctx, request.NewEmail); err != nil {
+		return nil, err
+	}
+
+	var user *sqlc.User
+	err = execTx(ctx, e.auth, func(auth db.Auth) error {
+		// the conditional update makes sure a change is applied once, and not
+		// after it was cancelled
+		_, err := auth.ConfirmEmailChangeRequest(ctx, request.ID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return customError.ErrInvalidEmailChange
+			}
+			return customError.UnExpectedError
+		}
+		user, err = updateEmail(ctx, auth, request.UserID, request.OldEmail, request.NewEmail)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// CancelEmailChange drops the change of the cancel token. When the change
+// was already confirmed, the old address is restored and true is returned,
+// as the account may have been taken over.
+func (e *EmailChangeManager) CancelEmailChange(ctx context.Context, cancelToken string) (*sqlc.User, bool, error) {
+	request, err := e.auth.GetEmailChangeRequestByCancelTokenHash(ctx, utils.HashToken(cancelToken))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, false, customError.ErrInvalidEmailChange
+		}
+		return nil, false, customError.UnExpectedError
+	}
+	if request.CancelledAt.Valid || time.Now().After(request.ExpiresAt.Time) {
+		return nil, false, customError.ErrInvalidEmailChange
+	}
+	if request.ConfirmedAt.Valid {
+		if err := e.ensureEmailAvailable(ctx, request.OldEmail); err != nil {
+			return nil, false, err
+		}
+	}
+
+	var user sqlc.User
+	err = execTx(ctx, e.auth, func(auth db.Auth) error {
+		var err error
+		request, err = auth.CancelEmailChangeRequest(ctx, request.ID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return customError.ErrInvalidEmailChange
+			}
+			return customError.UnExpectedError
+		}
+		if !request.ConfirmedAt.Valid {
+			user, err = auth.GetUser(ctx, request.UserID)
+			if err != nil {
+				return customError.UnExpectedError
+			}
+			return nil
+		}
+
+		// whoever confirmed the change may have changed the email again
+		// since, so their requests are dropped and the old address is
+		// restored whatever the current one is
+		err = auth.CancelOtherUserEmailChangeRequests(ctx, sqlc.CancelOtherUserEmailChangeRequestsParams{
+			UserID: request.UserID,
+			ID:     request.ID,
+		})
+		if err != nil {
+			return customError.UnExpectedError
+		}
+		user, err = auth.RestoreUserEmail(ctx, sqlc.RestoreUserEmailParams{
+			Email: request.OldEmail,
+			ID:    request.UserID,
+		})
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return customError.ErrInvalidEmailChange
+			}
+			// the old address was taken since it was checked
+			if db.IsUniqueViolation(err) {
+				return customError.ErrUserAlreadyExist
+			}
+			return customError.UnExpectedError
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	return &user, request.ConfirmedAt.Valid, nil
+}
+
+// updateEmail replaces oldEmail with newEmail, failing when the user no
+// longer has oldEmail because of another change
+func updateEmail(ctx context.Context, auth db.Auth, userID pgtype.UUID, oldEmail, newEmail string) (*sqlc.User, error) {
+	user, err := auth.UpdateUserEmail(ctx, sqlc.UpdateUserEmailParams{
+		NewEmail: newEmail,
+		ID:       userID,
+		OldEmail: oldEmail,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, customError.ErrInvalidEmailChange
+		}
+		// the new address was taken since it was checked
+		if db.IsUniqueViolation(err) {
+			return nil, customError.ErrUserAlreadyExist
+		}
+		return nil, customError.UnExpectedError
+	}
+	return &user, nil
+}
+
+func (e *EmailChangeManager) ensureEmailAvailable(ctx context.Context, email string) error {
+	_, err := e.auth.GetUserByEmail(ctx, email)
+	if err == nil {
+		return customError.ErrUserAlreadyExist
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return customError.UnExpectedError
+	}
+	deleted, err := heldByDeletedUser(ctx, e.auth, email)
+	if err != nil {
+		return err
+	}
+	if deleted {
+		return customError.ErrUserAlreadyExist
+	}
+	return nil
+}
+
+// validEmail accepts a bare address, without display name
+func validEmail(email string) bool {
+	address, err := mail.ParseAddress(email)
+	return err == nil && address.Address == email && len(email) <= 255
+}
@@ -0,0 +1,394 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	customError "github.com/suryansh74/auth-package/internal/apperrors"
+	"github.com/suryansh74/auth-package/internal/db"
+	"github.com/suryansh74/auth-package/internal/db/sqlc"
+	"github.com/suryansh74/auth-package/internal/utils"
+)
+
+// Roles a user can have within an organization, from most to least
+// privileged
+const (
+	OrganizationRoleOwner  = "owner"
+	OrganizationRoleAdmin  = "admin"
+	OrganizationRoleMember = "member"
+)
+
+// invitationTokenBytes is the amount of randomness in an invitation token
+const invitationTokenBytes = 32
+
+var organizationSlugPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,99}$`)
+
+// OrganizationService manages organizations, their members and the
+// invitations to join them. Methods taking an actor check that the actor
+// may perform the change within the organization.
+type OrganizationService interface {
+	CreateOrganization(ctx context.Context, ownerID pgtype.UUID, name, slug string) (*sqlc.Organization, error)
+	ListUserOrganizations(ctx context.Context, userID pgtype.UUID) ([]sqlc.ListUserOrganizationsRow, error)
+	ListMembers(ctx context.Context, actorID, orgID pgtype.UUID) ([]sqlc.ListOrganizationMembersRow, error)
+	UpdateMemberRole(ctx context.Context, actorID, orgID, userID pgtype.UUID, role string) (*sqlc.OrganizationMember, error)
+	RemoveMember(ctx context.Context, actorID, orgID, userID pgtype.UUID) error
+	Invite(ctx context.Context, actorID, orgID pgtype.UUID, email, role string, validFor time.Duration) (string, *sqlc.OrganizationInvitation, error)
+	AcceptInvitation(ctx context.Context, userID pgtype.UUID, email, invitationToken string) (*sqlc.OrganizationMember, error)
+	SwitchOrganization(ctx context.Context, userID, familyID, orgID pgtype.UUID) (*sqlc.OrganizationMember, error)
+	MembershipRole(ctx context.Context, orgID, userID pgtype.UUID) (string, error)
+}
+
+type OrganizationManager struct {
+	auth db.Auth
+}
+
+func NewOrganizationManager(auth db.Auth) OrganizationService {
+	return &OrganizationManager{
+		auth: auth,
+	}
+}
+
+// CreateOrganization creates an organization owned by ownerID
+func (o *OrganizationManager) CreateOrganization(ctx context.Context, ownerID pgtype.UUID, name, slug string) (*sqlc.Organization, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, customError.ErrInvalidOrganizationName
+	}
+	if !organizationSlugPattern.MatchString(slug) {
+		return nil, customError.ErrInvalidOrganizationSlug
+	}
+	_, err := o.auth.GetOrganizationBySlug(ctx, slug)
+	if err == nil {
+		return nil, customError.ErrOrganizationAlreadyExist
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, customError.UnExpectedError
+	}
+
+	// an organization is never left without its owner
+	var org sqlc.Organization
+	err = execTx(ctx, o.auth, func(auth db.Auth) error {
+		var err error
+		org, err = auth.CreateOrganization(ctx, sqlc.CreateOrganizationParams{
+			Name: name,
+			Slug: slug,
+		})
+		if err != nil {
+			// the slug was taken since it was checked
+			if db.IsUniqueViolation(err) {
+				return customError.ErrOrganizationAlreadyExist
+			}
+			return customError.UnExpectedError
+		}
+		_, err = auth.AddOrganizationMember(ctx, sqlc.AddOrganizationMemberParams{
+			OrganizationID: org.ID,
+			UserID:         ownerID,
+			Role:           OrganizationRoleOwner,
+		})
+		if err != nil {
+			return customError.UnExpectedError
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &org, nil
+}
+
+// ListUserOrganizations returns the organizations the user belongs to along
+// with their role in each
+func (o *OrganizationManager) ListUserOrganizations(ctx context.Context, userID pgtype.UUID) ([]sqlc.ListUserOrganizationsRow, error) {
+	orgs, err := o.auth.ListUserOrganizations(ctx, userID)
+	if err != nil {
+		return nil, customError.UnExpectedError
+	}
+	return orgs, nil
+}
+
+// ListMembers returns the members of the organization to any of them
+func (o *OrganizationManager) ListMembers(ctx context.Context, actorID, orgID pgtype.UUID) ([]sqlc.ListOrganizationMembersRow, error) {
+	_, err := o.member(ctx, orgID, actorID)
+	if err != nil {
+		return nil, err
+	}
+	members, err := o.auth.ListOrganizationMembers(ctx, orgID)
+	if err != nil {
+		return nil, customError.UnExpectedError
+	}
+	return members, nil
+}
+
+// UpdateMemberRole changes the role of a member. Only owners can change
+// roles, and the last owner cannot be demoted.
+func (o *OrganizationManager) UpdateMemberRole(ctx context.Context, actorID, orgID, userID pgtype.UUID, role string) (*sqlc.OrganizationMember, error) {
+	if !validOrganizationRole(role) {
+		return nil, customError.ErrInvalidOrganizationRole
+	}
+	var updated sqlc.OrganizationMember
+	err := o.withOrganizationLocked(ctx, orgID, func(tx *OrganizationManager) error {
+		actor, err := tx.member(ctx, orgID, actorID)
+		if err != nil {
+			return err
+		}
+		if actor.Role != OrganizationRoleOwner {
+			return customError.ErrInsufficientOrganizationRole
+		}
+		member, err := tx.member(ctx, orgID, userID)
+		if err != nil {
+			return err
+		}
+		if member.Role == OrganizationRoleOwner && role != OrganizationRoleOwner {
+			if err := tx.keepOwner(ctx, orgID); err != nil {
+				return err
+			}
+		}
+
+		updated, err = tx.auth.UpdateOrganizationMemberRole(ctx, sqlc.UpdateOrganizationMemberRoleParams{
+			OrganizationID: orgID,
+			UserID:         userID,
+			Role:           role,
+		})
+		if err != nil {
+			return customError.UnExpectedError
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+// RemoveMember removes a user from the organization. Members may leave on
+// their own, owners and admins may remove others, but only owners may
+// remove an owner and the last owner cannot be removed.
+func (o *OrganizationManager) RemoveMember(ctx context.Context, actorID, orgID, userID pgtype.UUID) error {
+	return o.withOrganizationLocked(ctx, orgID, func(tx *OrganizationManager) error {
+		actor, err := tx.member(ctx, orgID, actorID)
+		if err != nil {
+			return err
+		}
+		member, err := tx.member(ctx, orgID, userID)
+		if err != nil {
+			return err
+		}
+		if actorID != userID && !canManage(actor.Role, member.Role) {
+			return customError.ErrInsufficientOrganizationRole
+		}
+		if member.Role == OrganizationRoleOwner {
+			if err := tx.keepOwner(ctx, orgID); err != nil {
+				return err
+			}
+		}
+
+		err = tx.auth.RemoveOrganizationMember(ctx, sqlc.RemoveOrganizationMemberParams{
+			OrganizationID: orgID,
+			UserID:         userID,
+		})
+		if err != nil {
+			return customError.UnExpectedError
+		}
+		return nil
+	})
+}
+
+// Invite creates an invitation for email to join the organization with
+// role, valid for validFor, returning the plaintext invitation token. Only
+// its hash is stored. Owners and admins can invite, but only owners can
+// invite owners.
+func (o *OrganizationManager) Invite(ctx context.Context, actorID, orgID pgtype.UUID, email, role string, validFor time.Duration) (string, *sqlc.OrganizationInvitation, error) {
+	if role == "" {
+		role = OrganizationRoleMember
+	}
+	if !validOrganizationRole(role) {
+		return "", nil, customError.ErrInvalidOrganizationRole
+	}
+	actor, err := o.member(ctx, orgID, actorID)
+	if err != nil {
+		return "", nil, err
+	}
+	if !canManage(actor.Role, role) {
+		return "", nil, customError.ErrInsufficientOrganizationRole
+	}
+
+	invitationToken, err := utils.GenerateSecureToken(invitationTokenBytes)
+	if err != nil {
+		return "", nil, customError.UnExpectedError
+	}
+	invitation, err := o.auth.CreateOrganizationInvitation(ctx, sqlc.CreateOrganizationInvitationParams{
+		OrganizationID: orgID,
+		Email:          strings.ToLower(strings.TrimSpace(email)),
+		Role:           role,
+		TokenHash:      utils.HashToken(invitationToken),
+		InvitedBy:      actorID,
+		ExpiresAt:      pgtype.Timestamp{Time: time.Now().Add(validFor), Valid: true},
+	})
+	if err != nil {
+		return "", nil, customError.UnExpectedError
+	}
+	return invitationToken, &invitation, nil
+}
+
+// AcceptInvitation adds the user to the organization of the invitation. The
+// invitation can be used once, before it expires, by the user registered
+// with the invited email.
+func (o *OrganizationManager) AcceptInvitation(ctx context.Context, userID pgtype.UUID, email, invitationToken string) (*sqlc.OrganizationMember, error) {
+	invitation, err := o.auth.GetOrganizationInvitationByTokenHash(ctx, utils.HashToken(invitationToken))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, customError.ErrInvalidInvitation
+		}
+		return nil, customError.UnExpectedError
+	}
+	if invitation.AcceptedAt.Valid || time.Now().After(invitation.ExpiresAt.Time) {
+		return nil, customError.ErrInvalidInvitation
+	}
+	if !strings.EqualFold(invitation.Email, email) {
+		return nil, customError.ErrInvitationEmailMismatch
+	}
+	_, err = o.member(ctx, invitation.OrganizationID, userID)
+	if err == nil {
+		return nil, customError.ErrAlreadyOrganizationMember
+	}
+	if !errors.Is(err, customError.ErrNotOrganizationMember) {
+		return nil, err
+	}
+
+	// the invitation stays usable unless the user is added
+	var member sqlc.OrganizationMember
+	err = execTx(ctx, o.auth, func(auth db.Auth) error {
+		// the conditional update makes sure the invitation is used only once
+		_, err := auth.AcceptOrganizationInvitation(ctx, invitation.ID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return customError.ErrInvalidInvitation
+			}
+			return customError.UnExpectedError
+		}
+		member, err = auth.AddOrganizationMember(ctx, sqlc.AddOrganizationMemberParams{
+			OrganizationID: invitation.OrganizationID,
+			UserID:         userID,
+			Role:           invitation.Role,
+		})
+		if err != nil {
+			// the user joined through another invitation meanwhile
+			if db.IsUniqueViolation(err) {
+				return customError.ErrAlreadyOrganizationMember
+			}
+			return customError.UnExpectedError
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &member, nil
+}
+
+// SwitchOrganization makes the login session of familyID act for the
+// organization, which the user must be a member of. Access tokens issued for
+// the session afterwards, including on refresh, carry the organization.
+func (o *OrganizationManager) SwitchOrganization(ctx context.Context, userID, familyID, orgID pgtype.UUID) (*sqlc.OrganizationMember, error) {
+	member, err := o.member(ctx, orgID, userID)
+	if err != nil {
+		return nil, err
+	}
+	err = o.auth.SetSessionOrganization(ctx, sqlc.SetSessionOrganizationParams{
+		FamilyID:       familyID,
+		OrganizationID: orgID,
+	})
+	if err != nil {
+		return nil, customError.UnExpectedError
+	}
+	return member, nil
+}
+
+// MembershipRole returns the role of the user in the organization, or an
+// empty role when the user is not a member
+func (o *OrganizationManager) MembershipRole(ctx context.Context, orgID, userID pgtype.UUID) (string, error) {
+	member, err := o.member(ctx, orgID, userID)
+	if err != nil {
+		if errors.Is(err, customError.ErrNotOrganizationMember) {
+			return "", nil
+		}
+		return "", err
+	}
+	return member.Role, nil
+}
+
+func (o *OrganizationManager) member(ctx context.Context, orgID, userID pgtype.UUID) (*sqlc.OrganizationMember, error) {
+	member, err := o.auth.GetOrganizationMember(ctx, sqlc.GetOrganizationMemberParams{
+		OrganizationID: orgID,
+		UserID:         userID,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, customError.ErrNotOrganizationMember
+		}
+		return nil, customError.UnExpectedError
+	}
+	return &member, nil
+}
+
+// withOrganizationLocked runs fn in a transaction holding a lock on the
+// organization. Membership changes read the members with the lock held, so
+// two owners demoting or removing each other at once cannot leave the
+// organization without an owner.
+func (o *OrganizationManager) withOrganizationLocked(ctx context.Context, orgID pgtype.UUID, fn func(tx *OrganizationManager) error) error {
+	return execTx(ctx, o.auth, func(auth db.Auth) error {
+		_, err := auth.LockOrganization(ctx, orgID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return customError.ErrNotOrganizationMember
+			}
+			return customError.UnExpectedError
+		}
+		return fn(&OrganizationManager{auth: auth})
+	})
+}
+
+// keepOwner fails when the organization has a single owner left
+func (o *OrganizationManager) keepOwner(ctx context.Context, orgID pgtype.UUID) error {
+	owners, err := o.auth.CountOrganizationOwners(ctx, orgID)
+	if err != nil {
+		return customError.UnExpectedError
+	}
+	if owners <= 1 {
+		return customError.ErrLastOrganizationOwner
+	}
+	return nil
+}
+
+func validOrganizationRole(role string) bool {
+	return organizationRoleRank(role) > 0
+}
+
+// canManage reports whether a member with actorRole may invite, remove or
+// otherwise manage a member with role
+func canManage(actorRole, role string) bool {
+	switch actorRole {
+	case OrganizationRoleOwner:
+		return true
+	case OrganizationRoleAdmin:
+		return organizationRoleRank(role) < organizationRoleRank(OrganizationRoleOwner)
+	}
+	return false
+}
+
+func organizationRoleRank(role string) int {
+	switch role {
+	case OrganizationRoleOwner:
+		return 3
+	case OrganizationRoleAdmin:
+		return 2
+	case OrganizationRoleMember:
+		return 1
+	}
+	return 0
+}
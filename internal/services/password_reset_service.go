@@ -0,0 +1,140 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	customError "github.com/suryansh74/auth-package/internal/apperrors"
+	"github.com/suryansh74/auth-package/internal/db"
+	"github.com/suryansh74/auth-package/internal/db/sqlc"
+	"github.com/suryansh74/auth-package/internal/utils"
+)
+
+// resetTokenBytes is the amount of randomness in a password reset token
+const resetTokenBytes = 32
+
+// PasswordResetService lets users who forgot their password set a new one
+// with a token sent to their email. Tokens are opaque, only their SHA-256
+// hash is stored, and each can be used once before it expires.
+type PasswordResetService interface {
+	RequestReset(ctx context.Context, email string, validFor time.Duration) (string, *sqlc.User, error)
+	ResetPassword(ctx context.Context, resetToken, newPassword string) (*sqlc.User, error)
+}
+
+type PasswordResetManager struct {
+	auth db.Auth
+}
+
+func NewPasswordResetManager(auth db.Auth) PasswordResetService {
+	return &PasswordResetManager{
+		auth: auth,
+	}
+}
+
+// RequestReset creates a reset token for the user registered with email,
+// replacing any token requested before. It returns ErrUserNotFound for
+// unknown emails, which callers must not reveal.
+func (p *PasswordResetManager) RequestReset(ctx context.Context, email string, validFor time.Duration) (string, *sqlc.User, error) {
+	user, err := p.auth.GetUserByEmail(ctx, email)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", nil, customError.ErrUserNotFound
+		}
+		return "", nil, customError.UnExpectedError
+	}
+
+	if err := p.auth.InvalidateUserPasswordResetTokens(ctx, user.ID); err != nil {
+		return "", nil, customError.UnExpectedError
+	}
+	resetToken, err := utils.GenerateSecureToken(resetTokenBytes)
+	if err != nil {
+		return "", nil, customError.UnExpectedError
+	}
+	_, err = p.auth.CreatePasswordResetToken(ctx, sqlc.CreatePasswordResetTokenParams{
+		UserID:    user.ID,
+		TokenHash: utils.HashToken(resetToken),
+		ExpiresAt: pgtype.Timestamp{Time: time.Now().Add(validFor), Valid: true},
+	})
+	if err != nil {
+		return "", nil, customError.UnExpectedError
+	}
+	return resetToken, &user, nil
+}
+
+// ResetPassword consumes the reset token and sets the new password, which
+// also unlocks a locked account. Both happen in one transaction, so a
+// failed update leaves the token usable. The returned user's
+// PasswordChangedAt tells which tokens and sessions were issued with the old
+// password.
+func (p *PasswordResetManager) ResetPassword(ctx context.Context, resetToken, newPassword string) (*sqlc.User, error) {
+	reset, err := p.auth.GetPasswordResetTokenByHash(ctx, utils.HashToken(resetToken))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, customError.ErrInvalidResetToken
+		}
+		return nil, customError.UnExpectedError
+	}
+	if reset.UsedAt.Valid || time.Now().After(reset.ExpiresAt.Time) {
+		return nil, customError.ErrInvalidResetToken
+	}
+	// checked before using the token, so a weak password can be corrected
+	// with the same link
+	if !utils.ValidPassword(newPassword) {
+		return nil, customError.ErrWeakPassword
+	}
+
+	hashedPassword, err := utils.HashedPassword(newPassword)
+	if err != nil {
+		return nil, customError.UnExpectedError
+	}
+
+	var user sqlc.User
+	err = execTx(ctx, p.auth, func(auth db.Auth) error {
+		// the conditional update makes sure the token is only used once even
+		// by concurrent requests
+		_, err := auth.UsePasswordResetToken(ctx, reset.ID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return customError.ErrInvalidResetToken
+			}
+			return customError.UnExpectedError
+		}
+		user, err = auth.UpdateUserPassword(ctx, sqlc.UpdateUserPasswordParams{
+			ID:                reset.UserID,
+			Password:          hashedPassword,
+			PasswordChangedAt: pgtype.Timestamp{Time: time.Now(), Valid: true},
+		})
+		if err != nil {
+			// the account was deleted since the token was issued
+			if errors.Is(err, sql.ErrNoRows) {
+				return customError.ErrInvalidResetToken
+			}
+			return customError.UnExpectedError
+		}
+		if err := auth.InvalidateUserPasswordResetTokens(ctx, user.ID); err != nil {
+			return customError.UnExpectedError
+		}
+		// locked accounts wait for their owner to choose a new password
+		if user.Status == UserStatusLocked {
+			unlocked, err := auth.UpdateUserStatus(ctx, sqlc.UpdateUserStatusParams{
+				Status:        reinstatedStatus(&user),
+				StatusReason:  pgtype.Text{String: "password reset", Valid: true},
+				ID:            user.ID,
+				CurrentStatus: UserStatusLocked,
+			})
+			if err == nil {
+				user = unlocked
+			} else if !errors.Is(err, sql.ErrNoRows) {
+				return customError.UnExpectedError
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
@@ -0,0 +1,363 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"regexp"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	customError "github.com/suryansh74/auth-package/internal/apperrors"
+	"github.com/suryansh74/auth-package/internal/db"
+	"github.com/suryansh74/auth-package/internal/db/sqlc"
+)
+
+const (
+	// SuperAdminRole is granted every permission, whether or not the
+	// permission was explicitly granted to the role
+	SuperAdminRole = "super_admin"
+	// ManageRBACPermission allows managing roles, permissions and their
+	// assignments through the admin endpoints
+	ManageRBACPermission = "rbac:manage"
+)
+
+var rbacNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_.:-]{0,99}$`)
+
+// RoleService manages roles, the permissions granted to them and the roles
+// assigned to users. Roles and permissions are addressed by name.
+type RoleService interface {
+	CreateRole(ctx context.Context, name, description string) (*sqlc.Role, error)
+	DeleteRole(ctx context.Context, name string) error
+	ListRoles(ctx context.Context) ([]sqlc.Role, error)
+	CreatePermission(ctx context.Context, name, description string) (*sqlc.Permission, error)
+	DeletePermission(ctx context.Context, name string) error
+	ListPermissions(ctx context.Context) ([]sqlc.Permission, error)
+	ListRolePermissions(ctx context.Context, role string) ([]sqlc.Permission, error)
+	GrantPermission(ctx context.Context, role, permission string) error
+	RevokePermission(ctx context.Context, role, permission string) error
+	AssignRole(ctx context.Context, userID pgtype.UUID, role string) error
+	RevokeRole(ctx context.Context, userID pgtype.UUID, role string) error
+	UserRoles(ctx context.Context, userID pgtype.UUID) ([]string, error)
+	UserPermissions(ctx context.Context, userID pgtype.UUID) ([]string, error)
+	BootstrapSuperAdmin(ctx context.Context, email string) error
+}
+
+type RoleManager struct {
+	auth db.Auth
+}
+
+func NewRoleManager(auth db.Auth) RoleService {
+	return &RoleManager{
+		auth: auth,
+	}
+}
+
+func (r *RoleManager) CreateRole(ctx context.Context, name, description string) (*sqlc.Role, error) {
+	if !rbacNamePattern.MatchString(name) {
+		return nil, customError.ErrInvalidRBACName
+	}
+	_, err := r.role(ctx, name)
+	if err == nil {
+		return nil, customError.ErrRoleAlreadyExist
+	}
+	if !errors.Is(err, customError.ErrRoleNotFound) {
+		return nil, err
+	}
+
+	role, err := r.auth.CreateRole(ctx, sqlc.CreateRoleParams{
+		Name:        name,
+		Description: description,
+	})
+	if err != nil {
+		return nil, customError.UnExpectedError
+	}
+	return &role, nil
+}
+
+// DeleteRole removes the role along with its grants and user assignments
+func (r *RoleManager) DeleteRole(ctx context.Context, name string) error {
+	role, err := r.role(ctx, name)
+	if err != nil {
+		return err
+	}
+	err = r.auth.DeleteRole(ctx, role.ID)
+	if err != nil {
+		return customError.UnExpectedError
+	}
+	return nil
+}
+
+func (r *RoleManager) ListRoles(ctx context.Context) ([]sqlc.Role, error) {
+	roles, err := r.auth.ListRoles(ctx)
+	if err != nil {
+		return nil, customError.UnExpectedError
+	}
+	return roles, nil
+}
+
+func (r *RoleManager) CreatePermission(ctx context.Context, name, description string) (*sqlc.Permission, error) {
+	if !rbacNamePattern.MatchString(name) {
+		return nil, customError.ErrInvalidRBACName
+	}
+	_, err := r.permission(ctx, name)
+	if err == nil {
+		return nil, customError.ErrPermissionAlreadyExist
+	}
+	if !errors.Is(err, customError.ErrPermissionNotFound) {
+		return nil, err
+	}
+
+	permission, err := r.auth.CreatePermission(ctx, sqlc.CreatePermissionParams{
+		Name:        name,
+		Description: description,
+	})
+	if err != nil {
+		return nil, customError.UnExpectedError
+	}
+	return &permission, nil
+}
+
+// DeletePermission removes the permission and revokes it from every role
+func (r *RoleManager) DeletePermission(ctx context.Context, name string) error {
+	permission, err := r.permission(ctx, name)
+	if err != nil {
+		return err
+	}
+	err = r.auth.DeletePermission(ctx, permission.ID)
+	if err != nil {
+		return customError.UnExpectedError
+	}
+	return nil
+}
+
+func (r *RoleManager) ListPermissions(ctx context.Context) ([]sqlc.Permission, error) {
+	permissions, err := r.auth.ListPermissions(ctx)
+	if err != nil {
+		return nil, customError.UnExpectedError
+	}
+	return permissions, nil
+}
+
+func (r *RoleManager) ListRolePermissions(ctx context.Context, roleName string) ([]sqlc.Permission, error) {
+	role, err := r.role(ctx, roleName)
+	if err != nil {
+		return nil, err
+	}
+	permissions, err := r.auth.ListRolePermissions(ctx, role.ID)
+	if err != nil {
+		return nil, customError.UnExpectedError
+	}
+	return permissions, nil
+}
+
+// GrantPermission grants permission to role, granting it again is a no-op
+func (r *RoleManager) GrantPermission(ctx context.Context, roleName, permissionName string) error {
+	role, err := r.role(ctx, roleName)
+	if err != nil {
+		return err
+	}
+	permission, err := r.permission(ctx, permissionName)
+	if err != nil {
+		return err
+	}
+	err = r.auth.GrantRolePermission(ctx, sqlc.GrantRolePermissionParams{
+		RoleID:       role.ID,
+		PermissionID: permission.ID,
+	})
+	if err != nil {
+		return customError.UnExpectedError
+	}
+	return nil
+}
+
+func (r *RoleManager) RevokePermission(ctx context.Context, roleName, permissionName string) error {
+	role, err := r.role(ctx, roleName)
+	if err != nil {
+		return err
+	}
+	permission, err := r.permission(ctx, permissionName)
+	if err != nil {
+		return err
+	}
+	err = r.auth.RevokeRolePermission(ctx, sqlc.RevokeRolePermissionParams{
+		RoleID:       role.ID,
+		PermissionID: permission.ID,
+	})
+	if err != nil {
+		return customError.UnExpectedError
+	}
+	return nil
+}
+
+// AssignRole assigns role to the user, assigning it again is a no-op
+func (r *RoleManager) AssignRole(ctx context.Context, userID pgtype.UUID, roleName string) error {
+	role, err := r.role(ctx, roleName)
+	if err != nil {
+		return err
+	}
+	_, err = r.auth.GetUser(ctx, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return customError.ErrUserNotFound
+		}
+		return customError.UnExpectedError
+	}
+	err = r.auth.AssignUserRole(ctx, sqlc.AssignUserRoleParams{
+		UserID: userID,
+		RoleID: role.ID,
+	})
+	if err != nil {
+		return customError.UnExpectedError
+	}
+	return nil
+}
+
+func (r *RoleManager) RevokeRole(ctx context.Context, userID pgtype.UUID, roleName string) error {
+	role, err := r.role(ctx, roleName)
+	if err != nil {
+		return err
+	}
+	err = r.auth.RevokeUserRole(ctx, sqlc.RevokeUserRoleParams{
+		UserID: userID,
+		RoleID: role.ID,
+	})
+	if err != nil {
+		return customError.UnExpectedError
+	}
+	return nil
+}
+
+// UserRoles returns the names of the roles assigned to the user
+func (r *RoleManager) UserRoles(ctx context.Context, userID pgtype.UUID) ([]string, error) {
+	roles, err := r.auth.ListUserRoles(ctx, userID)
+	if err != nil {
+		return nil, customError.UnExpectedError
+	}
+	return roles, nil
+}
+
+// UserPermissions returns the names of the permissions granted to the user
+// through their roles. A super admin holds every permission.
+func (r *RoleManager) UserPermissions(ctx context.Context, userID pgtype.UUID) ([]string, error) {
+	roles, err := r.UserRoles(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !hasRole(roles, SuperAdminRole) {
+		permissions, err := r.auth.ListUserPermissions(ctx, userID)
+		if err != nil {
+			return nil, customError.UnExpectedError
+		}
+		return permissions, nil
+	}
+
+	all, err := r.ListPermissions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	permissions := make([]string, 0, len(all))
+	for _, permission := range all {
+		permissions = append(permissions, permission.Name)
+	}
+	return permissions, nil
+}
+
+// BootstrapSuperAdmin makes sure the super admin role and the permission to
+// manage roles exist, and assigns the role to the user registered with email
+// unless an active user already holds it. It is safe to call on every start.
+// Whoever registers the address first could claim it, so the user must have
+// verified the email and have an active account, otherwise
+// ErrSuperAdminIneligible is returned.
+func (r *RoleManager) BootstrapSuperAdmin(ctx context.Context, email string) error {
+	role, err := r.ensureRole(ctx, SuperAdminRole, "Holds every permission")
+	if err != nil {
+		return err
+	}
+	permission, err := r.ensurePermission(ctx, ManageRBACPermission, "Manage roles, permissions and their assignments")
+	if err != nil {
+		return err
+	}
+	err = r.auth.GrantRolePermission(ctx, sqlc.GrantRolePermissionParams{
+		RoleID:       role.ID,
+		PermissionID: permission.ID,
+	})
+	if err != nil {
+		return customError.UnExpectedError
+	}
+
+	if email == "" {
+		return nil
+	}
+	holders, err := r.auth.CountRoleUsers(ctx, role.ID)
+	if err != nil {
+		return customError.UnExpectedError
+	}
+	if holders > 0 {
+		return nil
+	}
+	user, err := r.auth.GetUserByEmail(ctx, email)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return customError.ErrUserNotFound
+		}
+		return customError.UnExpectedError
+	}
+	if !user.EmailVerifiedAt.Valid || user.Status != UserStatusActive {
+		return customError.ErrSuperAdminIneligible
+	}
+	err = r.auth.AssignUserRole(ctx, sqlc.AssignUserRoleParams{
+		UserID: user.ID,
+		RoleID: role.ID,
+	})
+	if err != nil {
+		return customError.UnExpectedError
+	}
+	return nil
+}
+
+func (r *RoleManager) role(ctx context.Context, name string) (*sqlc.Role, error) {
+	role, err := r.auth.GetRoleByName(ctx, name)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, customError.ErrRoleNotFound
+		}
+		return nil, customError.UnExpectedError
+	}
+	return &role, nil
+}
+
+func (r *RoleManager) permission(ctx context.Context, name string) (*sqlc.Permission, error) {
+	permission, err := r.auth.GetPermissionByName(ctx, name)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, customError.ErrPermissionNotFound
+		}
+		return nil, customError.UnExpectedError
+	}
+	return &permission, nil
+}
+
+func (r *RoleManager) ensureRole(ctx context.Context, name, description string) (*sqlc.Role, error) {
+	role, err := r.CreateRole(ctx, name, description)
+	if errors.Is(err, customError.ErrRoleAlreadyExist) {
+		return r.role(ctx, name)
+	}
+	return role, err
+}
+
+func (r *RoleManager) ensurePermission(ctx context.Context, name, description string) (*sqlc.Permission, error) {
+	permission, err := r.CreatePermission(ctx, name, description)
+	if errors.Is(err, customError.ErrPermissionAlreadyExist) {
+		return r.permission(ctx, name)
+	}
+	return permission, err
+}
+
+func hasRole(roles []string, role string) bool {
+	for _, r := range roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,144 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	customError "github.com/suryansh74/auth-package/internal/apperrors"
+	"github.com/suryansh74/auth-package/internal/db"
+	"github.com/suryansh74/auth-package/internal/db/sqlc"
+	"github.com/suryansh74/auth-package/internal/dto"
+	"github.com/suryansh74/auth-package/internal/utils"
+)
+
+// refreshTokenBytes is the amount of randomness in an opaque refresh token
+const refreshTokenBytes = 32
+
+// SessionService manages refresh sessions. Refresh tokens are opaque, only
+// their SHA-256 hash is stored, and each token can be used exactly once.
+type SessionService interface {
+	CreateSession(ctx context.Context, userID pgtype.UUID, meta dto.SessionMetadata) (string, *sqlc.Session, error)
+	RefreshSession(ctx context.Context, refreshToken string, meta dto.SessionMetadata) (string, *sqlc.Session, error)
+	RevokeSession(ctx context.Context, familyID pgtype.UUID) error
+	RevokeUserSessions(ctx context.Context, userID pgtype.UUID) error
+	RevokeOtherUserSessions(ctx context.Context, userID, familyID pgtype.UUID) error
+}
+
+type SessionManager struct {
+	auth                 db.Auth
+	refreshTokenDuration time.Duration
+}
+
+func NewSessionManager(auth db.Auth, refreshTokenDuration time.Duration) SessionService {
+	return &SessionManager{
+		auth:                 auth,
+		refreshTokenDuration: refreshTokenDuration,
+	}
+}
+
+// CreateSession starts a new token family for the user and returns the
+// plaintext refresh token along with the stored session
+func (s *SessionManager) CreateSession(ctx context.Context, userID pgtype.UUID, meta dto.SessionMetadata) (string, *sqlc.Session, error) {
+	familyID := pgtype.UUID{Bytes: uuid.New(), Valid: true}
+	return s.issue(ctx, userID, familyID, pgtype.UUID{}, meta)
+}
+
+// RefreshSession rotates the presented refresh token. The old token is marked
+// as used and a new one from the same family is returned. Presenting a token
+// that was already used revokes the whole family, since it means the token
+// has been stolen either by the caller or by whoever used it first.
+func (s *SessionManager) RefreshSession(ctx context.Context, refreshToken string, meta dto.SessionMetadata) (string, *sqlc.Session, error) {
+	session, err := s.auth.GetSessionByRefreshTokenHash(ctx, utils.HashToken(refreshToken))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", nil, customError.ErrInvalidRefreshToken
+		}
+		return "", nil, customError.UnExpectedError
+	}
+
+	if session.RevokedAt.Valid {
+		return "", nil, customError.ErrInvalidRefreshToken
+	}
+	if session.UsedAt.Valid {
+		return "", nil, s.revokeFamily(ctx, session.FamilyID)
+	}
+	if time.Now().After(session.ExpiresAt.Time) {
+		return "", nil, customError.ErrExpiredRefreshToken
+	}
+
+	// the conditional update makes sure only one of two concurrent
+	// requests with the same token wins the rotation
+	_, err = s.auth.MarkSessionUsed(ctx, session.ID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", nil, s.revokeFamily(ctx, session.FamilyID)
+		}
+		return "", nil, customError.UnExpectedError
+	}
+
+	// the new token keeps acting for the organization the session switched to
+	return s.issue(ctx, session.UserID, session.FamilyID, session.OrganizationID, meta)
+}
+
+// RevokeSession ends a login session by revoking every refresh token of
+// its family
+func (s *SessionManager) RevokeSession(ctx context.Context, familyID pgtype.UUID) error {
+	if err := s.auth.RevokeSessionFamily(ctx, familyID); err != nil {
+		return customError.UnExpectedError
+	}
+	return nil
+}
+
+// RevokeUserSessions ends every login session of the user
+func (s *SessionManager) RevokeUserSessions(ctx context.Context, userID pgtype.UUID) error {
+	if err := s.auth.RevokeUserSessions(ctx, userID); err != nil {
+		return customError.UnExpectedError
+	}
+	return nil
+}
+
+// RevokeOtherUserSessions ends every login session of the user except the
+// one of familyID
+func (s *SessionManager) RevokeOtherUserSessions(ctx context.Context, userID, familyID pgtype.UUID) error {
+	err := s.auth.RevokeOtherUserSessions(ctx, sqlc.RevokeOtherUserSessionsParams{
+		UserID:   userID,
+		FamilyID: familyID,
+	})
+	if err != nil {
+		return customError.UnExpectedError
+	}
+	return nil
+}
+
+func (s *SessionManager) issue(ctx context.Context, userID, familyID, organizationID pgtype.UUID, meta dto.SessionMetadata) (string, *sqlc.Session, error) {
+	refreshToken, err := utils.GenerateSecureToken(refreshTokenBytes)
+	if err != nil {
+		return "", nil, customError.UnExpectedError
+	}
+
+	arg := sqlc.CreateSessionParams{
+		UserID:           userID,
+		FamilyID:         familyID,
+		RefreshTokenHash: utils.HashToken(refreshToken),
+		UserAgent:        meta.UserAgent,
+		ClientIp:         meta.ClientIP,
+		ExpiresAt:        pgtype.Timestamp{Time: time.Now().Add(s.refreshTokenDuration), Valid: true},
+		OrganizationID:   organizationID,
+	}
+	session, err := s.auth.CreateSession(ctx, arg)
+	if err != nil {
+		return "", nil, customError.UnExpectedError
+	}
+	return refreshToken, &session, nil
+}
+
+func (s *SessionManager) revokeFamily(ctx context.Context, familyID pgtype.UUID) error {
+	if err := s.auth.RevokeSessionFamily(ctx, familyID); err != nil {
+		return customError.UnExpectedError
+	}
+	return customError.ErrRefreshTokenReused
+}
@@ -0,0 +1,231 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+
+	customError "github.com/suryansh74/auth-package/internal/apperrors"
+	"github.com/suryansh74/auth-package/internal/db/mock"
+	"github.com/suryansh74/auth-package/internal/db/sqlc"
+	"github.com/suryansh74/auth-package/internal/services"
+	"github.com/suryansh74/auth-package/internal/utils"
+)
+
+const gracePeriod = 30 * 24 * time.Hour
+
+func TestDeleteAccount(t *testing.T) {
+	password := "password123"
+	hashedPassword, _ := utils.HashedPassword(password)
+	user := sqlc.User{
+		ID:       pgtype.UUID{Bytes: uuid.New(), Valid: true},
+		Email:    "john@example.com",
+		Password: hashedPassword,
+	}
+	orgID := pgtype.UUID{Bytes: uuid.New(), Valid: true}
+
+	testCases := []struct {
+		name          string
+		password      string
+		buildStubs    func(mockAuth *mock.MockAuth)
+		checkResponse func(t *testing.T, user *sqlc.User, err error)
+	}{
+		{
+			name:     "OK",
+			password: password,
+			buildStubs: func(mockAuth *mock.MockAuth) {
+				mockAuth.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.ID)).Times(1).Return(user, nil)
+				expectTx(mockAuth).Times(1)
+				mockAuth.EXPECT().LockOwnedOrganizations(gomock.Any(), gomock.Eq(user.ID)).Times(1).Return([]pgtype.UUID{orgID}, nil)
+				mockAuth.EXPECT().CountOrganizationOwners(gomock.Any(), gomock.Eq(orgID)).Times(1).Return(int64(2), nil)
+				mockAuth.EXPECT().
+					SoftDeleteUser(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(ctx interface{}, params sqlc.SoftDeleteUserParams) (sqlc.User, error) {
+						require.Equal(t, user.ID, params.ID)
+						require.WithinDuration(t, time.Now(), params.DeletedAt.Time, time.Second)
+						deleted := user
+						deleted.DeletedAt = params.DeletedAt
+						return deleted, nil
+					})
+				// links sent before the deletion stop working
+				mockAuth.EXPECT().InvalidateUserPasswordResetTokens(gomock.Any(), gomock.Eq(user.ID)).Times(1).Return(nil)
+				mockAuth.EXPECT().CancelUserEmailChangeRequests(gomock.Any(), gomock.Eq(user.ID)).Times(1).Return(nil)
+			},
+			checkResponse: func(t *testing.T, deleted *sqlc.User, err error) {
+				require.NoError(t, err)
+				require.True(t, deleted.DeletedAt.Valid)
+			},
+		},
+		{
+			name:     "SoleOrganizationOwner",
+			password: password,
+			buildStubs: func(mockAuth *mock.MockAuth) {
+				mockAuth.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.ID)).Times(1).Return(user, nil)
+				expectTx(mockAuth).Times(1)
+				mockAuth.EXPECT().LockOwnedOrganizations(gomock.Any(), gomock.Eq(user.ID)).Times(1).Return([]pgtype.UUID{orgID}, nil)
+				mockAuth.EXPECT().CountOrganizationOwners(gomock.Any(), gomock.Eq(orgID)).Times(1).Return(int64(1), nil)
+				mockAuth.EXPECT().SoftDeleteUser(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, deleted *sqlc.User, err error) {
+				require.Equal(t, customError.ErrLastOrganizationOwner, err)
+				require.Nil(t, deleted)
+			},
+		},
+		{
+			name:     "IncorrectPassword",
+			password: "wrong-password1",
+			buildStubs: func(mockAuth *mock.MockAuth) {
+				mockAuth.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.ID)).Times(1).Return(user, nil)
+				mockAuth.EXPECT().SoftDeleteUser(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, deleted *sqlc.User, err error) {
+				require.Equal(t, customError.ErrIncorrectPassword, err)
+				require.Nil(t, deleted)
+			},
+		},
+		{
+			name:     "AlreadyDeleted",
+			password: password,
+			buildStubs: func(mockAuth *mock.MockAuth) {
+				mockAuth.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.ID)).Times(1).Return(sqlc.User{}, sql.ErrNoRows)
+				mockAuth.EXPECT().SoftDeleteUser(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, deleted *sqlc.User, err error) {
+				require.Equal(t, customError.ErrUserNotFound, err)
+				require.Nil(t, deleted)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockAuth := mock.NewMockAuth(ctrl)
+			tc.buildStubs(mockAuth)
+
+			accounts := services.NewAccountManager(mockAuth, gracePeriod)
+			deleted, err := accounts.DeleteAccount(context.Background(), user.ID, tc.password)
+
+			tc.checkResponse(t, deleted, err)
+		})
+	}
+}
+
+func TestRestoreAccount(t *testing.T) {
+	password := "password123"
+	hashedPassword, _ := utils.HashedPassword(password)
+	user := sqlc.User{
+		ID:        pgtype.UUID{Bytes: uuid.New(), Valid: true},
+		Email:     "john@example.com",
+		Password:  hashedPassword,
+		DeletedAt: pgtype.Timestamp{Time: time.Now().Add(-time.Hour), Valid: true},
+	}
+
+	testCases := []struct {
+		name          string
+		password      string
+		buildStubs    func(mockAuth *mock.MockAuth)
+		checkResponse func(t *testing.T, user *sqlc.User, err error)
+	}{
+		{
+			name:     "OK",
+			password: password,
+			buildStubs: func(mockAuth *mock.MockAuth) {
+				mockAuth.EXPECT().GetDeletedUserByEmail(gomock.Any(), gomock.Eq(user.Email)).Times(1).Return(user, nil)
+				mockAuth.EXPECT().
+					RestoreUser(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(ctx interface{}, params sqlc.RestoreUserParams) (sqlc.User, error) {
+						require.Equal(t, user.ID, params.ID)
+						require.WithinDuration(t, time.Now().Add(-gracePeriod), params.DeletedAfter.Time, time.Second)
+						restored := user
+						restored.DeletedAt = pgtype.Timestamp{}
+						return restored, nil
+					})
+			},
+			checkResponse: func(t *testing.T, restored *sqlc.User, err error) {
+				require.NoError(t, err)
+				require.False(t, restored.DeletedAt.Valid)
+			},
+		},
+		{
+			name:     "IncorrectPassword",
+			password: "wrong-password1",
+			buildStubs: func(mockAuth *mock.MockAuth) {
+				mockAuth.EXPECT().GetDeletedUserByEmail(gomock.Any(), gomock.Eq(user.Email)).Times(1).Return(user, nil)
+				mockAuth.EXPECT().RestoreUser(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, restored *sqlc.User, err error) {
+				require.Equal(t, customError.ErrIncorrectPassword, err)
+			},
+		},
+		{
+			name:     "NotDeleted",
+			password: password,
+			buildStubs: func(mockAuth *mock.MockAuth) {
+				mockAuth.EXPECT().GetDeletedUserByEmail(gomock.Any(), gomock.Eq(user.Email)).Times(1).Return(sqlc.User{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, restored *sqlc.User, err error) {
+				require.Equal(t, customError.ErrUserNotFound, err)
+			},
+		},
+		{
+			name:     "GracePeriodOver",
+			password: password,
+			buildStubs: func(mockAuth *mock.MockAuth) {
+				mockAuth.EXPECT().GetDeletedUserByEmail(gomock.Any(), gomock.Eq(user.Email)).Times(1).Return(user, nil)
+				mockAuth.EXPECT().RestoreUser(gomock.Any(), gomock.Any()).Times(1).Return(sqlc.User{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, restored *sqlc.User, err error) {
+				require.Equal(t, customError.ErrAccountNotRestorable, err)
+				require.Nil(t, restored)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockAuth := mock.NewMockAuth(ctrl)
+			tc.buildStubs(mockAuth)
+
+			accounts := services.NewAccountManager(mockAuth, gracePeriod)
+			restored, err := accounts.RestoreAccount(context.Background(), user.Email, tc.password)
+
+			tc.checkResponse(t, restored, err)
+		})
+	}
+}
+
+func TestPurgeDeletedAccounts(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockAuth := mock.NewMockAuth(ctrl)
+	mockAuth.EXPECT().
+		PurgeDeletedUsers(gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(ctx interface{}, deletedBefore pgtype.Timestamp) (int64, error) {
+			require.WithinDuration(t, time.Now().Add(-gracePeriod), deletedBefore.Time, time.Second)
+			return 2, nil
+		})
+
+	purged, err := services.NewAccountManager(mockAuth, gracePeriod).PurgeDeletedAccounts(context.Background())
+	require.NoError(t, err)
+	require.EqualValues(t, 2, purged)
+}
@@ -0,0 +1,319 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+
+	customError "github.com/suryansh74/auth-package/internal/apperrors"
+	"github.com/suryansh74/auth-package/internal/db/mock"
+	"github.com/suryansh74/auth-package/internal/db/sqlc"
+	"github.com/suryansh74/auth-package/internal/services"
+	"github.com/suryansh74/auth-package/internal/utils"
+)
+
+func TestRequestEmailChange(t *testing.T) {
+	password := "password123"
+	hashedPassword, _ := utils.HashedPassword(password)
+	user := sqlc.User{
+		ID:       pgtype.UUID{Bytes: uuid.New(), Valid: true},
+		Name:     "John Doe",
+		Email:    "john@example.com",
+		Password: hashedPassword,
+	}
+
+	testCases := []struct {
+		name       string
+		password   string
+		newEmail   string
+		buildStubs func(mockAuth *mock.MockAuth)
+		checkError func(t *testing.T, tokens *services.EmailChangeTokens, request *sqlc.EmailChangeRequest, err error)
+	}{
+		{
+			name:     "OK",
+			password: password,
+			newEmail: "johnny@example.com",
+			buildStubs: func(mockAuth *mock.MockAuth) {
+				mockAuth.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.ID)).Times(1).Return(user, nil)
+				mockAuth.EXPECT().
+					GetUserByEmail(gomock.Any(), gomock.Eq("johnny@example.com")).
+					Times(1).
+					Return(sqlc.User{}, sql.ErrNoRows)
+				mockAuth.EXPECT().
+					GetDeletedUserByEmail(gomock.Any(), gomock.Eq("johnny@example.com")).
+					Times(1).
+					Return(sqlc.User{}, sql.ErrNoRows)
+				// a pending change is replaced
+				mockAuth.EXPECT().CancelUserEmailChangeRequests(gomock.Any(), gomock.Eq(user.ID)).Times(1).Return(nil)
+				mockAuth.EXPECT().
+					CreateEmailChangeRequest(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(ctx interface{}, params sqlc.CreateEmailChangeRequestParams) (sqlc.EmailChangeRequest, error) {
+						return sqlc.EmailChangeRequest{
+							UserID:           params.UserID,
+							OldEmail:         params.OldEmail,
+							NewEmail:         params.NewEmail,
+							ConfirmTokenHash: params.ConfirmTokenHash,
+							CancelTokenHash:  params.CancelTokenHash,
+							ExpiresAt:        params.ExpiresAt,
+						}, nil
+					})
+			},
+			checkError: func(t *testing.T, tokens *services.EmailChangeTokens, request *sqlc.EmailChangeRequest, err error) {
+				require.NoError(t, err)
+				require.NotEqual(t, tokens.Confirm, tokens.Cancel)
+				require.Equal(t, utils.HashToken(tokens.Confirm), request.ConfirmTokenHash)
+				require.Equal(t, utils.HashToken(tokens.Cancel), request.CancelTokenHash)
+				require.Equal(t, user.Email, request.OldEmail)
+				require.Equal(t, "johnny@example.com", request.NewEmail)
+			},
+		},
+		{
+			name:     "IncorrectPassword",
+			password: "wrong-password1",
+			newEmail: "johnny@example.com",
+			buildStubs: func(mockAuth *mock.MockAuth) {
+				mockAuth.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.ID)).Times(1).Return(user, nil)
+				mockAuth.EXPECT().CreateEmailChangeRequest(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkError: func(t *testing.T, tokens *services.EmailChangeTokens, request *sqlc.EmailChangeRequest, err error) {
+				require.Equal(t, customError.ErrIncorrectPassword, err)
+			},
+		},
+		{
+			name:     "InvalidEmail",
+			password: password,
+			newEmail: "John <johnny@example.com>",
+			buildStubs: func(mockAuth *mock.MockAuth) {
+				mockAuth.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.ID)).Times(1).Return(user, nil)
+				mockAuth.EXPECT().CreateEmailChangeRequest(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkError: func(t *testing.T, tokens *services.EmailChangeTokens, request *sqlc.EmailChangeRequest, err error) {
+				require.Equal(t, customError.ErrInvalidEmail, err)
+			},
+		},
+		{
+			name:     "SameEmail",
+			password: password,
+			newEmail: user.Email,
+			buildStubs: func(mockAuth *mock.MockAuth) {
+				mockAuth.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.ID)).Times(1).Return(user, nil)
+				mockAuth.EXPECT().CreateEmailChangeRequest(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkError: func(t *testing.T, tokens *services.EmailChangeTokens, request *sqlc.EmailChangeRequest, err error) {
+				require.Equal(t, customError.ErrEmailNotChanged, err)
+			},
+		},
+		{
+			name:     "EmailTaken",
+			password: password,
+			newEmail: "jane@example.com",
+			buildStubs: func(mockAuth *mock.MockAuth) {
+				mockAuth.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.ID)).Times(1).Return(user, nil)
+				mockAuth.EXPECT().
+					GetUserByEmail(gomock.Any(), gomock.Eq("jane@example.com")).
+					Times(1).
+					Return(sqlc.User{Email: "jane@example.com"}, nil)
+				mockAuth.EXPECT().CreateEmailChangeRequest(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkError: func(t *testing.T, tokens *services.EmailChangeTokens, request *sqlc.EmailChangeRequest, err error) {
+				require.Equal(t, customError.ErrUserAlreadyExist, err)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockAuth := mock.NewMockAuth(ctrl)
+			tc.buildStubs(mockAuth)
+
+			changeService := services.NewEmailChangeManager(mockAuth)
+			tokens, request, err := changeService.RequestEmailChange(context.Background(), user.ID, tc.password, tc.newEmail, time.Hour)
+
+			tc.checkError(t, tokens, request, err)
+		})
+	}
+}
+
+func pendingEmailChange(userID pgtype.UUID) sqlc.EmailChangeRequest {
+	return sqlc.EmailChangeRequest{
+		ID:        pgtype.UUID{Bytes: uuid.New(), Valid: true},
+		UserID:    userID,
+		OldEmail:  "john@example.com",
+		NewEmail:  "johnny@example.com",
+		ExpiresAt: pgtype.Timestamp{Time: time.Now().Add(time.Hour), Valid: true},
+	}
+}
+
+func TestConfirmEmailChange(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockAuth := mock.NewMockAuth(ctrl)
+	userID := pgtype.UUID{Bytes: uuid.New(), Valid: true}
+	request := pendingEmailChange(userID)
+
+	mockAuth.EXPECT().
+		GetEmailChangeRequestByConfirmTokenHash(gomock.Any(), gomock.Eq(utils.HashToken("confirm-token"))).
+		Times(1).
+		Return(request, nil)
+	mockAuth.EXPECT().
+		GetUserByEmail(gomock.Any(), gomock.Eq(request.NewEmail)).
+		Times(1).
+		Return(sqlc.User{}, sql.ErrNoRows)
+	mockAuth.EXPECT().
+		GetDeletedUserByEmail(gomock.Any(), gomock.Eq(request.NewEmail)).
+		Times(1).
+		Return(sqlc.User{}, sql.ErrNoRows)
+	expectTx(mockAuth).Times(1)
+	mockAuth.EXPECT().
+		ConfirmEmailChangeRequest(gomock.Any(), gomock.Eq(request.ID)).
+		Times(1).
+		Return(request, nil)
+	mockAuth.EXPECT().
+		UpdateUserEmail(gomock.Any(), gomock.Eq(sqlc.UpdateUserEmailParams{
+			NewEmail: request.NewEmail,
+			ID:       userID,
+			OldEmail: request.OldEmail,
+		})).
+		Times(1).
+		Return(sqlc.User{ID: userID, Email: request.NewEmail}, nil)
+
+	changeService := services.NewEmailChangeManager(mockAuth)
+	user, err := changeService.ConfirmEmailChange(context.Background(), "confirm-token")
+	require.NoError(t, err)
+	require.Equal(t, request.NewEmail, user.Email)
+
+	// cancelled and expired requests cannot be confirmed
+	cancelled := pendingEmailChange(userID)
+	cancelled.CancelledAt = pgtype.Timestamp{Time: time.Now(), Valid: true}
+	expired := pendingEmailChange(userID)
+	expired.ExpiresAt = pgtype.Timestamp{Time: time.Now().Add(-time.Minute), Valid: true}
+	for _, request := range []sqlc.EmailChangeRequest{cancelled, expired} {
+		mockAuth.EXPECT().
+			GetEmailChangeRequestByConfirmTokenHash(gomock.Any(), gomock.Any()).
+			Times(1).
+			Return(request, nil)
+
+		user, err = changeService.ConfirmEmailChange(context.Background(), "confirm-token")
+		require.Equal(t, customError.ErrInvalidEmailChange, err)
+		require.Nil(t, user)
+	}
+
+	// the address may be registered between the check and the update
+	mockAuth.EXPECT().GetEmailChangeRequestByConfirmTokenHash(gomock.Any(), gomock.Any()).Times(1).Return(request, nil)
+	mockAuth.EXPECT().GetUserByEmail(gomock.Any(), gomock.Eq(request.NewEmail)).Times(1).Return(sqlc.User{}, sql.ErrNoRows)
+	mockAuth.EXPECT().GetDeletedUserByEmail(gomock.Any(), gomock.Eq(request.NewEmail)).Times(1).Return(sqlc.User{}, sql.ErrNoRows)
+	expectTx(mockAuth).Times(1)
+	mockAuth.EXPECT().ConfirmEmailChangeRequest(gomock.Any(), gomock.Eq(request.ID)).Times(1).Return(request, nil)
+	mockAuth.EXPECT().UpdateUserEmail(gomock.Any(), gomock.Any()).Times(1).Return(sqlc.User{}, &pgconn.PgError{Code: "23505"})
+
+	user, err = changeService.ConfirmEmailChange(context.Background(), "confirm-token")
+	require.Equal(t, customError.ErrUserAlreadyExist, err)
+	require.Nil(t, user)
+}
+
+func TestCancelEmailChange(t *testing.T) {
+	userID := pgtype.UUID{Bytes: uuid.New(), Valid: true}
+
+	t.Run("Pending", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockAuth := mock.NewMockAuth(ctrl)
+		request := pendingEmailChange(userID)
+		mockAuth.EXPECT().GetEmailChangeRequestByCancelTokenHash(gomock.Any(), gomock.Any()).Times(1).Return(request, nil)
+		expectTx(mockAuth).Times(1)
+		mockAuth.EXPECT().CancelEmailChangeRequest(gomock.Any(), gomock.Eq(request.ID)).Times(1).Return(request, nil)
+		mockAuth.EXPECT().GetUser(gomock.Any(), gomock.Eq(userID)).Times(1).Return(sqlc.User{ID: userID, Email: request.OldEmail}, nil)
+		mockAuth.EXPECT().RestoreUserEmail(gomock.Any(), gomock.Any()).Times(0)
+
+		user, reverted, err := services.NewEmailChangeManager(mockAuth).CancelEmailChange(context.Background(), "cancel-token")
+		require.NoError(t, err)
+		require.False(t, reverted)
+		require.Equal(t, request.OldEmail, user.Email)
+	})
+
+	t.Run("ConfirmedIsUndone", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockAuth := mock.NewMockAuth(ctrl)
+		request := pendingEmailChange(userID)
+		request.ConfirmedAt = pgtype.Timestamp{Time: time.Now(), Valid: true}
+		mockAuth.EXPECT().GetEmailChangeRequestByCancelTokenHash(gomock.Any(), gomock.Any()).Times(1).Return(request, nil)
+		mockAuth.EXPECT().GetUserByEmail(gomock.Any(), gomock.Eq(request.OldEmail)).Times(1).Return(sqlc.User{}, sql.ErrNoRows)
+		mockAuth.EXPECT().GetDeletedUserByEmail(gomock.Any(), gomock.Eq(request.OldEmail)).Times(1).Return(sqlc.User{}, sql.ErrNoRows)
+		expectTx(mockAuth).Times(1)
+		mockAuth.EXPECT().CancelEmailChangeRequest(gomock.Any(), gomock.Eq(request.ID)).Times(1).Return(request, nil)
+		mockAuth.EXPECT().
+			CancelOtherUserEmailChangeRequests(gomock.Any(), gomock.Eq(sqlc.CancelOtherUserEmailChangeRequestsParams{
+				UserID: userID,
+				ID:     request.ID,
+			})).
+			Times(1).
+			Return(nil)
+		// the old address is restored even when the email was changed again
+		// after the confirmation
+		mockAuth.EXPECT().
+			RestoreUserEmail(gomock.Any(), gomock.Eq(sqlc.RestoreUserEmailParams{
+				Email: request.OldEmail,
+				ID:    userID,
+			})).
+			Times(1).
+			Return(sqlc.User{ID: userID, Email: request.OldEmail}, nil)
+		mockAuth.EXPECT().UpdateUserEmail(gomock.Any(), gomock.Any()).Times(0)
+
+		user, reverted, err := services.NewEmailChangeManager(mockAuth).CancelEmailChange(context.Background(), "cancel-token")
+		require.NoError(t, err)
+		require.True(t, reverted)
+		require.Equal(t, request.OldEmail, user.Email)
+	})
+
+	t.Run("OldEmailTaken", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockAuth := mock.NewMockAuth(ctrl)
+		request := pendingEmailChange(userID)
+		request.ConfirmedAt = pgtype.Timestamp{Time: time.Now(), Valid: true}
+		mockAuth.EXPECT().GetEmailChangeRequestByCancelTokenHash(gomock.Any(), gomock.Any()).Times(1).Return(request, nil)
+		mockAuth.EXPECT().GetUserByEmail(gomock.Any(), gomock.Eq(request.OldEmail)).Times(1).Return(sqlc.User{}, sql.ErrNoRows)
+		mockAuth.EXPECT().GetDeletedUserByEmail(gomock.Any(), gomock.Eq(request.OldEmail)).Times(1).Return(sqlc.User{}, sql.ErrNoRows)
+		expectTx(mockAuth).Times(1)
+		mockAuth.EXPECT().CancelEmailChangeRequest(gomock.Any(), gomock.Eq(request.ID)).Times(1).Return(request, nil)
+		mockAuth.EXPECT().CancelOtherUserEmailChangeRequests(gomock.Any(), gomock.Any()).Times(1).Return(nil)
+		mockAuth.EXPECT().RestoreUserEmail(gomock.Any(), gomock.Any()).Times(1).Return(sqlc.User{}, &pgconn.PgError{Code: "23505"})
+
+		user, reverted, err := services.NewEmailChangeManager(mockAuth).CancelEmailChange(context.Background(), "cancel-token")
+		require.Equal(t, customError.ErrUserAlreadyExist, err)
+		require.False(t, reverted)
+		require.Nil(t, user)
+	})
+
+	t.Run("AlreadyCancelled", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockAuth := mock.NewMockAuth(ctrl)
+		request := pendingEmailChange(userID)
+		request.CancelledAt = pgtype.Timestamp{Time: time.Now(), Valid: true}
+		mockAuth.EXPECT().GetEmailChangeRequestByCancelTokenHash(gomock.Any(), gomock.Any()).Times(1).Return(request, nil)
+		mockAuth.EXPECT().CancelEmailChangeRequest(gomock.Any(), gomock.Any()).Times(0)
+
+		_, _, err := services.NewEmailChangeManager(mockAuth).CancelEmailChange(context.Background(), "cancel-token")
+		require.Equal(t, customError.ErrInvalidEmailChange, err)
+	})
+}
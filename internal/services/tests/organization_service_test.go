@@ -0,0 +1,449 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+
+	customError "github.com/suryansh74/auth-package/internal/apperrors"
+	"github.com/suryansh74/auth-package/internal/db"
+	"github.com/suryansh74/auth-package/internal/db/mock"
+	"github.com/suryansh74/auth-package/internal/db/sqlc"
+	"github.com/suryansh74/auth-package/internal/services"
+	"github.com/suryansh74/auth-package/internal/utils"
+)
+
+// expectTx runs the functions given to ExecTx against mockAuth, standing in
+// for the transaction
+func expectTx(mockAuth *mock.MockAuth) *gomock.Call {
+	return mockAuth.EXPECT().
+		ExecTx(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, fn func(db.Auth) error) error {
+			return fn(mockAuth)
+		})
+}
+
+func TestCreateOrganization(t *testing.T) {
+	ownerID := pgtype.UUID{Bytes: uuid.New(), Valid: true}
+	orgID := pgtype.UUID{Bytes: uuid.New(), Valid: true}
+	slug := "acme"
+
+	testCases := []struct {
+		name       string
+		buildStubs func(mockAuth *mock.MockAuth)
+		checkError func(t *testing.T, org *sqlc.Organization, err error)
+	}{
+		{
+			name: "OK",
+			buildStubs: func(mockAuth *mock.MockAuth) {
+				mockAuth.EXPECT().
+					GetOrganizationBySlug(gomock.Any(), gomock.Eq(slug)).
+					Times(1).
+					Return(sqlc.Organization{}, sql.ErrNoRows)
+				expectTx(mockAuth).Times(1)
+				mockAuth.EXPECT().
+					CreateOrganization(gomock.Any(), gomock.Eq(sqlc.CreateOrganizationParams{Name: "Acme", Slug: slug})).
+					Times(1).
+					Return(sqlc.Organization{ID: orgID, Name: "Acme", Slug: slug}, nil)
+				mockAuth.EXPECT().
+					AddOrganizationMember(gomock.Any(), gomock.Eq(sqlc.AddOrganizationMemberParams{
+						OrganizationID: orgID,
+						UserID:         ownerID,
+						Role:           services.OrganizationRoleOwner,
+					})).
+					Times(1).
+					Return(sqlc.OrganizationMember{OrganizationID: orgID, UserID: ownerID, Role: services.OrganizationRoleOwner}, nil)
+			},
+			checkError: func(t *testing.T, org *sqlc.Organization, err error) {
+				require.NoError(t, err)
+				require.Equal(t, orgID, org.ID)
+			},
+		},
+		{
+			name: "SlugTakenConcurrently",
+			buildStubs: func(mockAuth *mock.MockAuth) {
+				mockAuth.EXPECT().
+					GetOrganizationBySlug(gomock.Any(), gomock.Eq(slug)).
+					Times(1).
+					Return(sqlc.Organization{}, sql.ErrNoRows)
+				expectTx(mockAuth).Times(1)
+				mockAuth.EXPECT().
+					CreateOrganization(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(sqlc.Organization{}, &pgconn.PgError{Code: "23505"})
+				mockAuth.EXPECT().
+					AddOrganizationMember(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkError: func(t *testing.T, org *sqlc.Organization, err error) {
+				require.Equal(t, customError.ErrOrganizationAlreadyExist, err)
+				require.Nil(t, org)
+			},
+		},
+		{
+			name: "OwnerNotAdded",
+			buildStubs: func(mockAuth *mock.MockAuth) {
+				mockAuth.EXPECT().
+					GetOrganizationBySlug(gomock.Any(), gomock.Eq(slug)).
+					Times(1).
+					Return(sqlc.Organization{}, sql.ErrNoRows)
+				expectTx(mockAuth).Times(1)
+				mockAuth.EXPECT().
+					CreateOrganization(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(sqlc.Organization{ID: orgID, Name: "Acme", Slug: slug}, nil)
+				mockAuth.EXPECT().
+					AddOrganizationMember(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(sqlc.OrganizationMember{}, sql.ErrConnDone)
+			},
+			checkError: func(t *testing.T, org *sqlc.Organization, err error) {
+				// the transaction rolls the organization back
+				require.Equal(t, customError.UnExpectedError, err)
+				require.Nil(t, org)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockAuth := mock.NewMockAuth(ctrl)
+			tc.buildStubs(mockAuth)
+
+			orgService := services.NewOrganizationManager(mockAuth)
+			org, err := orgService.CreateOrganization(context.Background(), ownerID, " Acme ", slug)
+
+			tc.checkError(t, org, err)
+		})
+	}
+}
+
+func TestAcceptInvitation(t *testing.T) {
+	invitationToken := "invitation-token"
+	email := "invited@example.com"
+	userID := pgtype.UUID{Bytes: uuid.New(), Valid: true}
+	orgID := pgtype.UUID{Bytes: uuid.New(), Valid: true}
+
+	pendingInvitation := func() sqlc.OrganizationInvitation {
+		return sqlc.OrganizationInvitation{
+			ID:             pgtype.UUID{Bytes: uuid.New(), Valid: true},
+			OrganizationID: orgID,
+			Email:          email,
+			Role:           services.OrganizationRoleAdmin,
+			TokenHash:      utils.HashToken(invitationToken),
+			ExpiresAt:      pgtype.Timestamp{Time: time.Now().Add(time.Hour), Valid: true},
+		}
+	}
+
+	testCases := []struct {
+		name          string
+		email         string
+		buildStubs    func(mockAuth *mock.MockAuth)
+		checkResponse func(t *testing.T, member *sqlc.OrganizationMember, err error)
+	}{
+		{
+			name:  "OK",
+			email: "Invited@Example.com",
+			buildStubs: func(mockAuth *mock.MockAuth) {
+				invitation := pendingInvitation()
+				mockAuth.EXPECT().
+					GetOrganizationInvitationByTokenHash(gomock.Any(), gomock.Eq(utils.HashToken(invitationToken))).
+					Times(1).
+					Return(invitation, nil)
+
+				mockAuth.EXPECT().
+					GetOrganizationMember(gomock.Any(), gomock.Eq(sqlc.GetOrganizationMemberParams{OrganizationID: orgID, UserID: userID})).
+					Times(1).
+					Return(sqlc.OrganizationMember{}, sql.ErrNoRows)
+
+				expectTx(mockAuth).Times(1)
+				mockAuth.EXPECT().
+					AcceptOrganizationInvitation(gomock.Any(), gomock.Eq(invitation.ID)).
+					Times(1).
+					Return(invitation, nil)
+
+				mockAuth.EXPECT().
+					AddOrganizationMember(gomock.Any(), gomock.Eq(sqlc.AddOrganizationMemberParams{
+						OrganizationID: orgID,
+						UserID:         userID,
+						Role:           services.OrganizationRoleAdmin,
+					})).
+					Times(1).
+					Return(sqlc.OrganizationMember{OrganizationID: orgID, UserID: userID, Role: services.OrganizationRoleAdmin}, nil)
+			},
+			checkResponse: func(t *testing.T, member *sqlc.OrganizationMember, err error) {
+				require.NoError(t, err)
+				require.Equal(t, orgID, member.OrganizationID)
+				require.Equal(t, services.OrganizationRoleAdmin, member.Role)
+			},
+		},
+		{
+			name:  "JoinedConcurrently",
+			email: email,
+			buildStubs: func(mockAuth *mock.MockAuth) {
+				invitation := pendingInvitation()
+				mockAuth.EXPECT().
+					GetOrganizationInvitationByTokenHash(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(invitation, nil)
+				mockAuth.EXPECT().
+					GetOrganizationMember(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(sqlc.OrganizationMember{}, sql.ErrNoRows)
+
+				// accepting is rolled back along with the membership
+				expectTx(mockAuth).Times(1)
+				mockAuth.EXPECT().
+					AcceptOrganizationInvitation(gomock.Any(), gomock.Eq(invitation.ID)).
+					Times(1).
+					Return(invitation, nil)
+				mockAuth.EXPECT().
+					AddOrganizationMember(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(sqlc.OrganizationMember{}, &pgconn.PgError{Code: "23505"})
+			},
+			checkResponse: func(t *testing.T, member *sqlc.OrganizationMember, err error) {
+				require.Equal(t, customError.ErrAlreadyOrganizationMember, err)
+				require.Nil(t, member)
+			},
+		},
+		{
+			name:  "Expired",
+			email: email,
+			buildStubs: func(mockAuth *mock.MockAuth) {
+				invitation := pendingInvitation()
+				invitation.ExpiresAt = pgtype.Timestamp{Time: time.Now().Add(-time.Minute), Valid: true}
+				mockAuth.EXPECT().
+					GetOrganizationInvitationByTokenHash(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(invitation, nil)
+
+				mockAuth.EXPECT().
+					AddOrganizationMember(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, member *sqlc.OrganizationMember, err error) {
+				require.Equal(t, customError.ErrInvalidInvitation, err)
+				require.Nil(t, member)
+			},
+		},
+		{
+			name:  "AlreadyAccepted",
+			email: email,
+			buildStubs: func(mockAuth *mock.MockAuth) {
+				invitation := pendingInvitation()
+				invitation.AcceptedAt = pgtype.Timestamp{Time: time.Now(), Valid: true}
+				mockAuth.EXPECT().
+					GetOrganizationInvitationByTokenHash(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(invitation, nil)
+
+				mockAuth.EXPECT().
+					AddOrganizationMember(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, member *sqlc.OrganizationMember, err error) {
+				require.Equal(t, customError.ErrInvalidInvitation, err)
+			},
+		},
+		{
+			name:  "OtherEmail",
+			email: "someone-else@example.com",
+			buildStubs: func(mockAuth *mock.MockAuth) {
+				mockAuth.EXPECT().
+					GetOrganizationInvitationByTokenHash(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(pendingInvitation(), nil)
+
+				mockAuth.EXPECT().
+					AcceptOrganizationInvitation(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, member *sqlc.OrganizationMember, err error) {
+				require.Equal(t, customError.ErrInvitationEmailMismatch, err)
+			},
+		},
+		{
+			name:  "UnknownToken",
+			email: email,
+			buildStubs: func(mockAuth *mock.MockAuth) {
+				mockAuth.EXPECT().
+					GetOrganizationInvitationByTokenHash(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(sqlc.OrganizationInvitation{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, member *sqlc.OrganizationMember, err error) {
+				require.Equal(t, customError.ErrInvalidInvitation, err)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockAuth := mock.NewMockAuth(ctrl)
+			tc.buildStubs(mockAuth)
+
+			orgService := services.NewOrganizationManager(mockAuth)
+			member, err := orgService.AcceptInvitation(context.Background(), userID, tc.email, invitationToken)
+
+			tc.checkResponse(t, member, err)
+		})
+	}
+}
+
+func TestRemoveOrganizationMember(t *testing.T) {
+	orgID := pgtype.UUID{Bytes: uuid.New(), Valid: true}
+	actorID := pgtype.UUID{Bytes: uuid.New(), Valid: true}
+	userID := pgtype.UUID{Bytes: uuid.New(), Valid: true}
+
+	expectLocked := func(mockAuth *mock.MockAuth) {
+		expectTx(mockAuth).Times(1)
+		mockAuth.EXPECT().
+			LockOrganization(gomock.Any(), gomock.Eq(orgID)).
+			Times(1).
+			Return(orgID, nil)
+	}
+
+	expectMembers := func(mockAuth *mock.MockAuth, actorRole, userRole string) {
+		expectLocked(mockAuth)
+		mockAuth.EXPECT().
+			GetOrganizationMember(gomock.Any(), gomock.Eq(sqlc.GetOrganizationMemberParams{OrganizationID: orgID, UserID: actorID})).
+			Times(1).
+			Return(sqlc.OrganizationMember{OrganizationID: orgID, UserID: actorID, Role: actorRole}, nil)
+		mockAuth.EXPECT().
+			GetOrganizationMember(gomock.Any(), gomock.Eq(sqlc.GetOrganizationMemberParams{OrganizationID: orgID, UserID: userID})).
+			Times(1).
+			Return(sqlc.OrganizationMember{OrganizationID: orgID, UserID: userID, Role: userRole}, nil)
+	}
+
+	testCases := []struct {
+		name       string
+		buildStubs func(mockAuth *mock.MockAuth)
+		checkError func(t *testing.T, err error)
+	}{
+		{
+			name: "AdminRemovesMember",
+			buildStubs: func(mockAuth *mock.MockAuth) {
+				expectMembers(mockAuth, services.OrganizationRoleAdmin, services.OrganizationRoleMember)
+				mockAuth.EXPECT().
+					RemoveOrganizationMember(gomock.Any(), gomock.Eq(sqlc.RemoveOrganizationMemberParams{OrganizationID: orgID, UserID: userID})).
+					Times(1).
+					Return(nil)
+			},
+			checkError: func(t *testing.T, err error) {
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "AdminCannotRemoveOwner",
+			buildStubs: func(mockAuth *mock.MockAuth) {
+				expectMembers(mockAuth, services.OrganizationRoleAdmin, services.OrganizationRoleOwner)
+				mockAuth.EXPECT().
+					RemoveOrganizationMember(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkError: func(t *testing.T, err error) {
+				require.Equal(t, customError.ErrInsufficientOrganizationRole, err)
+			},
+		},
+		{
+			name: "MemberCannotRemoveOthers",
+			buildStubs: func(mockAuth *mock.MockAuth) {
+				expectMembers(mockAuth, services.OrganizationRoleMember, services.OrganizationRoleMember)
+				mockAuth.EXPECT().
+					RemoveOrganizationMember(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkError: func(t *testing.T, err error) {
+				require.Equal(t, customError.ErrInsufficientOrganizationRole, err)
+			},
+		},
+		{
+			name: "LastOwnerIsKept",
+			buildStubs: func(mockAuth *mock.MockAuth) {
+				expectMembers(mockAuth, services.OrganizationRoleOwner, services.OrganizationRoleOwner)
+				mockAuth.EXPECT().
+					CountOrganizationOwners(gomock.Any(), gomock.Eq(orgID)).
+					Times(1).
+					Return(int64(1), nil)
+				mockAuth.EXPECT().
+					RemoveOrganizationMember(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkError: func(t *testing.T, err error) {
+				require.Equal(t, customError.ErrLastOrganizationOwner, err)
+			},
+		},
+		{
+			name: "NotAMember",
+			buildStubs: func(mockAuth *mock.MockAuth) {
+				expectLocked(mockAuth)
+				mockAuth.EXPECT().
+					GetOrganizationMember(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(sqlc.OrganizationMember{}, sql.ErrNoRows)
+			},
+			checkError: func(t *testing.T, err error) {
+				require.Equal(t, customError.ErrNotOrganizationMember, err)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockAuth := mock.NewMockAuth(ctrl)
+			tc.buildStubs(mockAuth)
+
+			orgService := services.NewOrganizationManager(mockAuth)
+			err := orgService.RemoveMember(context.Background(), actorID, orgID, userID)
+
+			tc.checkError(t, err)
+		})
+	}
+}
+
+func TestSwitchOrganization(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	orgID := pgtype.UUID{Bytes: uuid.New(), Valid: true}
+	userID := pgtype.UUID{Bytes: uuid.New(), Valid: true}
+	familyID := pgtype.UUID{Bytes: uuid.New(), Valid: true}
+
+	mockAuth := mock.NewMockAuth(ctrl)
+	mockAuth.EXPECT().
+		GetOrganizationMember(gomock.Any(), gomock.Eq(sqlc.GetOrganizationMemberParams{OrganizationID: orgID, UserID: userID})).
+		Times(1).
+		Return(sqlc.OrganizationMember{OrganizationID: orgID, UserID: userID, Role: services.OrganizationRoleMember}, nil)
+	mockAuth.EXPECT().
+		SetSessionOrganization(gomock.Any(), gomock.Eq(sqlc.SetSessionOrganizationParams{FamilyID: familyID, OrganizationID: orgID})).
+		Times(1).
+		Return(nil)
+
+	orgService := services.NewOrganizationManager(mockAuth)
+	member, err := orgService.SwitchOrganization(context.Background(), userID, familyID, orgID)
+	require.NoError(t, err)
+	require.Equal(t, services.OrganizationRoleMember, member.Role)
+}
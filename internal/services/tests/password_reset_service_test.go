@@ -0,0 +1,341 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+
+	customError "github.com/suryansh74/auth-package/internal/apperrors"
+	"github.com/suryansh74/auth-package/internal/db/mock"
+	"github.com/suryansh74/auth-package/internal/db/sqlc"
+	"github.com/suryansh74/auth-package/internal/services"
+	"github.com/suryansh74/auth-package/internal/utils"
+)
+
+func TestRequestReset(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockAuth := mock.NewMockAuth(ctrl)
+	user := sqlc.User{ID: pgtype.UUID{Bytes: uuid.New(), Valid: true}, Email: "john@example.com"}
+
+	mockAuth.EXPECT().
+		GetUserByEmail(gomock.Any(), gomock.Eq(user.Email)).
+		Times(1).
+		Return(user, nil)
+	// links requested before stop working
+	mockAuth.EXPECT().
+		InvalidateUserPasswordResetTokens(gomock.Any(), gomock.Eq(user.ID)).
+		Times(1).
+		Return(nil)
+
+	var stored sqlc.CreatePasswordResetTokenParams
+	mockAuth.EXPECT().
+		CreatePasswordResetToken(gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(ctx interface{}, params sqlc.CreatePasswordResetTokenParams) (sqlc.PasswordResetToken, error) {
+			stored = params
+			return sqlc.PasswordResetToken{UserID: params.UserID, TokenHash: params.TokenHash, ExpiresAt: params.ExpiresAt}, nil
+		})
+
+	resetService := services.NewPasswordResetManager(mockAuth)
+	resetToken, resetUser, err := resetService.RequestReset(context.Background(), user.Email, 30*time.Minute)
+	require.NoError(t, err)
+	require.NotEmpty(t, resetToken)
+	require.Equal(t, user.ID, resetUser.ID)
+
+	// only the hash of the token is stored
+	require.Equal(t, utils.HashToken(resetToken), stored.TokenHash)
+	require.WithinDuration(t, time.Now().Add(30*time.Minute), stored.ExpiresAt.Time, time.Second)
+
+	mockAuth.EXPECT().
+		GetUserByEmail(gomock.Any(), gomock.Any()).
+		Times(1).
+		Return(sqlc.User{}, sql.ErrNoRows)
+
+	_, _, err = resetService.RequestReset(context.Background(), "unknown@example.com", 30*time.Minute)
+	require.ErrorIs(t, err, customError.ErrUserNotFound)
+}
+
+func TestResetPassword(t *testing.T) {
+	resetToken := "reset-token"
+	userID := pgtype.UUID{Bytes: uuid.New(), Valid: true}
+
+	validReset := func() sqlc.PasswordResetToken {
+		return sqlc.PasswordResetToken{
+			ID:        pgtype.UUID{Bytes: uuid.New(), Valid: true},
+			UserID:    userID,
+			TokenHash: utils.HashToken(resetToken),
+			ExpiresAt: pgtype.Timestamp{Time: time.Now().Add(time.Minute), Valid: true},
+		}
+	}
+
+	testCases := []struct {
+		name          string
+		newPassword   string
+		buildStubs    func(mockAuth *mock.MockAuth)
+		checkResponse func(t *testing.T, user *sqlc.User, err error)
+	}{
+		{
+			name:        "OK",
+			newPassword: "newpassword123",
+			buildStubs: func(mockAuth *mock.MockAuth) {
+				reset := validReset()
+				mockAuth.EXPECT().
+					GetPasswordResetTokenByHash(gomock.Any(), gomock.Eq(utils.HashToken(resetToken))).
+					Times(1).
+					Return(reset, nil)
+
+				expectTx(mockAuth).Times(1)
+				mockAuth.EXPECT().
+					UsePasswordResetToken(gomock.Any(), gomock.Eq(reset.ID)).
+					Times(1).
+					Return(reset, nil)
+
+				mockAuth.EXPECT().
+					UpdateUserPassword(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(ctx interface{}, params sqlc.UpdateUserPasswordParams) (sqlc.User, error) {
+						require.NoError(t, utils.CheckPassword("newpassword123", params.Password))
+						return sqlc.User{ID: params.ID, Password: params.Password, PasswordChangedAt: params.PasswordChangedAt}, nil
+					})
+
+				mockAuth.EXPECT().
+					InvalidateUserPasswordResetTokens(gomock.Any(), gomock.Eq(userID)).
+					Times(1).
+					Return(nil)
+			},
+			checkResponse: func(t *testing.T, user *sqlc.User, err error) {
+				require.NoError(t, err)
+				require.Equal(t, userID, user.ID)
+				require.True(t, user.PasswordChangedAt.Valid)
+			},
+		},
+		{
+			name:        "UnlocksAccount",
+			newPassword: "newpassword123",
+			buildStubs: func(mockAuth *mock.MockAuth) {
+				reset := validReset()
+				mockAuth.EXPECT().
+					GetPasswordResetTokenByHash(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(reset, nil)
+
+				expectTx(mockAuth).Times(1)
+				mockAuth.EXPECT().
+					UsePasswordResetToken(gomock.Any(), gomock.Eq(reset.ID)).
+					Times(1).
+					Return(reset, nil)
+
+				mockAuth.EXPECT().
+					UpdateUserPassword(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(ctx interface{}, params sqlc.UpdateUserPasswordParams) (sqlc.User, error) {
+						return sqlc.User{
+							ID:                params.ID,
+							Password:          params.Password,
+							PasswordChangedAt: params.PasswordChangedAt,
+							EmailVerifiedAt:   pgtype.Timestamp{Time: time.Now(), Valid: true},
+							Status:            services.UserStatusLocked,
+						}, nil
+					})
+
+				mockAuth.EXPECT().
+					InvalidateUserPasswordResetTokens(gomock.Any(), gomock.Eq(userID)).
+					Times(1).
+					Return(nil)
+
+				mockAuth.EXPECT().
+					UpdateUserStatus(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(ctx interface{}, params sqlc.UpdateUserStatusParams) (sqlc.User, error) {
+						require.Equal(t, userID, params.ID)
+						require.Equal(t, services.UserStatusActive, params.Status)
+						require.Equal(t, services.UserStatusLocked, params.CurrentStatus)
+						return sqlc.User{ID: params.ID, Status: params.Status}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, user *sqlc.User, err error) {
+				require.NoError(t, err)
+				require.Equal(t, services.UserStatusActive, user.Status)
+			},
+		},
+		{
+			name:        "UnknownToken",
+			newPassword: "newpassword123",
+			buildStubs: func(mockAuth *mock.MockAuth) {
+				mockAuth.EXPECT().
+					GetPasswordResetTokenByHash(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(sqlc.PasswordResetToken{}, sql.ErrNoRows)
+
+				mockAuth.EXPECT().
+					UpdateUserPassword(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, user *sqlc.User, err error) {
+				require.Equal(t, customError.ErrInvalidResetToken, err)
+				require.Nil(t, user)
+			},
+		},
+		{
+			name:        "Expired",
+			newPassword: "newpassword123",
+			buildStubs: func(mockAuth *mock.MockAuth) {
+				reset := validReset()
+				reset.ExpiresAt = pgtype.Timestamp{Time: time.Now().Add(-time.Minute), Valid: true}
+				mockAuth.EXPECT().
+					GetPasswordResetTokenByHash(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(reset, nil)
+
+				mockAuth.EXPECT().
+					UsePasswordResetToken(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, user *sqlc.User, err error) {
+				require.Equal(t, customError.ErrInvalidResetToken, err)
+				require.Nil(t, user)
+			},
+		},
+		{
+			name:        "AlreadyUsed",
+			newPassword: "newpassword123",
+			buildStubs: func(mockAuth *mock.MockAuth) {
+				reset := validReset()
+				reset.UsedAt = pgtype.Timestamp{Time: time.Now(), Valid: true}
+				mockAuth.EXPECT().
+					GetPasswordResetTokenByHash(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(reset, nil)
+
+				mockAuth.EXPECT().
+					UsePasswordResetToken(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, user *sqlc.User, err error) {
+				require.Equal(t, customError.ErrInvalidResetToken, err)
+				require.Nil(t, user)
+			},
+		},
+		{
+			name:        "ConcurrentUse",
+			newPassword: "newpassword123",
+			buildStubs: func(mockAuth *mock.MockAuth) {
+				mockAuth.EXPECT().
+					GetPasswordResetTokenByHash(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(validReset(), nil)
+
+				expectTx(mockAuth).Times(1)
+				mockAuth.EXPECT().
+					UsePasswordResetToken(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(sqlc.PasswordResetToken{}, sql.ErrNoRows)
+
+				mockAuth.EXPECT().
+					UpdateUserPassword(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, user *sqlc.User, err error) {
+				require.Equal(t, customError.ErrInvalidResetToken, err)
+				require.Nil(t, user)
+			},
+		},
+		{
+			name:        "AccountDeleted",
+			newPassword: "newpassword123",
+			buildStubs: func(mockAuth *mock.MockAuth) {
+				reset := validReset()
+				mockAuth.EXPECT().
+					GetPasswordResetTokenByHash(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(reset, nil)
+
+				expectTx(mockAuth).Times(1)
+				mockAuth.EXPECT().
+					UsePasswordResetToken(gomock.Any(), gomock.Eq(reset.ID)).
+					Times(1).
+					Return(reset, nil)
+				mockAuth.EXPECT().
+					UpdateUserPassword(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(sqlc.User{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, user *sqlc.User, err error) {
+				require.Equal(t, customError.ErrInvalidResetToken, err)
+				require.Nil(t, user)
+			},
+		},
+		{
+			name:        "FailedUpdateKeepsToken",
+			newPassword: "newpassword123",
+			buildStubs: func(mockAuth *mock.MockAuth) {
+				reset := validReset()
+				mockAuth.EXPECT().
+					GetPasswordResetTokenByHash(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(reset, nil)
+
+				// using the token is rolled back along with the update
+				expectTx(mockAuth).Times(1)
+				mockAuth.EXPECT().
+					UsePasswordResetToken(gomock.Any(), gomock.Eq(reset.ID)).
+					Times(1).
+					Return(reset, nil)
+				mockAuth.EXPECT().
+					UpdateUserPassword(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(sqlc.User{}, sql.ErrConnDone)
+				mockAuth.EXPECT().
+					InvalidateUserPasswordResetTokens(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, user *sqlc.User, err error) {
+				require.Equal(t, customError.UnExpectedError, err)
+				require.Nil(t, user)
+			},
+		},
+		{
+			name:        "WeakPasswordKeepsToken",
+			newPassword: "short",
+			buildStubs: func(mockAuth *mock.MockAuth) {
+				mockAuth.EXPECT().
+					GetPasswordResetTokenByHash(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(validReset(), nil)
+
+				mockAuth.EXPECT().
+					UsePasswordResetToken(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, user *sqlc.User, err error) {
+				require.Equal(t, customError.ErrWeakPassword, err)
+				require.Nil(t, user)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockAuth := mock.NewMockAuth(ctrl)
+			tc.buildStubs(mockAuth)
+
+			resetService := services.NewPasswordResetManager(mockAuth)
+			user, err := resetService.ResetPassword(context.Background(), resetToken, tc.newPassword)
+
+			tc.checkResponse(t, user, err)
+		})
+	}
+}
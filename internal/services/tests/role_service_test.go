@@ -0,0 +1,359 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+
+	customError "github.com/suryansh74/auth-package/internal/apperrors"
+	"github.com/suryansh74/auth-package/internal/db/mock"
+	"github.com/suryansh74/auth-package/internal/db/sqlc"
+	"github.com/suryansh74/auth-package/internal/services"
+)
+
+func TestAssignRole(t *testing.T) {
+	userID := pgtype.UUID{Bytes: uuid.New(), Valid: true}
+	role := sqlc.Role{ID: pgtype.UUID{Bytes: uuid.New(), Valid: true}, Name: "editor"}
+
+	testCases := []struct {
+		name       string
+		role       string
+		buildStubs func(mockAuth *mock.MockAuth)
+		checkError func(t *testing.T, err error)
+	}{
+		{
+			name: "OK",
+			role: role.Name,
+			buildStubs: func(mockAuth *mock.MockAuth) {
+				mockAuth.EXPECT().
+					GetRoleByName(gomock.Any(), gomock.Eq(role.Name)).
+					Times(1).
+					Return(role, nil)
+
+				mockAuth.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(userID)).
+					Times(1).
+					Return(sqlc.User{ID: userID}, nil)
+
+				mockAuth.EXPECT().
+					AssignUserRole(gomock.Any(), gomock.Eq(sqlc.AssignUserRoleParams{UserID: userID, RoleID: role.ID})).
+					Times(1).
+					Return(nil)
+			},
+			checkError: func(t *testing.T, err error) {
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "RoleNotFound",
+			role: "missing",
+			buildStubs: func(mockAuth *mock.MockAuth) {
+				mockAuth.EXPECT().
+					GetRoleByName(gomock.Any(), gomock.Eq("missing")).
+					Times(1).
+					Return(sqlc.Role{}, sql.ErrNoRows)
+
+				mockAuth.EXPECT().
+					AssignUserRole(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkError: func(t *testing.T, err error) {
+				require.Equal(t, customError.ErrRoleNotFound, err)
+			},
+		},
+		{
+			name: "UserNotFound",
+			role: role.Name,
+			buildStubs: func(mockAuth *mock.MockAuth) {
+				mockAuth.EXPECT().
+					GetRoleByName(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(role, nil)
+
+				mockAuth.EXPECT().
+					GetUser(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(sqlc.User{}, sql.ErrNoRows)
+
+				mockAuth.EXPECT().
+					AssignUserRole(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkError: func(t *testing.T, err error) {
+				require.Equal(t, customError.ErrUserNotFound, err)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockAuth := mock.NewMockAuth(ctrl)
+			tc.buildStubs(mockAuth)
+
+			roleService := services.NewRoleManager(mockAuth)
+			err := roleService.AssignRole(context.Background(), userID, tc.role)
+
+			tc.checkError(t, err)
+		})
+	}
+}
+
+func TestCreateRole(t *testing.T) {
+	testCases := []struct {
+		name       string
+		role       string
+		buildStubs func(mockAuth *mock.MockAuth)
+		checkError func(t *testing.T, err error)
+	}{
+		{
+			name: "OK",
+			role: "editor",
+			buildStubs: func(mockAuth *mock.MockAuth) {
+				mockAuth.EXPECT().
+					GetRoleByName(gomock.Any(), gomock.Eq("editor")).
+					Times(1).
+					Return(sqlc.Role{}, sql.ErrNoRows)
+
+				mockAuth.EXPECT().
+					CreateRole(gomock.Any(), gomock.Eq(sqlc.CreateRoleParams{Name: "editor"})).
+					Times(1).
+					Return(sqlc.Role{Name: "editor"}, nil)
+			},
+			checkError: func(t *testing.T, err error) {
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "AlreadyExists",
+			role: "editor",
+			buildStubs: func(mockAuth *mock.MockAuth) {
+				mockAuth.EXPECT().
+					GetRoleByName(gomock.Any(), gomock.Eq("editor")).
+					Times(1).
+					Return(sqlc.Role{Name: "editor"}, nil)
+
+				mockAuth.EXPECT().
+					CreateRole(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkError: func(t *testing.T, err error) {
+				require.Equal(t, customError.ErrRoleAlreadyExist, err)
+			},
+		},
+		{
+			name: "InvalidName",
+			role: "Not A Role",
+			buildStubs: func(mockAuth *mock.MockAuth) {
+				mockAuth.EXPECT().
+					GetRoleByName(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkError: func(t *testing.T, err error) {
+				require.Equal(t, customError.ErrInvalidRBACName, err)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockAuth := mock.NewMockAuth(ctrl)
+			tc.buildStubs(mockAuth)
+
+			roleService := services.NewRoleManager(mockAuth)
+			_, err := roleService.CreateRole(context.Background(), tc.role, "")
+
+			tc.checkError(t, err)
+		})
+	}
+}
+
+func TestSuperAdminHasEveryPermission(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	userID := pgtype.UUID{Bytes: uuid.New(), Valid: true}
+	mockAuth := mock.NewMockAuth(ctrl)
+	mockAuth.EXPECT().
+		ListUserRoles(gomock.Any(), gomock.Eq(userID)).
+		Times(1).
+		Return([]string{services.SuperAdminRole}, nil)
+	mockAuth.EXPECT().
+		ListPermissions(gomock.Any()).
+		Times(1).
+		Return([]sqlc.Permission{{Name: "posts:delete"}, {Name: services.ManageRBACPermission}}, nil)
+	mockAuth.EXPECT().
+		ListUserPermissions(gomock.Any(), gomock.Any()).
+		Times(0)
+
+	roleService := services.NewRoleManager(mockAuth)
+	permissions, err := roleService.UserPermissions(context.Background(), userID)
+	require.NoError(t, err)
+	require.Equal(t, []string{"posts:delete", services.ManageRBACPermission}, permissions)
+}
+
+func TestBootstrapSuperAdmin(t *testing.T) {
+	email := "admin@example.com"
+	role := sqlc.Role{ID: pgtype.UUID{Bytes: uuid.New(), Valid: true}, Name: services.SuperAdminRole}
+	permission := sqlc.Permission{ID: pgtype.UUID{Bytes: uuid.New(), Valid: true}, Name: services.ManageRBACPermission}
+	user := sqlc.User{
+		ID:              pgtype.UUID{Bytes: uuid.New(), Valid: true},
+		Email:           email,
+		EmailVerifiedAt: pgtype.Timestamp{Time: time.Now(), Valid: true},
+		Status:          services.UserStatusActive,
+	}
+	unverified := user
+	unverified.EmailVerifiedAt = pgtype.Timestamp{}
+	unverified.Status = services.UserStatusPending
+	suspended := user
+	suspended.Status = services.UserStatusSuspended
+
+	existingRoleAndPermission := func(mockAuth *mock.MockAuth) {
+		mockAuth.EXPECT().
+			GetRoleByName(gomock.Any(), gomock.Eq(services.SuperAdminRole)).
+			Times(2).
+			Return(role, nil)
+		mockAuth.EXPECT().
+			GetPermissionByName(gomock.Any(), gomock.Eq(services.ManageRBACPermission)).
+			Times(2).
+			Return(permission, nil)
+		mockAuth.EXPECT().
+			GrantRolePermission(gomock.Any(), gomock.Eq(sqlc.GrantRolePermissionParams{RoleID: role.ID, PermissionID: permission.ID})).
+			Times(1).
+			Return(nil)
+	}
+
+	testCases := []struct {
+		name       string
+		buildStubs func(mockAuth *mock.MockAuth)
+		checkError func(t *testing.T, err error)
+	}{
+		{
+			name: "AssignsFirstSuperAdmin",
+			buildStubs: func(mockAuth *mock.MockAuth) {
+				existingRoleAndPermission(mockAuth)
+				mockAuth.EXPECT().
+					CountRoleUsers(gomock.Any(), gomock.Eq(role.ID)).
+					Times(1).
+					Return(int64(0), nil)
+				mockAuth.EXPECT().
+					GetUserByEmail(gomock.Any(), gomock.Eq(email)).
+					Times(1).
+					Return(user, nil)
+				mockAuth.EXPECT().
+					AssignUserRole(gomock.Any(), gomock.Eq(sqlc.AssignUserRoleParams{UserID: user.ID, RoleID: role.ID})).
+					Times(1).
+					Return(nil)
+			},
+			checkError: func(t *testing.T, err error) {
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "SuperAdminAlreadyAssigned",
+			buildStubs: func(mockAuth *mock.MockAuth) {
+				existingRoleAndPermission(mockAuth)
+				mockAuth.EXPECT().
+					CountRoleUsers(gomock.Any(), gomock.Eq(role.ID)).
+					Times(1).
+					Return(int64(1), nil)
+				mockAuth.EXPECT().
+					AssignUserRole(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkError: func(t *testing.T, err error) {
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "UserNotRegistered",
+			buildStubs: func(mockAuth *mock.MockAuth) {
+				existingRoleAndPermission(mockAuth)
+				mockAuth.EXPECT().
+					CountRoleUsers(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(int64(0), nil)
+				mockAuth.EXPECT().
+					GetUserByEmail(gomock.Any(), gomock.Eq(email)).
+					Times(1).
+					Return(sqlc.User{}, sql.ErrNoRows)
+			},
+			checkError: func(t *testing.T, err error) {
+				require.Equal(t, customError.ErrUserNotFound, err)
+			},
+		},
+		{
+			// whoever registered the address first has not proven owning it
+			name: "EmailNotVerified",
+			buildStubs: func(mockAuth *mock.MockAuth) {
+				existingRoleAndPermission(mockAuth)
+				mockAuth.EXPECT().
+					CountRoleUsers(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(int64(0), nil)
+				mockAuth.EXPECT().
+					GetUserByEmail(gomock.Any(), gomock.Eq(email)).
+					Times(1).
+					Return(unverified, nil)
+				mockAuth.EXPECT().
+					AssignUserRole(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkError: func(t *testing.T, err error) {
+				require.Equal(t, customError.ErrSuperAdminIneligible, err)
+			},
+		},
+		{
+			name: "AccountSuspended",
+			buildStubs: func(mockAuth *mock.MockAuth) {
+				existingRoleAndPermission(mockAuth)
+				mockAuth.EXPECT().
+					CountRoleUsers(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(int64(0), nil)
+				mockAuth.EXPECT().
+					GetUserByEmail(gomock.Any(), gomock.Eq(email)).
+					Times(1).
+					Return(suspended, nil)
+				mockAuth.EXPECT().
+					AssignUserRole(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkError: func(t *testing.T, err error) {
+				require.Equal(t, customError.ErrSuperAdminIneligible, err)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockAuth := mock.NewMockAuth(ctrl)
+			tc.buildStubs(mockAuth)
+
+			roleService := services.NewRoleManager(mockAuth)
+			err := roleService.BootstrapSuperAdmin(context.Background(), email)
+
+			tc.checkError(t, err)
+		})
+	}
+}
@@ -17,6 +17,7 @@ import (
 	"github.com/suryansh74/auth-package/internal/dto"
 	"github.com/suryansh74/auth-package/internal/services"
 	"github.com/suryansh74/auth-package/internal/utils"
+	"github.com/suryansh74/auth-package/token"
 )
 
 func TestRegister(t *testing.T) {
@@ -39,6 +40,10 @@ func TestRegister(t *testing.T) {
 					GetUserByEmail(gomock.Any(), gomock.Eq("john@example.com")).
 					Times(1).
 					Return(sqlc.User{}, sql.ErrNoRows)
+				mockAuth.EXPECT().
+					GetDeletedUserByEmail(gomock.Any(), gomock.Eq("john@example.com")).
+					Times(1).
+					Return(sqlc.User{}, sql.ErrNoRows)
 
 				// CreateUser succeeds
 				hashedPassword, _ := utils.HashedPassword("password123")
@@ -102,6 +107,34 @@ func TestRegister(t *testing.T) {
 				require.Equal(t, customError.ErrUserAlreadyExist, err)
 			},
 		},
+		{
+			name: "DeletedUserHoldsEmail",
+			request: dto.UserRegisterRequest{
+				Name:     "Jane Doe",
+				Email:    "jane@example.com",
+				Password: "password123",
+			},
+			buildStubs: func(mockAuth *mock.MockAuth) {
+				mockAuth.EXPECT().
+					GetUserByEmail(gomock.Any(), gomock.Eq("jane@example.com")).
+					Times(1).
+					Return(sqlc.User{}, sql.ErrNoRows)
+
+				// the account can still be restored
+				mockAuth.EXPECT().
+					GetDeletedUserByEmail(gomock.Any(), gomock.Eq("jane@example.com")).
+					Times(1).
+					Return(sqlc.User{Email: "jane@example.com", DeletedAt: pgtype.Timestamp{Time: time.Now(), Valid: true}}, nil)
+
+				mockAuth.EXPECT().
+					CreateUser(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, resp *dto.UserRegisterResponse, err error) {
+				require.Nil(t, resp)
+				require.Equal(t, customError.ErrUserAlreadyExist, err)
+			},
+		},
 		{
 			name: "DatabaseErrorOnCheck",
 			request: dto.UserRegisterRequest{
@@ -140,6 +173,10 @@ func TestRegister(t *testing.T) {
 					GetUserByEmail(gomock.Any(), gomock.Eq("alice@example.com")).
 					Times(1).
 					Return(sqlc.User{}, sql.ErrNoRows)
+				mockAuth.EXPECT().
+					GetDeletedUserByEmail(gomock.Any(), gomock.Eq("alice@example.com")).
+					Times(1).
+					Return(sqlc.User{}, sql.ErrNoRows)
 
 				// CreateUser fails
 				mockAuth.EXPECT().
@@ -208,6 +245,106 @@ func TestLogin(t *testing.T) {
 				require.NoError(t, err)
 				require.NotNil(t, resp)
 				require.Equal(t, "john@example.com", resp.Email)
+				require.False(t, resp.EmailVerified)
+			},
+		},
+		{
+			name: "VerifiedEmail",
+			request: dto.UserLoginRequest{
+				Email:    "john@example.com",
+				Password: password,
+			},
+			buildStubs: func(mockAuth *mock.MockAuth) {
+				user := sqlc.User{
+					ID:              pgtype.UUID{Valid: true},
+					Email:           "john@example.com",
+					Password:        hashedPassword,
+					EmailVerifiedAt: pgtype.Timestamp{Time: time.Now(), Valid: true},
+				}
+
+				mockAuth.EXPECT().
+					GetUserByEmail(gomock.Any(), gomock.Eq("john@example.com")).
+					Times(1).
+					Return(user, nil)
+			},
+			checkResponse: func(t *testing.T, resp *dto.UserLoginResponse, err error) {
+				require.NoError(t, err)
+				require.True(t, resp.EmailVerified)
+			},
+		},
+		{
+			name: "Suspended",
+			request: dto.UserLoginRequest{
+				Email:    "john@example.com",
+				Password: password,
+			},
+			buildStubs: func(mockAuth *mock.MockAuth) {
+				user := sqlc.User{
+					ID:       pgtype.UUID{Valid: true},
+					Email:    "john@example.com",
+					Password: hashedPassword,
+					Status:   services.UserStatusSuspended,
+				}
+
+				mockAuth.EXPECT().
+					GetUserByEmail(gomock.Any(), gomock.Eq("john@example.com")).
+					Times(1).
+					Return(user, nil)
+			},
+			checkResponse: func(t *testing.T, resp *dto.UserLoginResponse, err error) {
+				require.Equal(t, customError.ErrAccountSuspended, err)
+				require.Nil(t, resp)
+			},
+		},
+		{
+			name: "SuspensionOver",
+			request: dto.UserLoginRequest{
+				Email:    "john@example.com",
+				Password: password,
+			},
+			buildStubs: func(mockAuth *mock.MockAuth) {
+				user := sqlc.User{
+					ID:             pgtype.UUID{Valid: true},
+					Email:          "john@example.com",
+					Password:       hashedPassword,
+					Status:         services.UserStatusSuspended,
+					SuspendedUntil: pgtype.Timestamp{Time: time.Now().Add(-time.Minute), Valid: true},
+				}
+
+				mockAuth.EXPECT().
+					GetUserByEmail(gomock.Any(), gomock.Eq("john@example.com")).
+					Times(1).
+					Return(user, nil)
+			},
+			checkResponse: func(t *testing.T, resp *dto.UserLoginResponse, err error) {
+				require.NoError(t, err)
+				require.NotNil(t, resp)
+			},
+		},
+		{
+			name: "LockedWrongPassword",
+			request: dto.UserLoginRequest{
+				Email:    "john@example.com",
+				Password: "wrong-password1",
+			},
+			buildStubs: func(mockAuth *mock.MockAuth) {
+				user := sqlc.User{
+					ID:       pgtype.UUID{Valid: true},
+					Email:    "john@example.com",
+					Password: hashedPassword,
+					Status:   services.UserStatusLocked,
+				}
+
+				mockAuth.EXPECT().
+					GetUserByEmail(gomock.Any(), gomock.Eq("john@example.com")).
+					Times(1).
+					Return(user, nil)
+			},
+			checkResponse: func(t *testing.T, resp *dto.UserLoginResponse, err error) {
+				// the status is not told without the password
+				require.Error(t, err)
+				require.NotEqual(t, customError.ErrAccountLocked, err)
+				require.Nil(t, resp)
 			},
 		},
 		{
@@ -292,3 +429,263 @@ func TestLogin(t *testing.T) {
 		})
 	}
 }
+
+func TestChangePassword(t *testing.T) {
+	currentPassword := "password123"
+	hashedPassword, _ := utils.HashedPassword(currentPassword)
+	userID := pgtype.UUID{Valid: true}
+	user := sqlc.User{
+		ID:       userID,
+		Email:    "john@example.com",
+		Password: hashedPassword,
+	}
+	now := time.Date(2026, time.March, 1, 12, 0, 0, 500_000_000, time.UTC)
+
+	testCases := []struct {
+		name            string
+		currentPassword string
+		newPassword     string
+		buildStubs      func(mockAuth *mock.MockAuth)
+		checkResponse   func(t *testing.T, user *sqlc.User, err error)
+	}{
+		{
+			name:            "OK",
+			currentPassword: currentPassword,
+			newPassword:     "new-password456",
+			buildStubs: func(mockAuth *mock.MockAuth) {
+				mockAuth.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(userID)).
+					Times(1).
+					Return(user, nil)
+
+				mockAuth.EXPECT().
+					UpdateUserPassword(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(ctx interface{}, params sqlc.UpdateUserPasswordParams) (sqlc.User, error) {
+						require.Equal(t, userID, params.ID)
+						require.NoError(t, utils.CheckPassword("new-password456", params.Password))
+						require.Equal(t, pgtype.Timestamp{Time: now, Valid: true}, params.PasswordChangedAt)
+						updated := user
+						updated.Password = params.Password
+						updated.PasswordChangedAt = params.PasswordChangedAt
+						return updated, nil
+					})
+			},
+			checkResponse: func(t *testing.T, user *sqlc.User, err error) {
+				require.NoError(t, err)
+				require.Equal(t, now, user.PasswordChangedAt.Time)
+			},
+		},
+		{
+			name:            "WrongCurrentPassword",
+			currentPassword: "wrongpassword",
+			newPassword:     "new-password456",
+			buildStubs: func(mockAuth *mock.MockAuth) {
+				mockAuth.EXPECT().
+					GetUser(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(user, nil)
+
+				mockAuth.EXPECT().
+					UpdateUserPassword(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, user *sqlc.User, err error) {
+				require.Equal(t, customError.ErrIncorrectPassword, err)
+				require.Nil(t, user)
+			},
+		},
+		{
+			name:            "WeakPassword",
+			currentPassword: currentPassword,
+			newPassword:     "short",
+			buildStubs: func(mockAuth *mock.MockAuth) {
+				mockAuth.EXPECT().
+					GetUser(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(user, nil)
+
+				mockAuth.EXPECT().
+					UpdateUserPassword(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, user *sqlc.User, err error) {
+				require.Equal(t, customError.ErrWeakPassword, err)
+			},
+		},
+		{
+			name:            "SamePassword",
+			currentPassword: currentPassword,
+			newPassword:     currentPassword,
+			buildStubs: func(mockAuth *mock.MockAuth) {
+				mockAuth.EXPECT().
+					GetUser(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(user, nil)
+
+				mockAuth.EXPECT().
+					UpdateUserPassword(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, user *sqlc.User, err error) {
+				require.Equal(t, customError.ErrPasswordNotChanged, err)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockAuth := mock.NewMockAuth(ctrl)
+			tc.buildStubs(mockAuth)
+
+			authService := services.NewAuthenticator(mockAuth, services.WithClock(token.NewFakeClock(now)))
+			user, err := authService.ChangePassword(context.Background(), userID, tc.currentPassword, tc.newPassword)
+
+			tc.checkResponse(t, user, err)
+		})
+	}
+}
+
+func TestVerifyEmail(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockAuth := mock.NewMockAuth(ctrl)
+	userID := pgtype.UUID{Valid: true}
+	arg := sqlc.VerifyUserEmailParams{ID: userID, Email: "john@example.com"}
+
+	mockAuth.EXPECT().
+		VerifyUserEmail(gomock.Any(), gomock.Eq(arg)).
+		Times(1).
+		Return(sqlc.User{ID: userID, Email: arg.Email, EmailVerifiedAt: pgtype.Timestamp{Time: time.Now(), Valid: true}}, nil)
+
+	authService := services.NewAuthenticator(mockAuth)
+	user, err := authService.VerifyEmail(context.Background(), userID, arg.Email)
+	require.NoError(t, err)
+	require.True(t, user.EmailVerifiedAt.Valid)
+
+	// the email of the user changed since the link was sent
+	mockAuth.EXPECT().
+		VerifyUserEmail(gomock.Any(), gomock.Any()).
+		Times(1).
+		Return(sqlc.User{}, sql.ErrNoRows)
+
+	user, err = authService.VerifyEmail(context.Background(), userID, "old@example.com")
+	require.Equal(t, customError.ErrInvalidVerificationToken, err)
+	require.Nil(t, user)
+}
+
+func TestUpdateProfile(t *testing.T) {
+	userID := pgtype.UUID{Valid: true}
+	updatedAt := time.Now().UTC()
+	name := func(name string) *string { return &name }
+
+	testCases := []struct {
+		name          string
+		request       dto.UpdateProfileRequest
+		buildStubs    func(mockAuth *mock.MockAuth)
+		checkResponse func(t *testing.T, user *sqlc.User, err error)
+	}{
+		{
+			name:    "OK",
+			request: dto.UpdateProfileRequest{Name: name("  Jane Doe "), UpdatedAt: updatedAt},
+			buildStubs: func(mockAuth *mock.MockAuth) {
+				mockAuth.EXPECT().
+					UpdateUserProfile(gomock.Any(), gomock.Eq(sqlc.UpdateUserProfileParams{
+						Name:      pgtype.Text{String: "Jane Doe", Valid: true},
+						ID:        userID,
+						UpdatedAt: pgtype.Timestamp{Time: updatedAt, Valid: true},
+					})).
+					Times(1).
+					Return(sqlc.User{ID: userID, Name: "Jane Doe"}, nil)
+			},
+			checkResponse: func(t *testing.T, user *sqlc.User, err error) {
+				require.NoError(t, err)
+				require.Equal(t, "Jane Doe", user.Name)
+			},
+		},
+		{
+			name:    "Conflict",
+			request: dto.UpdateProfileRequest{Name: name("Jane Doe"), UpdatedAt: updatedAt},
+			buildStubs: func(mockAuth *mock.MockAuth) {
+				mockAuth.EXPECT().
+					UpdateUserProfile(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(sqlc.User{}, sql.ErrNoRows)
+
+				mockAuth.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(userID)).
+					Times(1).
+					Return(sqlc.User{ID: userID}, nil)
+			},
+			checkResponse: func(t *testing.T, user *sqlc.User, err error) {
+				require.Equal(t, customError.ErrProfileConflict, err)
+				require.Nil(t, user)
+			},
+		},
+		{
+			name:    "UserNotFound",
+			request: dto.UpdateProfileRequest{Name: name("Jane Doe"), UpdatedAt: updatedAt},
+			buildStubs: func(mockAuth *mock.MockAuth) {
+				mockAuth.EXPECT().
+					UpdateUserProfile(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(sqlc.User{}, sql.ErrNoRows)
+
+				mockAuth.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(userID)).
+					Times(1).
+					Return(sqlc.User{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, user *sqlc.User, err error) {
+				require.Equal(t, customError.ErrUserNotFound, err)
+			},
+		},
+		{
+			name:    "InvalidName",
+			request: dto.UpdateProfileRequest{Name: name("   "), UpdatedAt: updatedAt},
+			buildStubs: func(mockAuth *mock.MockAuth) {
+				mockAuth.EXPECT().
+					UpdateUserProfile(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, user *sqlc.User, err error) {
+				require.Equal(t, customError.ErrInvalidName, err)
+			},
+		},
+		{
+			name:    "NothingToUpdate",
+			request: dto.UpdateProfileRequest{UpdatedAt: updatedAt},
+			buildStubs: func(mockAuth *mock.MockAuth) {
+				mockAuth.EXPECT().
+					UpdateUserProfile(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, user *sqlc.User, err error) {
+				require.Equal(t, customError.ErrNoProfileChanges, err)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockAuth := mock.NewMockAuth(ctrl)
+			tc.buildStubs(mockAuth)
+
+			authService := services.NewAuthenticator(mockAuth)
+			user, err := authService.UpdateProfile(context.Background(), userID, tc.request)
+
+			tc.checkResponse(t, user, err)
+		})
+	}
+}
@@ -0,0 +1,200 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+
+	customError "github.com/suryansh74/auth-package/internal/apperrors"
+	"github.com/suryansh74/auth-package/internal/db/mock"
+	"github.com/suryansh74/auth-package/internal/db/sqlc"
+	"github.com/suryansh74/auth-package/internal/dto"
+	"github.com/suryansh74/auth-package/internal/services"
+	"github.com/suryansh74/auth-package/internal/utils"
+)
+
+func TestRefreshSession(t *testing.T) {
+	refreshToken := "refresh-token"
+	userID := pgtype.UUID{Bytes: uuid.New(), Valid: true}
+	familyID := pgtype.UUID{Bytes: uuid.New(), Valid: true}
+
+	activeSession := func() sqlc.Session {
+		return sqlc.Session{
+			ID:               pgtype.UUID{Bytes: uuid.New(), Valid: true},
+			UserID:           userID,
+			FamilyID:         familyID,
+			RefreshTokenHash: utils.HashToken(refreshToken),
+			ExpiresAt:        pgtype.Timestamp{Time: time.Now().Add(time.Hour), Valid: true},
+		}
+	}
+
+	testCases := []struct {
+		name          string
+		buildStubs    func(mockAuth *mock.MockAuth)
+		checkResponse func(t *testing.T, token string, session *sqlc.Session, err error)
+	}{
+		{
+			name: "OK",
+			buildStubs: func(mockAuth *mock.MockAuth) {
+				session := activeSession()
+				mockAuth.EXPECT().
+					GetSessionByRefreshTokenHash(gomock.Any(), gomock.Eq(utils.HashToken(refreshToken))).
+					Times(1).
+					Return(session, nil)
+
+				mockAuth.EXPECT().
+					MarkSessionUsed(gomock.Any(), gomock.Eq(session.ID)).
+					Times(1).
+					Return(session, nil)
+
+				mockAuth.EXPECT().
+					CreateSession(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(ctx interface{}, params sqlc.CreateSessionParams) (sqlc.Session, error) {
+						return sqlc.Session{
+							ID:               pgtype.UUID{Bytes: uuid.New(), Valid: true},
+							UserID:           params.UserID,
+							FamilyID:         params.FamilyID,
+							RefreshTokenHash: params.RefreshTokenHash,
+							ExpiresAt:        params.ExpiresAt,
+						}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, token string, session *sqlc.Session, err error) {
+				require.NoError(t, err)
+				require.NotEmpty(t, token)
+				require.NotEqual(t, refreshToken, token)
+				require.Equal(t, userID, session.UserID)
+				require.Equal(t, familyID, session.FamilyID)
+				require.Equal(t, utils.HashToken(token), session.RefreshTokenHash)
+			},
+		},
+		{
+			name: "NotFound",
+			buildStubs: func(mockAuth *mock.MockAuth) {
+				mockAuth.EXPECT().
+					GetSessionByRefreshTokenHash(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(sqlc.Session{}, sql.ErrNoRows)
+
+				mockAuth.EXPECT().
+					CreateSession(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, token string, session *sqlc.Session, err error) {
+				require.Equal(t, customError.ErrInvalidRefreshToken, err)
+				require.Empty(t, token)
+				require.Nil(t, session)
+			},
+		},
+		{
+			name: "Expired",
+			buildStubs: func(mockAuth *mock.MockAuth) {
+				session := activeSession()
+				session.ExpiresAt = pgtype.Timestamp{Time: time.Now().Add(-time.Minute), Valid: true}
+				mockAuth.EXPECT().
+					GetSessionByRefreshTokenHash(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(session, nil)
+
+				mockAuth.EXPECT().
+					MarkSessionUsed(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, token string, session *sqlc.Session, err error) {
+				require.Equal(t, customError.ErrExpiredRefreshToken, err)
+				require.Nil(t, session)
+			},
+		},
+		{
+			name: "ReusedTokenRevokesFamily",
+			buildStubs: func(mockAuth *mock.MockAuth) {
+				session := activeSession()
+				session.UsedAt = pgtype.Timestamp{Time: time.Now().Add(-time.Minute), Valid: true}
+				mockAuth.EXPECT().
+					GetSessionByRefreshTokenHash(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(session, nil)
+
+				mockAuth.EXPECT().
+					RevokeSessionFamily(gomock.Any(), gomock.Eq(familyID)).
+					Times(1).
+					Return(nil)
+
+				mockAuth.EXPECT().
+					CreateSession(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, token string, session *sqlc.Session, err error) {
+				require.Equal(t, customError.ErrRefreshTokenReused, err)
+				require.Nil(t, session)
+			},
+		},
+		{
+			name: "ConcurrentRotationRevokesFamily",
+			buildStubs: func(mockAuth *mock.MockAuth) {
+				session := activeSession()
+				mockAuth.EXPECT().
+					GetSessionByRefreshTokenHash(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(session, nil)
+
+				mockAuth.EXPECT().
+					MarkSessionUsed(gomock.Any(), gomock.Eq(session.ID)).
+					Times(1).
+					Return(sqlc.Session{}, sql.ErrNoRows)
+
+				mockAuth.EXPECT().
+					RevokeSessionFamily(gomock.Any(), gomock.Eq(familyID)).
+					Times(1).
+					Return(nil)
+			},
+			checkResponse: func(t *testing.T, token string, session *sqlc.Session, err error) {
+				require.Equal(t, customError.ErrRefreshTokenReused, err)
+				require.Nil(t, session)
+			},
+		},
+		{
+			name: "RevokedSession",
+			buildStubs: func(mockAuth *mock.MockAuth) {
+				session := activeSession()
+				session.RevokedAt = pgtype.Timestamp{Time: time.Now(), Valid: true}
+				mockAuth.EXPECT().
+					GetSessionByRefreshTokenHash(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(session, nil)
+
+				mockAuth.EXPECT().
+					MarkSessionUsed(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, token string, session *sqlc.Session, err error) {
+				require.Equal(t, customError.ErrInvalidRefreshToken, err)
+				require.Nil(t, session)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockAuth := mock.NewMockAuth(ctrl)
+			tc.buildStubs(mockAuth)
+
+			sessionService := services.NewSessionManager(mockAuth, time.Hour)
+			token, session, err := sessionService.RefreshSession(context.Background(), refreshToken, dto.SessionMetadata{})
+
+			tc.checkResponse(t, token, session, err)
+		})
+	}
+}
@@ -0,0 +1,340 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+
+	customError "github.com/suryansh74/auth-package/internal/apperrors"
+	"github.com/suryansh74/auth-package/internal/db/mock"
+	"github.com/suryansh74/auth-package/internal/db/sqlc"
+	"github.com/suryansh74/auth-package/internal/services"
+)
+
+func TestUpdateUserStatus(t *testing.T) {
+	user := sqlc.User{
+		ID:              pgtype.UUID{Bytes: uuid.New(), Valid: true},
+		Email:           "john@example.com",
+		EmailVerifiedAt: pgtype.Timestamp{Time: time.Now().Add(-time.Hour), Valid: true},
+		Status:          services.UserStatusActive,
+	}
+	suspended := user
+	suspended.Status = services.UserStatusSuspended
+	unverified := user
+	unverified.EmailVerifiedAt = pgtype.Timestamp{}
+	unverified.Status = services.UserStatusLocked
+	suspendedUntil := time.Now().Add(24 * time.Hour)
+
+	// updated returns the user as UpdateUserStatus writes it
+	updated := func(params sqlc.UpdateUserStatusParams) sqlc.User {
+		u := user
+		u.Status = params.Status
+		u.StatusReason = params.StatusReason
+		u.SuspendedUntil = params.SuspendedUntil
+		u.StatusChangedAt = pgtype.Timestamp{Time: time.Now(), Valid: true}
+		return u
+	}
+
+	testCases := []struct {
+		name           string
+		status         string
+		reason         string
+		suspendedUntil *time.Time
+		buildStubs     func(mockAuth *mock.MockAuth)
+		checkResponse  func(t *testing.T, user *sqlc.User, err error)
+	}{
+		{
+			name:           "Suspend",
+			status:         services.UserStatusSuspended,
+			reason:         "  spam  ",
+			suspendedUntil: &suspendedUntil,
+			buildStubs: func(mockAuth *mock.MockAuth) {
+				mockAuth.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.ID)).Times(1).Return(user, nil)
+				mockAuth.EXPECT().
+					UpdateUserStatus(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(ctx interface{}, params sqlc.UpdateUserStatusParams) (sqlc.User, error) {
+						require.Equal(t, user.ID, params.ID)
+						require.Equal(t, services.UserStatusSuspended, params.Status)
+						require.Equal(t, pgtype.Text{String: "spam", Valid: true}, params.StatusReason)
+						require.True(t, params.SuspendedUntil.Valid)
+						require.WithinDuration(t, suspendedUntil, params.SuspendedUntil.Time, time.Microsecond)
+						// the update is bound to the status the transition was checked for
+						require.Equal(t, services.UserStatusActive, params.CurrentStatus)
+						return updated(params), nil
+					})
+			},
+			checkResponse: func(t *testing.T, u *sqlc.User, err error) {
+				require.NoError(t, err)
+				require.Equal(t, services.UserStatusSuspended, u.Status)
+				require.Equal(t, "spam", u.StatusReason.String)
+			},
+		},
+		{
+			name:   "Lock",
+			status: services.UserStatusLocked,
+			reason: "credentials leaked",
+			buildStubs: func(mockAuth *mock.MockAuth) {
+				mockAuth.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.ID)).Times(1).Return(user, nil)
+				mockAuth.EXPECT().
+					UpdateUserStatus(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(ctx interface{}, params sqlc.UpdateUserStatusParams) (sqlc.User, error) {
+						require.Equal(t, services.UserStatusLocked, params.Status)
+						require.False(t, params.SuspendedUntil.Valid)
+						return updated(params), nil
+					})
+			},
+			checkResponse: func(t *testing.T, u *sqlc.User, err error) {
+				require.NoError(t, err)
+				require.Equal(t, services.UserStatusLocked, u.Status)
+			},
+		},
+		{
+			name:   "Reinstate",
+			status: services.UserStatusActive,
+			buildStubs: func(mockAuth *mock.MockAuth) {
+				mockAuth.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.ID)).Times(1).Return(suspended, nil)
+				mockAuth.EXPECT().
+					UpdateUserStatus(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(ctx interface{}, params sqlc.UpdateUserStatusParams) (sqlc.User, error) {
+						require.Equal(t, services.UserStatusActive, params.Status)
+						require.False(t, params.StatusReason.Valid)
+						require.Equal(t, services.UserStatusSuspended, params.CurrentStatus)
+						return updated(params), nil
+					})
+			},
+			checkResponse: func(t *testing.T, u *sqlc.User, err error) {
+				require.NoError(t, err)
+				require.Equal(t, services.UserStatusActive, u.Status)
+			},
+		},
+		{
+			name:   "ReinstateUnverified",
+			status: services.UserStatusActive,
+			buildStubs: func(mockAuth *mock.MockAuth) {
+				mockAuth.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.ID)).Times(1).Return(unverified, nil)
+				mockAuth.EXPECT().
+					UpdateUserStatus(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(ctx interface{}, params sqlc.UpdateUserStatusParams) (sqlc.User, error) {
+						// accounts go back to pending until the email is verified
+						require.Equal(t, services.UserStatusPending, params.Status)
+						return updated(params), nil
+					})
+			},
+			checkResponse: func(t *testing.T, u *sqlc.User, err error) {
+				require.NoError(t, err)
+				require.Equal(t, services.UserStatusPending, u.Status)
+			},
+		},
+		{
+			name:   "InvalidStatus",
+			status: services.UserStatusDeleted,
+			reason: "spam",
+			buildStubs: func(mockAuth *mock.MockAuth) {
+				mockAuth.EXPECT().GetUser(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, u *sqlc.User, err error) {
+				require.Equal(t, customError.ErrInvalidUserStatus, err)
+				require.Nil(t, u)
+			},
+		},
+		{
+			name:   "MissingReason",
+			status: services.UserStatusSuspended,
+			reason: "   ",
+			buildStubs: func(mockAuth *mock.MockAuth) {
+				mockAuth.EXPECT().GetUser(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, u *sqlc.User, err error) {
+				require.Equal(t, customError.ErrInvalidStatusReason, err)
+				require.Nil(t, u)
+			},
+		},
+		{
+			name:           "SuspensionEndInPast",
+			status:         services.UserStatusSuspended,
+			reason:         "spam",
+			suspendedUntil: func() *time.Time { t := time.Now().Add(-time.Minute); return &t }(),
+			buildStubs: func(mockAuth *mock.MockAuth) {
+				mockAuth.EXPECT().GetUser(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, u *sqlc.User, err error) {
+				require.Equal(t, customError.ErrInvalidSuspensionEnd, err)
+				require.Nil(t, u)
+			},
+		},
+		{
+			name:           "SuspensionEndWhenLocking",
+			status:         services.UserStatusLocked,
+			reason:         "spam",
+			suspendedUntil: &suspendedUntil,
+			buildStubs: func(mockAuth *mock.MockAuth) {
+				mockAuth.EXPECT().GetUser(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, u *sqlc.User, err error) {
+				require.Equal(t, customError.ErrInvalidSuspensionEnd, err)
+				require.Nil(t, u)
+			},
+		},
+		{
+			name:   "InvalidTransition",
+			status: services.UserStatusActive,
+			buildStubs: func(mockAuth *mock.MockAuth) {
+				mockAuth.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.ID)).Times(1).Return(user, nil)
+				mockAuth.EXPECT().UpdateUserStatus(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, u *sqlc.User, err error) {
+				require.Equal(t, customError.ErrInvalidStatusTransition, err)
+				require.Nil(t, u)
+			},
+		},
+		{
+			name:   "UserNotFound",
+			status: services.UserStatusSuspended,
+			reason: "spam",
+			buildStubs: func(mockAuth *mock.MockAuth) {
+				mockAuth.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.ID)).Times(1).Return(sqlc.User{}, sql.ErrNoRows)
+				mockAuth.EXPECT().UpdateUserStatus(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, u *sqlc.User, err error) {
+				require.Equal(t, customError.ErrUserNotFound, err)
+				require.Nil(t, u)
+			},
+		},
+		{
+			name:   "Conflict",
+			status: services.UserStatusSuspended,
+			reason: "spam",
+			buildStubs: func(mockAuth *mock.MockAuth) {
+				mockAuth.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.ID)).Times(1).Return(user, nil)
+				// the status changed since it was read
+				mockAuth.EXPECT().UpdateUserStatus(gomock.Any(), gomock.Any()).Times(1).Return(sqlc.User{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, u *sqlc.User, err error) {
+				require.Equal(t, customError.ErrUserStatusConflict, err)
+				require.Nil(t, u)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockAuth := mock.NewMockAuth(ctrl)
+			tc.buildStubs(mockAuth)
+
+			statuses := services.NewUserStatusManager(mockAuth)
+			u, err := statuses.UpdateUserStatus(context.Background(), user.ID, tc.status, tc.reason, tc.suspendedUntil)
+
+			tc.checkResponse(t, u, err)
+		})
+	}
+}
+
+func TestCheckAccountStatus(t *testing.T) {
+	userID := pgtype.UUID{Bytes: uuid.New(), Valid: true}
+
+	testCases := []struct {
+		name       string
+		buildStubs func(mockAuth *mock.MockAuth)
+		err        error
+	}{
+		{
+			name: "Active",
+			buildStubs: func(mockAuth *mock.MockAuth) {
+				mockAuth.EXPECT().GetUserStatus(gomock.Any(), gomock.Eq(userID)).Times(1).
+					Return(sqlc.GetUserStatusRow{Status: services.UserStatusActive}, nil)
+			},
+		},
+		{
+			name: "Pending",
+			buildStubs: func(mockAuth *mock.MockAuth) {
+				mockAuth.EXPECT().GetUserStatus(gomock.Any(), gomock.Eq(userID)).Times(1).
+					Return(sqlc.GetUserStatusRow{Status: services.UserStatusPending}, nil)
+			},
+		},
+		{
+			name: "Suspended",
+			buildStubs: func(mockAuth *mock.MockAuth) {
+				mockAuth.EXPECT().GetUserStatus(gomock.Any(), gomock.Eq(userID)).Times(1).
+					Return(sqlc.GetUserStatusRow{
+						Status:         services.UserStatusSuspended,
+						SuspendedUntil: pgtype.Timestamp{Time: time.Now().Add(time.Hour), Valid: true},
+					}, nil)
+			},
+			err: customError.ErrAccountSuspended,
+		},
+		{
+			name: "SuspensionOver",
+			buildStubs: func(mockAuth *mock.MockAuth) {
+				mockAuth.EXPECT().GetUserStatus(gomock.Any(), gomock.Eq(userID)).Times(1).
+					Return(sqlc.GetUserStatusRow{
+						Status:         services.UserStatusSuspended,
+						SuspendedUntil: pgtype.Timestamp{Time: time.Now().Add(-time.Minute), Valid: true},
+					}, nil)
+			},
+		},
+		{
+			name: "Locked",
+			buildStubs: func(mockAuth *mock.MockAuth) {
+				mockAuth.EXPECT().GetUserStatus(gomock.Any(), gomock.Eq(userID)).Times(1).
+					Return(sqlc.GetUserStatusRow{Status: services.UserStatusLocked}, nil)
+			},
+			err: customError.ErrAccountLocked,
+		},
+		{
+			name: "Deleted",
+			buildStubs: func(mockAuth *mock.MockAuth) {
+				mockAuth.EXPECT().GetUserStatus(gomock.Any(), gomock.Eq(userID)).Times(1).
+					Return(sqlc.GetUserStatusRow{Status: services.UserStatusDeleted}, nil)
+			},
+			err: customError.ErrAccountDeleted,
+		},
+		{
+			name: "Purged",
+			buildStubs: func(mockAuth *mock.MockAuth) {
+				mockAuth.EXPECT().GetUserStatus(gomock.Any(), gomock.Eq(userID)).Times(1).
+					Return(sqlc.GetUserStatusRow{}, sql.ErrNoRows)
+			},
+			err: customError.ErrAccountDeleted,
+		},
+		{
+			name: "InternalError",
+			buildStubs: func(mockAuth *mock.MockAuth) {
+				mockAuth.EXPECT().GetUserStatus(gomock.Any(), gomock.Eq(userID)).Times(1).
+					Return(sqlc.GetUserStatusRow{}, sql.ErrConnDone)
+			},
+			err: customError.UnExpectedError,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockAuth := mock.NewMockAuth(ctrl)
+			tc.buildStubs(mockAuth)
+
+			statuses := services.NewUserStatusManager(mockAuth)
+			err := statuses.CheckAccountStatus(context.Background(), userID)
+
+			require.Equal(t, tc.err, err)
+		})
+	}
+}
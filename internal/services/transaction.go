@@ -0,0 +1,26 @@
+package services
+
+import (
+	"context"
+
+	customError "github.com/suryansh74/auth-package/internal/apperrors"
+	"github.com/suryansh74/auth-package/internal/db"
+)
+
+// execTx runs fn in a transaction of auth. Errors returned by fn are
+// returned as they are, while failures to begin or commit the transaction
+// are unexpected.
+func execTx(ctx context.Context, auth db.Auth, fn func(auth db.Auth) error) error {
+	var fnErr error
+	err := auth.ExecTx(ctx, func(auth db.Auth) error {
+		fnErr = fn(auth)
+		return fnErr
+	})
+	if fnErr != nil {
+		return fnErr
+	}
+	if err != nil {
+		return customError.UnExpectedError
+	}
+	return nil
+}
@@ -4,6 +4,9 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"strings"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/jackc/pgx/v5/pgtype"
 	customError "github.com/suryansh74/auth-package/internal/apperrors"
@@ -11,6 +14,7 @@ import (
 	"github.com/suryansh74/auth-package/internal/db/sqlc"
 	"github.com/suryansh74/auth-package/internal/dto"
 	"github.com/suryansh74/auth-package/internal/utils"
+	"github.com/suryansh74/auth-package/token"
 )
 
 type AuthService interface {
@@ -18,16 +22,36 @@ type AuthService interface {
 	Register(ctx context.Context, req dto.UserRegisterRequest) (*dto.UserRegisterResponse, error)
 	Login(ctx context.Context, req dto.UserLoginRequest) (*dto.UserLoginResponse, error)
 	GetUserByID(ctx context.Context, userID pgtype.UUID) (*sqlc.User, error)
+	GetUserByEmail(ctx context.Context, email string) (*sqlc.User, error)
+	ChangePassword(ctx context.Context, userID pgtype.UUID, currentPassword, newPassword string) (*sqlc.User, error)
+	VerifyEmail(ctx context.Context, userID pgtype.UUID, email string) (*sqlc.User, error)
+	UpdateProfile(ctx context.Context, userID pgtype.UUID, req dto.UpdateProfileRequest) (*sqlc.User, error)
 }
 
 type Authenticator struct {
-	auth db.Auth
+	auth  db.Auth
+	clock token.Clock
 }
 
-func NewAuthenticator(auth db.Auth) AuthService {
-	return &Authenticator{
-		auth: auth,
+// AuthenticatorOption configures an Authenticator
+type AuthenticatorOption func(*Authenticator)
+
+// WithClock sets the clock used to record when passwords change
+func WithClock(clock token.Clock) AuthenticatorOption {
+	return func(a *Authenticator) {
+		a.clock = clock
+	}
+}
+
+func NewAuthenticator(auth db.Auth, opts ...AuthenticatorOption) AuthService {
+	a := &Authenticator{
+		auth:  auth,
+		clock: token.SystemClock,
 	}
+	for _, opt := range opts {
+		opt(a)
+	}
+	return a
 }
 
 func (a *Authenticator) Register(ctx context.Context, req dto.UserRegisterRequest) (*dto.UserRegisterResponse, error) {
@@ -39,6 +63,16 @@ func (a *Authenticator) Register(ctx context.Context, req dto.UserRegisterReques
 	if exists {
 		return nil, customError.ErrUserAlreadyExist
 	}
+	deleted, err := heldByDeletedUser(ctx, a.auth, req.Email)
+	if err != nil {
+		return nil, err
+	}
+	if deleted {
+		return nil, customError.ErrUserAlreadyExist
+	}
+	if !utils.ValidPassword(req.Password) {
+		return nil, customError.ErrWeakPassword
+	}
 
 	// create hash password
 	hashedPassword, err := utils.HashedPassword(req.Password)
@@ -81,10 +115,15 @@ func (a *Authenticator) Login(ctx context.Context, req dto.UserLoginRequest) (*d
 	if err != nil {
 		return nil, errors.New("password not matched")
 	}
+	// the status is only told to whoever knows the password
+	if err := AccountStatusError(user); err != nil {
+		return nil, err
+	}
 
 	userResponse := dto.UserLoginResponse{
-		UserID: user.ID,
-		Email:  req.Email,
+		UserID:        user.ID,
+		Email:         req.Email,
+		EmailVerified: user.EmailVerifiedAt.Valid,
 	}
 	return &userResponse, nil
 }
@@ -107,3 +146,115 @@ func (a *Authenticator) GetUserByID(ctx context.Context, userID pgtype.UUID) (*s
 	}
 	return &user, nil
 }
+
+func (a *Authenticator) GetUserByEmail(ctx context.Context, email string) (*sqlc.User, error) {
+	exists, user, err := a.userExists(ctx, email)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, customError.ErrUserNotFound
+	}
+	return user, nil
+}
+
+// ChangePassword replaces the password of the user after checking the
+// current one, and records when it changed.
+func (a *Authenticator) ChangePassword(ctx context.Context, userID pgtype.UUID, currentPassword, newPassword string) (*sqlc.User, error) {
+	user, err := a.auth.GetUser(ctx, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, customError.ErrUserNotFound
+		}
+		return nil, customError.UnExpectedError
+	}
+	if utils.CheckPassword(currentPassword, user.Password) != nil {
+		return nil, customError.ErrIncorrectPassword
+	}
+	if !utils.ValidPassword(newPassword) {
+		return nil, customError.ErrWeakPassword
+	}
+	if newPassword == currentPassword {
+		return nil, customError.ErrPasswordNotChanged
+	}
+
+	hashedPassword, err := utils.HashedPassword(newPassword)
+	if err != nil {
+		return nil, customError.UnExpectedError
+	}
+	user, err = a.auth.UpdateUserPassword(ctx, sqlc.UpdateUserPasswordParams{
+		ID:                userID,
+		Password:          hashedPassword,
+		PasswordChangedAt: pgtype.Timestamp{Time: a.clock.Now(), Valid: true},
+	})
+	if err != nil {
+		return nil, customError.UnExpectedError
+	}
+	return &user, nil
+}
+
+// VerifyEmail records that the user owns email. It fails with
+// ErrInvalidVerificationToken when email is no longer the address of the
+// user, so links sent to a previous address cannot verify the current one.
+// Verifying again keeps the first verification time.
+func (a *Authenticator) VerifyEmail(ctx context.Context, userID pgtype.UUID, email string) (*sqlc.User, error) {
+	user, err := a.auth.VerifyUserEmail(ctx, sqlc.VerifyUserEmailParams{
+		ID:    userID,
+		Email: email,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, customError.ErrInvalidVerificationToken
+		}
+		return nil, customError.UnExpectedError
+	}
+	return &user, nil
+}
+
+// UpdateProfile applies the fields set in req. The update only goes through
+// when the profile still has the updated_at the client read, otherwise
+// ErrProfileConflict is returned and nothing changes.
+func (a *Authenticator) UpdateProfile(ctx context.Context, userID pgtype.UUID, req dto.UpdateProfileRequest) (*sqlc.User, error) {
+	if req.Name == nil {
+		return nil, customError.ErrNoProfileChanges
+	}
+	name := strings.TrimSpace(*req.Name)
+	if !validName(name) {
+		return nil, customError.ErrInvalidName
+	}
+
+	user, err := a.auth.UpdateUserProfile(ctx, sqlc.UpdateUserProfileParams{
+		Name: pgtype.Text{String: name, Valid: true},
+		ID:   userID,
+		// updated_at has no time zone and is compared as read
+		UpdatedAt: pgtype.Timestamp{Time: req.UpdatedAt.UTC(), Valid: true},
+	})
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			return nil, customError.UnExpectedError
+		}
+		// either the user is gone or the profile changed meanwhile
+		if _, err := a.auth.GetUser(ctx, userID); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil, customError.ErrUserNotFound
+			}
+			return nil, customError.UnExpectedError
+		}
+		return nil, customError.ErrProfileConflict
+	}
+	return &user, nil
+}
+
+// validName checks that name fits the users table and holds no control
+// characters
+func validName(name string) bool {
+	if name == "" || utf8.RuneCountInString(name) > 255 {
+		return false
+	}
+	for _, r := range name {
+		if unicode.IsControl(r) {
+			return false
+		}
+	}
+	return true
+}
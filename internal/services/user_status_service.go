@@ -0,0 +1,177 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	customError "github.com/suryansh74/auth-package/internal/apperrors"
+	"github.com/suryansh74/auth-package/internal/db"
+	"github.com/suryansh74/auth-package/internal/db/sqlc"
+)
+
+// Statuses of a user account
+const (
+	// UserStatusPending accounts have not verified their email yet; what
+	// they may do is decided by the unverified login policy
+	UserStatusPending = "pending"
+	UserStatusActive  = "active"
+	// UserStatusSuspended accounts are blocked by an admin, until lifted or
+	// until the end of the suspension when one is set
+	UserStatusSuspended = "suspended"
+	// UserStatusLocked accounts are blocked for their safety, until an
+	// admin unlocks them or the owner resets the password
+	UserStatusLocked = "locked"
+	// UserStatusDeleted accounts are deleted by their owner and can be
+	// restored until purged
+	UserStatusDeleted = "deleted"
+)
+
+// statusReasonMaxLength bounds the reason given for a status change
+const statusReasonMaxLength = 500
+
+// userStatusTransitions lists the statuses an admin may move an account to
+// from each status. Pending accounts become active by verifying their email
+// and deleted ones are only left by restoring them.
+var userStatusTransitions = map[string][]string{
+	UserStatusPending:   {UserStatusSuspended, UserStatusLocked},
+	UserStatusActive:    {UserStatusSuspended, UserStatusLocked},
+	UserStatusSuspended: {UserStatusActive, UserStatusSuspended, UserStatusLocked},
+	UserStatusLocked:    {UserStatusActive, UserStatusSuspended},
+}
+
+// UserStatusService manages the status of user accounts, which decides
+// whether they may sign in and use their tokens
+type UserStatusService interface {
+	UpdateUserStatus(ctx context.Context, userID pgtype.UUID, status, reason string, suspendedUntil *time.Time) (*sqlc.User, error)
+	CheckAccountStatus(ctx context.Context, userID pgtype.UUID) error
+}
+
+type UserStatusManager struct {
+	auth db.Auth
+}
+
+func NewUserStatusManager(auth db.Auth) UserStatusService {
+	return &UserStatusManager{
+		auth: auth,
+	}
+}
+
+// UpdateUserStatus moves the account to status, when allowed from its
+// current status. Suspending and locking require a reason, and a
+// suspension may be given an end. Reinstating an account that has not
+// verified its email makes it pending rather than active.
+func (u *UserStatusManager) UpdateUserStatus(ctx context.Context, userID pgtype.UUID, status, reason string, suspendedUntil *time.Time) (*sqlc.User, error) {
+	switch status {
+	case UserStatusActive, UserStatusSuspended, UserStatusLocked:
+	default:
+		return nil, customError.ErrInvalidUserStatus
+	}
+	reason = strings.TrimSpace(reason)
+	if !validStatusReason(reason) || (reason == "" && status != UserStatusActive) {
+		return nil, customError.ErrInvalidStatusReason
+	}
+	if suspendedUntil != nil && (status != UserStatusSuspended || !suspendedUntil.After(time.Now())) {
+		return nil, customError.ErrInvalidSuspensionEnd
+	}
+
+	user, err := u.auth.GetUser(ctx, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, customError.ErrUserNotFound
+		}
+		return nil, customError.UnExpectedError
+	}
+	if !slices.Contains(userStatusTransitions[user.Status], status) {
+		return nil, customError.ErrInvalidStatusTransition
+	}
+	if status == UserStatusActive {
+		status = reinstatedStatus(&user)
+	}
+
+	params := sqlc.UpdateUserStatusParams{
+		Status:        status,
+		StatusReason:  pgtype.Text{String: reason, Valid: reason != ""},
+		ID:            userID,
+		CurrentStatus: user.Status,
+	}
+	if suspendedUntil != nil {
+		// suspended_until has no time zone, like the other timestamps
+		params.SuspendedUntil = pgtype.Timestamp{Time: suspendedUntil.UTC(), Valid: true}
+	}
+	// the update only applies to the status the transition was checked for
+	user, err = u.auth.UpdateUserStatus(ctx, params)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, customError.ErrUserStatusConflict
+		}
+		return nil, customError.UnExpectedError
+	}
+	return &user, nil
+}
+
+// CheckAccountStatus returns the error of the status that keeps the user
+// from using the account, or nil when the account may be used. Users that
+// no longer exist count as deleted.
+func (u *UserStatusManager) CheckAccountStatus(ctx context.Context, userID pgtype.UUID) error {
+	status, err := u.auth.GetUserStatus(ctx, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return customError.ErrAccountDeleted
+		}
+		return customError.UnExpectedError
+	}
+	return statusError(status.Status, status.SuspendedUntil, time.Now())
+}
+
+// AccountStatusError returns the error of the status that keeps user from
+// signing in, or nil when the account may be used
+func AccountStatusError(user *sqlc.User) error {
+	if user.DeletedAt.Valid {
+		return customError.ErrAccountDeleted
+	}
+	return statusError(user.Status, user.SuspendedUntil, time.Now())
+}
+
+// statusError tells whether status blocks the account at now. Suspensions
+// end by themselves once suspendedUntil is over.
+func statusError(status string, suspendedUntil pgtype.Timestamp, now time.Time) error {
+	switch status {
+	case UserStatusSuspended:
+		if suspendedUntil.Valid && !now.Before(suspendedUntil.Time) {
+			return nil
+		}
+		return customError.ErrAccountSuspended
+	case UserStatusLocked:
+		return customError.ErrAccountLocked
+	case UserStatusDeleted:
+		return customError.ErrAccountDeleted
+	}
+	return nil
+}
+
+// reinstatedStatus is the status an account returns to once unblocked
+func reinstatedStatus(user *sqlc.User) string {
+	if !user.EmailVerifiedAt.Valid {
+		return UserStatusPending
+	}
+	return UserStatusActive
+}
+
+func validStatusReason(reason string) bool {
+	if utf8.RuneCountInString(reason) > statusReasonMaxLength {
+		return false
+	}
+	for _, r := range reason {
+		if unicode.IsControl(r) {
+			return false
+		}
+	}
+	return true
+}
@@ -1,6 +1,8 @@
 package utils
 
 import (
+	"unicode"
+
 	"golang.org/x/crypto/bcrypt"
 )
 
@@ -17,3 +19,29 @@ func HashedPassword(password string) (string, error) {
 func CheckPassword(password string, hashedPassword string) error {
 	return bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(password))
 }
+
+const (
+	// MinPasswordLength is the shortest password accepted
+	MinPasswordLength = 8
+	// MaxPasswordLength is the longest password bcrypt can hash, in bytes
+	MaxPasswordLength = 72
+)
+
+// ValidPassword reports whether the password meets the password policy:
+// MinPasswordLength to MaxPasswordLength bytes long, with at least one
+// letter and one digit
+func ValidPassword(password string) bool {
+	if len(password) < MinPasswordLength || len(password) > MaxPasswordLength {
+		return false
+	}
+	var letter, digit bool
+	for _, r := range password {
+		switch {
+		case unicode.IsLetter(r):
+			letter = true
+		case unicode.IsDigit(r):
+			digit = true
+		}
+	}
+	return letter && digit
+}
@@ -0,0 +1,30 @@
+package mailer
+
+import (
+	"context"
+	"log"
+)
+
+// LogMailer writes messages to a logger instead of sending them. It is meant
+// for development, where the links in the messages can be copied from the
+// log.
+type LogMailer struct {
+	logger *log.Logger
+}
+
+// NewLogMailer creates a LogMailer writing to logger, or to the standard
+// logger when it is nil
+func NewLogMailer(logger *log.Logger) *LogMailer {
+	if logger == nil {
+		logger = log.Default()
+	}
+	return &LogMailer{logger: logger}
+}
+
+func (m *LogMailer) Send(ctx context.Context, msg Message) error {
+	if err := msg.Validate(); err != nil {
+		return err
+	}
+	m.logger.Printf("mail to %s: %s\n%s", msg.To, msg.Subject, msg.Body)
+	return nil
+}
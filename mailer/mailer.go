@@ -0,0 +1,37 @@
+// Package mailer sends the emails of the auth server, such as password reset
+// links, through a pluggable Mailer
+package mailer
+
+import (
+	"context"
+	"errors"
+	"strings"
+)
+
+// ErrInvalidHeader is returned for messages whose recipient or subject
+// contain line breaks, which could inject extra headers
+var ErrInvalidHeader = errors.New("mailer: invalid header value")
+
+// Message is a plain text email
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer delivers messages
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// Validate checks that the message has a recipient and that its headers
+// cannot be used to inject other headers
+func (msg Message) Validate() error {
+	if msg.To == "" {
+		return errors.New("mailer: message has no recipient")
+	}
+	if strings.ContainsAny(msg.To, "\r\n") || strings.ContainsAny(msg.Subject, "\r\n") {
+		return ErrInvalidHeader
+	}
+	return nil
+}
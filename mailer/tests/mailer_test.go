@@ -0,0 +1,163 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"net"
+	"net/textproto"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/suryansh74/auth-package/mailer"
+)
+
+func TestMemoryMailer(t *testing.T) {
+	m := mailer.NewMemoryMailer()
+	ctx := context.Background()
+
+	require.NoError(t, m.Send(ctx, mailer.Message{To: "a@example.com", Subject: "first", Body: "1"}))
+	require.NoError(t, m.Send(ctx, mailer.Message{To: "b@example.com", Subject: "second", Body: "2"}))
+	require.NoError(t, m.Send(ctx, mailer.Message{To: "a@example.com", Subject: "third", Body: "3"}))
+	require.Len(t, m.Messages(), 3)
+
+	last, ok := m.Last("a@example.com")
+	require.True(t, ok)
+	require.Equal(t, "third", last.Subject)
+
+	_, ok = m.Last("c@example.com")
+	require.False(t, ok)
+
+	m.Reset()
+	require.Empty(t, m.Messages())
+}
+
+func TestLogMailer(t *testing.T) {
+	var buf bytes.Buffer
+	m := mailer.NewLogMailer(log.New(&buf, "", 0))
+
+	err := m.Send(context.Background(), mailer.Message{To: "a@example.com", Subject: "Reset", Body: "https://example.com/reset?token=abc"})
+	require.NoError(t, err)
+	require.Contains(t, buf.String(), "a@example.com")
+	require.Contains(t, buf.String(), "token=abc")
+}
+
+func TestHeaderInjectionRejected(t *testing.T) {
+	m := mailer.NewMemoryMailer()
+
+	err := m.Send(context.Background(), mailer.Message{To: "a@example.com\r\nBcc: b@example.com", Subject: "hi"})
+	require.ErrorIs(t, err, mailer.ErrInvalidHeader)
+
+	err = m.Send(context.Background(), mailer.Message{To: "a@example.com", Subject: "hi\nBcc: b@example.com"})
+	require.ErrorIs(t, err, mailer.ErrInvalidHeader)
+
+	err = m.Send(context.Background(), mailer.Message{Subject: "hi"})
+	require.Error(t, err)
+	require.Empty(t, m.Messages())
+}
+
+func TestNewSMTPMailer(t *testing.T) {
+	_, err := mailer.NewSMTPMailer(mailer.SMTPConfig{From: "auth@example.com"})
+	require.Error(t, err)
+
+	_, err = mailer.NewSMTPMailer(mailer.SMTPConfig{Host: "smtp.example.com"})
+	require.Error(t, err)
+
+	_, err = mailer.NewSMTPMailer(mailer.SMTPConfig{Host: "smtp.example.com", From: "auth@example.com"})
+	require.NoError(t, err)
+}
+
+// serveSMTP answers one SMTP session on listener without offering
+// STARTTLS, returning the commands it received
+func serveSMTP(listener net.Listener) <-chan []string {
+	commands := make(chan []string, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			commands <- nil
+			return
+		}
+		defer conn.Close()
+		text := textproto.NewConn(conn)
+		var received []string
+		defer func() { commands <- received }()
+
+		text.PrintfLine("220 localhost ESMTP")
+		for {
+			line, err := text.ReadLine()
+			if err != nil {
+				return
+			}
+			received = append(received, strings.Fields(line)[0])
+			switch strings.ToUpper(strings.Fields(line)[0]) {
+			case "EHLO":
+				text.PrintfLine("250-localhost")
+				text.PrintfLine("250 8BITMIME")
+			case "DATA":
+				text.PrintfLine("354 go ahead")
+				if _, err := text.ReadDotLines(); err != nil {
+					return
+				}
+				text.PrintfLine("250 queued")
+			case "QUIT":
+				text.PrintfLine("221 bye")
+				return
+			default:
+				text.PrintfLine("250 ok")
+			}
+		}
+	}()
+	return commands
+}
+
+func TestSMTPMailerRequiresTLS(t *testing.T) {
+	testCases := []struct {
+		name           string
+		allowPlaintext bool
+		checkResult    func(t *testing.T, err error, commands []string)
+	}{
+		{
+			name: "Refused",
+			checkResult: func(t *testing.T, err error, commands []string) {
+				require.ErrorIs(t, err, mailer.ErrTLSRequired)
+				require.NotContains(t, commands, "MAIL")
+			},
+		},
+		{
+			name:           "AllowPlaintext",
+			allowPlaintext: true,
+			checkResult: func(t *testing.T, err error, commands []string) {
+				require.NoError(t, err)
+				require.Contains(t, commands, "DATA")
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			listener, err := net.Listen("tcp", "127.0.0.1:0")
+			require.NoError(t, err)
+			defer listener.Close()
+			commands := serveSMTP(listener)
+
+			port := listener.Addr().(*net.TCPAddr).Port
+			m, err := mailer.NewSMTPMailer(mailer.SMTPConfig{
+				Host:           "127.0.0.1",
+				Port:           port,
+				From:           "auth@example.com",
+				AllowPlaintext: tc.allowPlaintext,
+			})
+			require.NoError(t, err)
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			err = m.Send(ctx, mailer.Message{To: "a@example.com", Subject: "hi", Body: "hello"})
+			listener.Close()
+			tc.checkResult(t, err, <-commands)
+		})
+	}
+}
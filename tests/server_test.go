@@ -0,0 +1,109 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/require"
+
+	auth "github.com/suryansh74/auth-package"
+	"github.com/suryansh74/auth-package/internal/utils"
+)
+
+// testConfig returns a configuration that needs no database connection
+// until a handler queries it
+func testConfig() auth.Config {
+	return auth.Config{
+		TokenSymmetricKey: utils.RandomString(32),
+		RevocationStore:   auth.RevocationStoreMemory,
+		Mailer:            auth.MailerMemory,
+	}
+}
+
+func TestIntrospectionClients(t *testing.T) {
+	testCases := []struct {
+		name    string
+		clients []string
+		isValid bool
+	}{
+		{name: "Valid", clients: []string{"billing:secret", " reports:other-secret "}, isValid: true},
+		{name: "EmptyEntriesSkipped", clients: []string{"", "  ", "billing:secret"}, isValid: true},
+		{name: "SecretWithColon", clients: []string{"billing:se:cret"}, isValid: true},
+		{name: "MissingSecret", clients: []string{"billing:"}},
+		{name: "MissingID", clients: []string{":secret"}},
+		{name: "NoSeparator", clients: []string{"billing"}},
+		{name: "Duplicate", clients: []string{"billing:a", "billing:b"}},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			config := testConfig()
+			config.IntrospectionClients = tc.clients
+			server, err := auth.NewAuthServer(fiber.New(), nil, config)
+			if tc.isValid {
+				require.NoError(t, err)
+				require.NotNil(t, server)
+			} else {
+				require.Error(t, err)
+				require.Nil(t, server)
+			}
+		})
+	}
+}
+
+func TestIntrospectionRequiresConfiguredClient(t *testing.T) {
+	config := testConfig()
+	config.IntrospectionClients = []string{"billing:se:cret"}
+	app := fiber.New()
+	server, err := auth.NewAuthServer(app, nil, config)
+	require.NoError(t, err)
+	server.SetupRoutes()
+
+	testCases := []struct {
+		name         string
+		clientSecret string
+		status       int
+	}{
+		// the secret is everything after the first colon
+		{name: "Configured", clientSecret: "se:cret", status: http.StatusOK},
+		{name: "Truncated", clientSecret: "se", status: http.StatusUnauthorized},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			form := url.Values{
+				"token":         {"not-a-token"},
+				"client_id":     {"billing"},
+				"client_secret": {tc.clientSecret},
+			}
+			req := httptest.NewRequest(http.MethodPost, "/auth/introspect", strings.NewReader(form.Encode()))
+			req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationForm)
+
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+			require.Equal(t, tc.status, resp.StatusCode)
+		})
+	}
+}
+
+func TestMailerRequired(t *testing.T) {
+	config := testConfig()
+	config.Mailer = ""
+	server, err := auth.NewAuthServer(fiber.New(), nil, config)
+	require.Error(t, err)
+	require.Nil(t, server)
+
+	config.Mailer = auth.MailerLog
+	server, err = auth.NewAuthServer(fiber.New(), nil, config)
+	require.NoError(t, err)
+	require.NotNil(t, server)
+}
@@ -0,0 +1,38 @@
+package token
+
+import "errors"
+
+// ErrInvalidToken matches every token verification failure, so callers that
+// do not care about the reason can check errors.Is(err, ErrInvalidToken)
+var ErrInvalidToken = errors.New("token is invalid")
+
+// Verification failures. Each of them also matches ErrInvalidToken.
+var (
+	ErrMalformedToken    = newVerificationError("token is malformed")
+	ErrInvalidSignature  = newVerificationError("token signature is invalid")
+	ErrExpiredToken      = newVerificationError("token is expired")
+	ErrTokenNotYetValid  = newVerificationError("token is not valid yet")
+	ErrRevokedToken      = newVerificationError("token is revoked")
+	ErrInvalidPurpose    = newVerificationError("token was issued for another purpose")
+	ErrInvalidIssuer     = newVerificationError("token has an unexpected issuer")
+	ErrInvalidAudience   = newVerificationError("token is not intended for this audience")
+	ErrInsufficientScope = newVerificationError("token is missing a required scope")
+)
+
+var ErrReservedClaimInUse = errors.New("extra claim uses a reserved name")
+
+type verificationError struct {
+	message string
+}
+
+func newVerificationError(message string) error {
+	return &verificationError{message: message}
+}
+
+func (e *verificationError) Error() string {
+	return e.message
+}
+
+func (e *verificationError) Is(target error) bool {
+	return target == ErrInvalidToken
+}
@@ -0,0 +1,125 @@
+package token
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// JSONWebKey is a public verification key as published in a JWK Set
+// (RFC 7517). Ed25519 keys use the OKP key type of RFC 8037.
+type JSONWebKey struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use,omitempty"`
+	Algorithm string `json:"alg,omitempty"`
+	Curve     string `json:"crv,omitempty"`
+	X         string `json:"x,omitempty"`
+	N         string `json:"n,omitempty"`
+	E         string `json:"e,omitempty"`
+}
+
+// JSONWebKeySet is the document served at /.well-known/jwks.json
+type JSONWebKeySet struct {
+	Keys []JSONWebKey `json:"keys"`
+}
+
+// KeySetPublisher is implemented by makers that can publish their
+// verification keys, active and retired, so other services can verify
+// tokens without sharing a secret
+type KeySetPublisher interface {
+	PublicKeySet() JSONWebKeySet
+}
+
+// NewJSONWebKey encodes an RSA or Ed25519 public key. The algorithm is
+// optional and left out of the key when empty.
+func NewJSONWebKey(keyID, algorithm string, key crypto.PublicKey) (JSONWebKey, error) {
+	jwk := JSONWebKey{KeyID: keyID, Use: "sig", Algorithm: algorithm}
+
+	switch key := key.(type) {
+	case ed25519.PublicKey:
+		jwk.KeyType = "OKP"
+		jwk.Curve = "Ed25519"
+		jwk.X = base64.RawURLEncoding.EncodeToString(key)
+	case *rsa.PublicKey:
+		jwk.KeyType = "RSA"
+		jwk.N = base64.RawURLEncoding.EncodeToString(key.N.Bytes())
+		jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes())
+	default:
+		return JSONWebKey{}, fmt.Errorf("unsupported public key type %T", key)
+	}
+	return jwk, nil
+}
+
+// PublicKey decodes the key
+func (jwk JSONWebKey) PublicKey() (crypto.PublicKey, error) {
+	switch jwk.KeyType {
+	case "OKP":
+		if jwk.Curve != "Ed25519" {
+			return nil, fmt.Errorf("unsupported curve %q", jwk.Curve)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(jwk.X)
+		if err != nil || len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("invalid Ed25519 key")
+		}
+		return ed25519.PublicKey(x), nil
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(jwk.N)
+		if err != nil {
+			return nil, errors.New("invalid RSA modulus")
+		}
+		e, err := base64.RawURLEncoding.DecodeString(jwk.E)
+		if err != nil || len(e) == 0 || len(e) > 4 {
+			return nil, errors.New("invalid RSA exponent")
+		}
+		return &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}, nil
+	}
+	return nil, fmt.Errorf("unsupported key type %q", jwk.KeyType)
+}
+
+// PublicKeyID derives a stable key ID from a public key
+func PublicKeyID(key crypto.PublicKey) (string, error) {
+	der, err := x509.MarshalPKIXPublicKey(key)
+	if err != nil {
+		return "", err
+	}
+	return KeyID(der), nil
+}
+
+// publicKeySet encodes every key in ring
+func publicKeySet(ring *KeyRing, algorithm string) JSONWebKeySet {
+	set := JSONWebKeySet{Keys: []JSONWebKey{}}
+	for _, id := range ring.KeyIDs() {
+		key, err := ring.key(id)
+		if err != nil {
+			continue
+		}
+		jwk, err := NewJSONWebKey(id, algorithm, key)
+		if err != nil {
+			continue
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+	return set
+}
+
+// addPublicKey adds key to ring under its derived key ID and returns the ID.
+// Adding a key that is already in the ring is not an error.
+func addPublicKey(ring *KeyRing, key crypto.PublicKey) (string, error) {
+	id, err := PublicKeyID(key)
+	if err != nil {
+		return "", err
+	}
+	if _, err := ring.key(id); err == nil {
+		return id, nil
+	}
+	return id, ring.AddKey(id, key)
+}
@@ -0,0 +1,457 @@
+package token
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Supported JWT signing algorithms
+const (
+	AlgorithmHS256 = "HS256"
+	AlgorithmRS256 = "RS256"
+	AlgorithmEdDSA = "EdDSA"
+)
+
+const minHMACKeySize = 32
+
+type jwtHeader struct {
+	Algorithm string `json:"alg"`
+	Type      string `json:"typ,omitempty"`
+	KeyID     string `json:"kid,omitempty"`
+}
+
+// jwtClaims maps Payload to the registered JWT claims. Extra claims of the
+// payload are written next to them at the top level of the claims set.
+type jwtClaims struct {
+	ID        string   `json:"jti"`
+	Subject   string   `json:"sub"`
+	Email     string   `json:"email"`
+	SessionID string   `json:"sid,omitempty"`
+	OrgID     string   `json:"org_id,omitempty"`
+	Purpose   string   `json:"purpose,omitempty"`
+	Issuer    string   `json:"iss,omitempty"`
+	Audience  audience `json:"aud,omitempty"`
+	Roles     []string `json:"roles,omitempty"`
+	Scope     string   `json:"scope,omitempty"`
+	IssuedAt  int64    `json:"iat"`
+	NotBefore int64    `json:"nbf,omitempty"`
+	ExpiresAt int64    `json:"exp"`
+}
+
+// reservedJWTClaims are the claim names used by jwtClaims, which extra
+// claims must not override
+var reservedJWTClaims = map[string]bool{
+	"jti": true, "sub": true, "email": true, "sid": true, "org_id": true, "purpose": true, "iss": true, "aud": true,
+	"roles": true, "scope": true, "iat": true, "nbf": true, "exp": true,
+}
+
+// audience decodes the "aud" claim, which may be a single string or an array
+type audience []string
+
+func (a *audience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = audience{single}
+		return nil
+	}
+
+	var many []string
+	if err := json.Unmarshal(data, &many); err != nil {
+		return err
+	}
+	*a = many
+	return nil
+}
+
+// JWTVerifier verifies JSON Web Tokens. The algorithm is fixed when the
+// verifier is created and the "alg" header of incoming tokens must match it
+// exactly, which rules out "none" and algorithm confusion attacks. The
+// "kid" header picks the key among the active and retired ones.
+type JWTVerifier struct {
+	algorithm string
+	keys      *KeyRing
+	options   makerOptions
+}
+
+// JWTMaker is a JSON Web Token maker
+type JWTMaker struct {
+	*JWTVerifier
+	signingKey interface{}
+	keyID      string
+}
+
+// NewJWTMaker creates a JWT maker for the given algorithm. The signing key
+// must be a []byte of at least 32 bytes for HS256, an *rsa.PrivateKey for
+// RS256 or an ed25519.PrivateKey for EdDSA. Previous keys can be passed with
+// WithRetiredKeys for HS256 and WithRetiredPublicKeys otherwise.
+func NewJWTMaker(algorithm string, signingKey interface{}, opts ...MakerOption) (Maker, error) {
+	var verifyKey interface{}
+
+	switch algorithm {
+	case AlgorithmHS256:
+		verifyKey = signingKey
+	case AlgorithmRS256:
+		key, ok := signingKey.(*rsa.PrivateKey)
+		if !ok {
+			return nil, fmt.Errorf("invalid key type for %s: expected *rsa.PrivateKey", algorithm)
+		}
+		verifyKey = &key.PublicKey
+	case AlgorithmEdDSA:
+		key, ok := signingKey.(ed25519.PrivateKey)
+		if !ok || len(key) != ed25519.PrivateKeySize {
+			return nil, fmt.Errorf("invalid key type for %s: expected ed25519.PrivateKey", algorithm)
+		}
+		verifyKey = key.Public()
+	default:
+		return nil, fmt.Errorf("unsupported JWT algorithm %q", algorithm)
+	}
+
+	verifier, err := newJWTVerifier(algorithm, verifyKey, opts)
+	if err != nil {
+		return nil, err
+	}
+	maker := &JWTMaker{
+		JWTVerifier: verifier,
+		signingKey:  signingKey,
+		keyID:       verifier.keys.ActiveKeyID(),
+	}
+	return maker, nil
+}
+
+// NewJWTVerifier creates a verifier for the given algorithm. The key must be
+// a []byte for HS256, an *rsa.PublicKey for RS256 or an ed25519.PublicKey for
+// EdDSA.
+func NewJWTVerifier(algorithm string, verifyKey interface{}, opts ...MakerOption) (Verifier, error) {
+	return newJWTVerifier(algorithm, verifyKey, opts)
+}
+
+func newJWTVerifier(algorithm string, verifyKey interface{}, opts []MakerOption) (*JWTVerifier, error) {
+	if !isJWTAlgorithm(algorithm) {
+		return nil, fmt.Errorf("unsupported JWT algorithm %q", algorithm)
+	}
+
+	options := newMakerOptions(opts)
+	keys := newKeyRing(func(key interface{}) error {
+		return validateJWTKey(algorithm, key)
+	})
+
+	var activeID string
+	var err error
+	if algorithm == AlgorithmHS256 {
+		for _, key := range options.retiredKeys {
+			if _, err := addSymmetricKey(keys, []byte(key)); err != nil {
+				return nil, err
+			}
+		}
+		secret, ok := verifyKey.([]byte)
+		if !ok {
+			return nil, fmt.Errorf("invalid key type for %s: expected []byte", algorithm)
+		}
+		activeID, err = addSymmetricKey(keys, secret)
+	} else {
+		for _, key := range options.retiredPublicKeys {
+			if _, err := addPublicKey(keys, key); err != nil {
+				return nil, err
+			}
+		}
+		if err := validateJWTKey(algorithm, verifyKey); err != nil {
+			return nil, err
+		}
+		activeID, err = addPublicKey(keys, verifyKey)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := keys.ActivateKey(activeID); err != nil {
+		return nil, err
+	}
+
+	return &JWTVerifier{
+		algorithm: algorithm,
+		keys:      keys,
+		options:   options,
+	}, nil
+}
+
+func isJWTAlgorithm(algorithm string) bool {
+	return algorithm == AlgorithmHS256 || algorithm == AlgorithmRS256 || algorithm == AlgorithmEdDSA
+}
+
+// validateJWTKey checks that key is a verification key for algorithm
+func validateJWTKey(algorithm string, verifyKey interface{}) error {
+	switch algorithm {
+	case AlgorithmHS256:
+		key, ok := verifyKey.([]byte)
+		if !ok {
+			return fmt.Errorf("invalid key type for %s: expected []byte", algorithm)
+		}
+		if len(key) < minHMACKeySize {
+			return fmt.Errorf("invalid key size: must be at least %d bytes", minHMACKeySize)
+		}
+	case AlgorithmRS256:
+		key, ok := verifyKey.(*rsa.PublicKey)
+		if !ok {
+			return fmt.Errorf("invalid key type for %s: expected *rsa.PublicKey", algorithm)
+		}
+		if key.N.BitLen() < 2048 {
+			return errors.New("invalid key size: RSA keys must be at least 2048 bits")
+		}
+	case AlgorithmEdDSA:
+		key, ok := verifyKey.(ed25519.PublicKey)
+		if !ok || len(key) != ed25519.PublicKeySize {
+			return fmt.Errorf("invalid key type for %s: expected ed25519.PublicKey", algorithm)
+		}
+	default:
+		return fmt.Errorf("unsupported JWT algorithm %q", algorithm)
+	}
+	return nil
+}
+
+func (maker *JWTMaker) CreateToken(userID pgtype.UUID, email string, duration time.Duration, opts ...PayloadOption) (string, error) {
+	payload, err := newPayload(maker.options.clock.Now(), userID, email, duration, opts...)
+	if err != nil {
+		return "", err
+	}
+
+	header, err := json.Marshal(jwtHeader{Algorithm: maker.algorithm, Type: "JWT", KeyID: maker.keyID})
+	if err != nil {
+		return "", err
+	}
+	claimsJSON, err := marshalJWTClaims(payload)
+	if err != nil {
+		return "", err
+	}
+
+	signingInput := encodeSegment(header) + "." + encodeSegment(claimsJSON)
+	signature, err := maker.sign([]byte(signingInput))
+	if err != nil {
+		return "", err
+	}
+	return signingInput + "." + encodeSegment(signature), nil
+}
+
+func (verifier *JWTVerifier) VerifyToken(token string, opts ...VerifyOption) (*Payload, error) {
+	payload, err := verifyJWT(token, func(header jwtHeader) (interface{}, error) {
+		// a token claiming any other algorithm, including "none", cannot
+		// carry a signature made with our key
+		if header.Algorithm != verifier.algorithm {
+			return nil, ErrInvalidSignature
+		}
+		return verifier.keys.verificationKey(header.KeyID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = payload.Validate(verifier.options.verifyOptions(opts)...)
+	if err != nil {
+		return nil, err
+	}
+	return payload, nil
+}
+
+// PublicKeySet returns the active and retired public keys of the verifier.
+// HMAC secrets are never published, so the set is empty for HS256.
+func (verifier *JWTVerifier) PublicKeySet() JSONWebKeySet {
+	if verifier.algorithm == AlgorithmHS256 {
+		return JSONWebKeySet{Keys: []JSONWebKey{}}
+	}
+	return publicKeySet(verifier.keys, verifier.algorithm)
+}
+
+// verifyJWT checks the signature of token with the key returned by keyFor
+// and decodes its claims. keyFor must only return a key that is meant to be
+// used with the algorithm of the header.
+func verifyJWT(token string, keyFor func(header jwtHeader) (interface{}, error)) (*Payload, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, ErrMalformedToken
+	}
+
+	var header jwtHeader
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, ErrMalformedToken
+	}
+	key, err := keyFor(header)
+	if err != nil {
+		return nil, err
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, ErrMalformedToken
+	}
+	if err := verifyJWTSignature(key, []byte(parts[0]+"."+parts[1]), signature); err != nil {
+		return nil, ErrInvalidSignature
+	}
+
+	payload, err := unmarshalJWTClaims(parts[1])
+	if err != nil {
+		return nil, ErrMalformedToken
+	}
+	return payload, nil
+}
+
+func (maker *JWTMaker) sign(signingInput []byte) ([]byte, error) {
+	switch key := maker.signingKey.(type) {
+	case []byte:
+		mac := hmac.New(sha256.New, key)
+		mac.Write(signingInput)
+		return mac.Sum(nil), nil
+	case *rsa.PrivateKey:
+		digest := sha256.Sum256(signingInput)
+		return rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	case ed25519.PrivateKey:
+		return ed25519.Sign(key, signingInput), nil
+	}
+	return nil, errors.New("unsupported signing key")
+}
+
+func verifyJWTSignature(verifyKey interface{}, signingInput, signature []byte) error {
+	switch key := verifyKey.(type) {
+	case []byte:
+		mac := hmac.New(sha256.New, key)
+		mac.Write(signingInput)
+		if !hmac.Equal(signature, mac.Sum(nil)) {
+			return ErrInvalidSignature
+		}
+		return nil
+	case *rsa.PublicKey:
+		digest := sha256.Sum256(signingInput)
+		return rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature)
+	case ed25519.PublicKey:
+		if !ed25519.Verify(key, signingInput, signature) {
+			return ErrInvalidSignature
+		}
+		return nil
+	}
+	return errors.New("unsupported verification key")
+}
+
+func marshalJWTClaims(payload *Payload) ([]byte, error) {
+	claims := jwtClaims{
+		ID:        payload.ID.String(),
+		Subject:   uuid.UUID(payload.UserID.Bytes).String(),
+		Email:     payload.Email,
+		Purpose:   string(payload.Purpose),
+		Issuer:    payload.Issuer,
+		Audience:  payload.Audience,
+		Roles:     payload.Roles,
+		Scope:     strings.Join(payload.Scopes, " "),
+		IssuedAt:  payload.IssuedAt.Unix(),
+		ExpiresAt: payload.ExpiredAt.Unix(),
+	}
+	if payload.SessionID != uuid.Nil {
+		claims.SessionID = payload.SessionID.String()
+	}
+	if payload.OrgID != uuid.Nil {
+		claims.OrgID = payload.OrgID.String()
+	}
+	if !payload.NotBefore.IsZero() {
+		claims.NotBefore = payload.NotBefore.Unix()
+	}
+
+	if len(payload.Extra) == 0 {
+		return json.Marshal(claims)
+	}
+
+	// flatten the extra claims into the claims set
+	registered, err := json.Marshal(claims)
+	if err != nil {
+		return nil, err
+	}
+	all := make(map[string]interface{}, len(payload.Extra))
+	if err := json.Unmarshal(registered, &all); err != nil {
+		return nil, err
+	}
+	for key, value := range payload.Extra {
+		if reservedJWTClaims[key] {
+			return nil, fmt.Errorf("%w: %q", ErrReservedClaimInUse, key)
+		}
+		all[key] = value
+	}
+	return json.Marshal(all)
+}
+
+func unmarshalJWTClaims(segment string) (*Payload, error) {
+	var claims jwtClaims
+	if err := decodeSegment(segment, &claims); err != nil {
+		return nil, err
+	}
+	var all map[string]interface{}
+	if err := decodeSegment(segment, &all); err != nil {
+		return nil, err
+	}
+
+	id, err := uuid.Parse(claims.ID)
+	if err != nil {
+		return nil, err
+	}
+	subject, err := uuid.Parse(claims.Subject)
+	if err != nil {
+		return nil, err
+	}
+	payload := &Payload{
+		ID:        id,
+		UserID:    pgtype.UUID{Bytes: subject, Valid: true},
+		Email:     claims.Email,
+		Purpose:   Purpose(claims.Purpose),
+		Issuer:    claims.Issuer,
+		Audience:  claims.Audience,
+		Roles:     claims.Roles,
+		Scopes:    strings.Fields(claims.Scope),
+		IssuedAt:  time.Unix(claims.IssuedAt, 0),
+		ExpiredAt: time.Unix(claims.ExpiresAt, 0),
+	}
+	if claims.SessionID != "" {
+		payload.SessionID, err = uuid.Parse(claims.SessionID)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if claims.OrgID != "" {
+		payload.OrgID, err = uuid.Parse(claims.OrgID)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if claims.NotBefore != 0 {
+		payload.NotBefore = time.Unix(claims.NotBefore, 0)
+	}
+
+	for key, value := range all {
+		if reservedJWTClaims[key] {
+			continue
+		}
+		if payload.Extra == nil {
+			payload.Extra = make(map[string]interface{})
+		}
+		payload.Extra[key] = value
+	}
+	return payload, nil
+}
+
+func encodeSegment(data []byte) string {
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeSegment(segment string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
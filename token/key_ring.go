@@ -0,0 +1,165 @@
+package token
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+var (
+	ErrKeyNotFound     = errors.New("key not found")
+	ErrRemoveActiveKey = errors.New("cannot remove the active key")
+)
+
+// KeyRotator is implemented by makers whose keys can be rotated at runtime
+type KeyRotator interface {
+	KeyRing() *KeyRing
+}
+
+// KeyRing holds the keys of a maker, identified by key ID (kid). Exactly one
+// key is active and used to create tokens; every other key in the ring is
+// retired and only accepted when verifying tokens. It is safe for concurrent
+// use, so keys can be rotated while the server is running:
+//
+//	ring.AddKey("2025-02", newKey)  // start accepting tokens signed with it
+//	ring.ActivateKey("2025-02")     // sign new tokens with it, retire the old key
+//	ring.RemoveKey("2025-01")       // once old tokens have expired
+type KeyRing struct {
+	mu       sync.RWMutex
+	activeID string
+	keys     map[string]interface{}
+	validate func(key interface{}) error
+}
+
+func newKeyRing(validate func(key interface{}) error) *KeyRing {
+	return &KeyRing{
+		keys:     make(map[string]interface{}),
+		validate: validate,
+	}
+}
+
+// AddKey adds a retired key to the ring. It is accepted for verification
+// but not used to create tokens until it is activated.
+func (ring *KeyRing) AddKey(id string, key interface{}) error {
+	if id == "" {
+		return errors.New("key ID must not be empty")
+	}
+	if err := ring.validate(key); err != nil {
+		return err
+	}
+
+	ring.mu.Lock()
+	defer ring.mu.Unlock()
+
+	if _, exists := ring.keys[id]; exists {
+		return fmt.Errorf("key %q already exists", id)
+	}
+	ring.keys[id] = key
+	return nil
+}
+
+// ActivateKey makes the key the one used for new tokens. The previously
+// active key stays in the ring as a retired key.
+func (ring *KeyRing) ActivateKey(id string) error {
+	ring.mu.Lock()
+	defer ring.mu.Unlock()
+
+	if _, exists := ring.keys[id]; !exists {
+		return ErrKeyNotFound
+	}
+	ring.activeID = id
+	return nil
+}
+
+// RemoveKey drops a retired key, after which tokens created with it are no
+// longer accepted
+func (ring *KeyRing) RemoveKey(id string) error {
+	ring.mu.Lock()
+	defer ring.mu.Unlock()
+
+	if id == ring.activeID {
+		return ErrRemoveActiveKey
+	}
+	if _, exists := ring.keys[id]; !exists {
+		return ErrKeyNotFound
+	}
+	delete(ring.keys, id)
+	return nil
+}
+
+// ActiveKeyID returns the ID of the key used to create tokens
+func (ring *KeyRing) ActiveKeyID() string {
+	ring.mu.RLock()
+	defer ring.mu.RUnlock()
+	return ring.activeID
+}
+
+// KeyIDs returns the IDs of all keys in the ring, sorted
+func (ring *KeyRing) KeyIDs() []string {
+	ring.mu.RLock()
+	defer ring.mu.RUnlock()
+
+	ids := make([]string, 0, len(ring.keys))
+	for id := range ring.keys {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+func (ring *KeyRing) activeKey() (string, interface{}, error) {
+	ring.mu.RLock()
+	defer ring.mu.RUnlock()
+
+	key, exists := ring.keys[ring.activeID]
+	if !exists {
+		return "", nil, ErrKeyNotFound
+	}
+	return ring.activeID, key, nil
+}
+
+func (ring *KeyRing) key(id string) (interface{}, error) {
+	ring.mu.RLock()
+	defer ring.mu.RUnlock()
+
+	key, exists := ring.keys[id]
+	if !exists {
+		return nil, ErrKeyNotFound
+	}
+	return key, nil
+}
+
+// verificationKey returns the key named by a token. Tokens created before
+// key IDs were introduced carry none and use the active key. A key that is
+// not in the ring cannot have produced a valid token.
+func (ring *KeyRing) verificationKey(id string) (interface{}, error) {
+	if id == "" {
+		_, key, err := ring.activeKey()
+		return key, err
+	}
+
+	key, err := ring.key(id)
+	if err != nil {
+		return nil, ErrInvalidSignature
+	}
+	return key, nil
+}
+
+// KeyID derives a stable key ID from key material, for keys that are
+// configured without an explicit ID
+func KeyID(key []byte) string {
+	sum := sha256.Sum256(key)
+	return hex.EncodeToString(sum[:8])
+}
+
+// addSymmetricKey adds a symmetric key to ring under its derived key ID
+func addSymmetricKey(ring *KeyRing, key []byte) (string, error) {
+	id := KeyID(key)
+	if _, err := ring.key(id); err == nil {
+		return id, nil
+	}
+	return id, ring.AddKey(id, key)
+}
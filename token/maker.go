@@ -6,7 +6,20 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+// Issuer creates tokens. Only the auth server needs an Issuer.
+type Issuer interface {
+	CreateToken(userID pgtype.UUID, email string, duration time.Duration, opts ...PayloadOption) (string, error)
+}
+
+// Verifier checks tokens and returns their payload. Services that only
+// consume tokens should depend on a Verifier, which for asymmetric makers can
+// be built from the public key alone.
+type Verifier interface {
+	VerifyToken(token string, opts ...VerifyOption) (*Payload, error)
+}
+
+// Maker both issues and verifies tokens
 type Maker interface {
-	CreateToken(userID pgtype.UUID, email string, duration time.Duration) (string, error)
-	VerifyToken(token string) (*Payload, error)
+	Issuer
+	Verifier
 }
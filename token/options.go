@@ -0,0 +1,65 @@
+package token
+
+import (
+	"crypto"
+	"time"
+)
+
+// MakerOption configures a maker or verifier
+type MakerOption func(*makerOptions)
+
+type makerOptions struct {
+	clock       Clock
+	leeway      time.Duration
+	retiredKeys []string
+
+	retiredPublicKeys []crypto.PublicKey
+}
+
+func newMakerOptions(opts []MakerOption) makerOptions {
+	o := makerOptions{clock: SystemClock}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// WithClock sets the clock used to stamp and check token times
+func WithClock(clock Clock) MakerOption {
+	return func(o *makerOptions) {
+		o.clock = clock
+	}
+}
+
+// WithLeeway tolerates clocks of issuer and verifier being up to leeway
+// apart when checking expiry and not-before. AllowClockSkew overrides it for
+// a single verification.
+func WithLeeway(leeway time.Duration) MakerOption {
+	return func(o *makerOptions) {
+		o.leeway = leeway
+	}
+}
+
+// WithRetiredKeys adds previous symmetric keys to a PASETO maker. Tokens
+// created with them are still accepted, which allows rotating the key
+// without logging out every user.
+func WithRetiredKeys(keys ...string) MakerOption {
+	return func(o *makerOptions) {
+		o.retiredKeys = append(o.retiredKeys, keys...)
+	}
+}
+
+// WithRetiredPublicKeys adds previous public keys to an asymmetric maker or
+// verifier. Tokens signed with their private keys are still accepted and the
+// keys are published in the maker's key set.
+func WithRetiredPublicKeys(keys ...crypto.PublicKey) MakerOption {
+	return func(o *makerOptions) {
+		o.retiredPublicKeys = append(o.retiredPublicKeys, keys...)
+	}
+}
+
+// verifyOptions returns the options every verification of the maker starts
+// with, followed by the caller's own options
+func (o makerOptions) verifyOptions(opts []VerifyOption) []VerifyOption {
+	return append([]VerifyOption{VerifyWithClock(o.clock), AllowClockSkew(o.leeway)}, opts...)
+}
@@ -1,6 +1,7 @@
 package token
 
 import (
+	"errors"
 	"fmt"
 	"time"
 
@@ -9,41 +10,128 @@ import (
 	"github.com/o1egl/paseto"
 )
 
+// footer is written in clear text into every token so VerifyToken knows
+// which key of the ring to use
+type footer struct {
+	KeyID string `json:"kid"`
+}
+
 type PasetoMaker struct {
-	paseto       *paseto.V2
-	symmetricKey []byte
+	paseto  *paseto.V2
+	keyRing *KeyRing
+	options makerOptions
 }
 
-func NewPasetoMaker(symmetricKey string) (Maker, error) {
-	if len(symmetricKey) != chacha20poly1305.KeySize {
-		return nil, fmt.Errorf("invalid key size: must be exactly %d characters", chacha20poly1305.KeySize)
+// NewPasetoMaker creates a v2.local maker that encrypts tokens with
+// symmetricKey. Previous keys can be passed with WithRetiredKeys.
+func NewPasetoMaker(symmetricKey string, opts ...MakerOption) (Maker, error) {
+	keyRing := NewPasetoKeyRing()
+	for _, key := range newMakerOptions(opts).retiredKeys {
+		if _, err := addSymmetricKey(keyRing, []byte(key)); err != nil {
+			return nil, err
+		}
+	}
+
+	activeID, err := addSymmetricKey(keyRing, []byte(symmetricKey))
+	if err != nil {
+		return nil, err
+	}
+	if err := keyRing.ActivateKey(activeID); err != nil {
+		return nil, err
+	}
+
+	return NewPasetoMakerWithKeyRing(keyRing, opts...)
+}
+
+// NewPasetoKeyRing creates an empty key ring that accepts
+// 32 byte symmetric keys
+func NewPasetoKeyRing() *KeyRing {
+	return newKeyRing(func(key interface{}) error {
+		symmetricKey, ok := key.([]byte)
+		if !ok || len(symmetricKey) != chacha20poly1305.KeySize {
+			return fmt.Errorf("invalid key size: must be exactly %d characters", chacha20poly1305.KeySize)
+		}
+		return nil
+	})
+}
+
+// NewPasetoMakerWithKeyRing creates a v2.local maker backed by keyRing,
+// which must have an active key
+func NewPasetoMakerWithKeyRing(keyRing *KeyRing, opts ...MakerOption) (Maker, error) {
+	if _, _, err := keyRing.activeKey(); err != nil {
+		return nil, errors.New("key ring has no active key")
 	}
 
 	maker := &PasetoMaker{
-		paseto:       paseto.NewV2(),
-		symmetricKey: []byte(symmetricKey),
+		paseto:  paseto.NewV2(),
+		keyRing: keyRing,
+		options: newMakerOptions(opts),
 	}
 	return maker, nil
 }
 
-func (maker *PasetoMaker) CreateToken(id pgtype.UUID, email string, duration time.Duration) (string, error) {
-	payload, err := NewPayload(id, email, duration)
+// KeyRing returns the keys of the maker so they can be rotated at runtime
+func (maker *PasetoMaker) KeyRing() *KeyRing {
+	return maker.keyRing
+}
+
+func (maker *PasetoMaker) CreateToken(id pgtype.UUID, email string, duration time.Duration, opts ...PayloadOption) (string, error) {
+	payload, err := newPayload(maker.options.clock.Now(), id, email, duration, opts...)
 	if err != nil {
 		return "", err
 	}
 
-	return maker.paseto.Encrypt(maker.symmetricKey, payload, nil)
+	keyID, key, err := maker.keyRing.activeKey()
+	if err != nil {
+		return "", err
+	}
+	return maker.paseto.Encrypt(key.([]byte), payload, footer{KeyID: keyID})
 }
 
-func (maker *PasetoMaker) VerifyToken(token string) (*Payload, error) {
-	payload := &Payload{}
-	err := maker.paseto.Decrypt(token, maker.symmetricKey, payload, nil)
+func (maker *PasetoMaker) VerifyToken(token string, opts ...VerifyOption) (*Payload, error) {
+	key, err := maker.verificationKey(token)
 	if err != nil {
 		return nil, err
 	}
-	err = payload.Valid()
+
+	payload := &Payload{}
+	err = maker.paseto.Decrypt(token, key, payload, nil)
+	if err != nil {
+		return nil, pasetoError(err)
+	}
+	err = payload.Validate(maker.options.verifyOptions(opts)...)
 	if err != nil {
 		return nil, err
 	}
 	return payload, nil
 }
+
+// verificationKey picks the key named by the token footer
+func (maker *PasetoMaker) verificationKey(token string) ([]byte, error) {
+	keyID, err := footerKeyID(token)
+	if err != nil {
+		return nil, err
+	}
+	key, err := maker.keyRing.verificationKey(keyID)
+	if err != nil {
+		return nil, err
+	}
+	return key.([]byte), nil
+}
+
+// footerKeyID reads the key ID from the clear text footer of a token
+func footerKeyID(token string) (string, error) {
+	var tokenFooter footer
+	if err := paseto.ParseFooter(token, &tokenFooter); err != nil {
+		return "", ErrMalformedToken
+	}
+	return tokenFooter.KeyID, nil
+}
+
+// pasetoError maps errors of the paseto library to verification errors
+func pasetoError(err error) error {
+	if errors.Is(err, paseto.ErrInvalidTokenAuth) || errors.Is(err, paseto.ErrInvalidSignature) {
+		return ErrInvalidSignature
+	}
+	return ErrMalformedToken
+}
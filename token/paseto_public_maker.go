@@ -0,0 +1,154 @@
+package token
+
+import (
+	"crypto/ed25519"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/o1egl/paseto"
+)
+
+// Supported PASETO versions for public (signed) tokens
+const (
+	PasetoVersion2 = "v2"
+	PasetoVersion4 = "v4"
+)
+
+// PasetoPublicVerifier verifies v2.public or v4.public tokens with Ed25519
+// public keys. It cannot create tokens, so it is safe to hand out to every
+// service that only needs to check them.
+type PasetoPublicVerifier struct {
+	version string
+	paseto  *paseto.V2
+	keys    *KeyRing
+	options makerOptions
+}
+
+// PasetoPublicMaker signs tokens with an Ed25519 private key that only the
+// auth server holds
+type PasetoPublicMaker struct {
+	*PasetoPublicVerifier
+	privateKey ed25519.PrivateKey
+	keyID      string
+}
+
+// NewPasetoPublicMaker creates a maker that signs with privateKey. Public
+// keys of previous private keys can be passed with WithRetiredPublicKeys.
+func NewPasetoPublicMaker(version string, privateKey ed25519.PrivateKey, opts ...MakerOption) (Maker, error) {
+	if len(privateKey) != ed25519.PrivateKeySize {
+		return nil, fmt.Errorf("invalid key size: must be exactly %d bytes", ed25519.PrivateKeySize)
+	}
+
+	verifier, err := newPasetoPublicVerifier(version, privateKey.Public().(ed25519.PublicKey), opts)
+	if err != nil {
+		return nil, err
+	}
+	maker := &PasetoPublicMaker{
+		PasetoPublicVerifier: verifier,
+		privateKey:           privateKey,
+		keyID:                verifier.keys.ActiveKeyID(),
+	}
+	return maker, nil
+}
+
+func NewPasetoPublicVerifier(version string, publicKey ed25519.PublicKey, opts ...MakerOption) (Verifier, error) {
+	return newPasetoPublicVerifier(version, publicKey, opts)
+}
+
+func newPasetoPublicVerifier(version string, publicKey ed25519.PublicKey, opts []MakerOption) (*PasetoPublicVerifier, error) {
+	if version != PasetoVersion2 && version != PasetoVersion4 {
+		return nil, fmt.Errorf("unsupported PASETO version %q", version)
+	}
+
+	options := newMakerOptions(opts)
+	keys := newKeyRing(func(key interface{}) error {
+		publicKey, ok := key.(ed25519.PublicKey)
+		if !ok || len(publicKey) != ed25519.PublicKeySize {
+			return fmt.Errorf("invalid key size: must be exactly %d bytes", ed25519.PublicKeySize)
+		}
+		return nil
+	})
+	for _, key := range options.retiredPublicKeys {
+		if _, err := addPublicKey(keys, key); err != nil {
+			return nil, err
+		}
+	}
+	activeID, err := addPublicKey(keys, publicKey)
+	if err != nil {
+		return nil, err
+	}
+	if err := keys.ActivateKey(activeID); err != nil {
+		return nil, err
+	}
+
+	verifier := &PasetoPublicVerifier{
+		version: version,
+		paseto:  paseto.NewV2(),
+		keys:    keys,
+		options: options,
+	}
+	return verifier, nil
+}
+
+func (maker *PasetoPublicMaker) CreateToken(userID pgtype.UUID, email string, duration time.Duration, opts ...PayloadOption) (string, error) {
+	payload, err := newPayload(maker.options.clock.Now(), userID, email, duration, opts...)
+	if err != nil {
+		return "", err
+	}
+
+	tokenFooter := footer{KeyID: maker.keyID}
+	if maker.version == PasetoVersion4 {
+		footerBytes, err := json.Marshal(tokenFooter)
+		if err != nil {
+			return "", err
+		}
+		return signV4Public(maker.privateKey, payload, footerBytes)
+	}
+	return maker.paseto.Sign(maker.privateKey, payload, tokenFooter)
+}
+
+func (verifier *PasetoPublicVerifier) VerifyToken(token string, opts ...VerifyOption) (*Payload, error) {
+	if !strings.HasPrefix(token, verifier.version+".public.") {
+		return nil, ErrMalformedToken
+	}
+	keyID, err := footerKeyID(token)
+	if err != nil {
+		return nil, err
+	}
+	key, err := verifier.keys.verificationKey(keyID)
+	if err != nil {
+		return nil, err
+	}
+
+	payload := &Payload{}
+	err = verifyPasetoPublic(verifier.version, token, key.(ed25519.PublicKey), payload)
+	if err != nil {
+		return nil, err
+	}
+
+	err = payload.Validate(verifier.options.verifyOptions(opts)...)
+	if err != nil {
+		return nil, err
+	}
+	return payload, nil
+}
+
+// PublicKeySet returns the active and retired public keys of the verifier
+func (verifier *PasetoPublicVerifier) PublicKeySet() JSONWebKeySet {
+	return publicKeySet(verifier.keys, "")
+}
+
+// verifyPasetoPublic checks the signature of a v2.public or v4.public token
+// and decodes its payload
+func verifyPasetoPublic(version, token string, publicKey ed25519.PublicKey, payload *Payload) error {
+	if version == PasetoVersion4 {
+		return verifyV4Public(token, publicKey, payload, nil)
+	}
+	if err := paseto.NewV2().Verify(token, publicKey, payload, nil); err != nil {
+		return pasetoError(err)
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package token
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"strings"
+)
+
+// The o1egl/paseto library only implements v1 and v2, so v4.public is
+// implemented here following the PASETO specification. v4.public is
+// Ed25519 over the pre-authentication encoding of header, message, footer
+// and implicit assertion.
+
+const headerV4Public = "v4.public."
+
+func signV4Public(privateKey ed25519.PrivateKey, payload interface{}, footer []byte) (string, error) {
+	message, err := json.Marshal(payload)
+	if err != nil {
+		return "", err
+	}
+
+	signature := ed25519.Sign(privateKey, preAuthEncode([]byte(headerV4Public), message, footer, nil))
+
+	token := headerV4Public + base64.RawURLEncoding.EncodeToString(append(message, signature...))
+	if len(footer) > 0 {
+		token += "." + base64.RawURLEncoding.EncodeToString(footer)
+	}
+	return token, nil
+}
+
+func verifyV4Public(token string, publicKey ed25519.PublicKey, payload interface{}, footer *[]byte) error {
+	if !strings.HasPrefix(token, headerV4Public) {
+		return ErrMalformedToken
+	}
+
+	parts := strings.Split(token[len(headerV4Public):], ".")
+	if len(parts) > 2 {
+		return ErrMalformedToken
+	}
+
+	body, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil || len(body) < ed25519.SignatureSize {
+		return ErrMalformedToken
+	}
+
+	var footerBytes []byte
+	if len(parts) == 2 {
+		footerBytes, err = base64.RawURLEncoding.DecodeString(parts[1])
+		if err != nil {
+			return ErrMalformedToken
+		}
+	}
+
+	message := body[:len(body)-ed25519.SignatureSize]
+	signature := body[len(body)-ed25519.SignatureSize:]
+	if !ed25519.Verify(publicKey, preAuthEncode([]byte(headerV4Public), message, footerBytes, nil), signature) {
+		return ErrInvalidSignature
+	}
+
+	if footer != nil {
+		*footer = footerBytes
+	}
+	if err := json.Unmarshal(message, payload); err != nil {
+		return ErrMalformedToken
+	}
+	return nil
+}
+
+// preAuthEncode implements PAE from the PASETO specification
+func preAuthEncode(pieces ...[]byte) []byte {
+	var buf bytes.Buffer
+	length := make([]byte, 8)
+
+	binary.LittleEndian.PutUint64(length, uint64(len(pieces)))
+	buf.Write(length)
+	for _, piece := range pieces {
+		binary.LittleEndian.PutUint64(length, uint64(len(piece)))
+		buf.Write(length)
+		buf.Write(piece)
+	}
+	return buf.Bytes()
+}
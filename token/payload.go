@@ -2,44 +2,252 @@
 package token
 
 import (
-	"errors"
+	"context"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
-var (
-	ErrInvalidToken = errors.New("token is invalid")
-	ErrExpiredToken = errors.New("token is expired")
-)
-
 // Payload contain payload data of token
 type Payload struct {
-	ID        uuid.UUID   `json:"id"`
-	UserID    pgtype.UUID `json:"user_id"`
-	Email     string      `json:"email"`
-	IssuedAt  time.Time   `json:"issued_at"`
-	ExpiredAt time.Time   `json:"expired_at"`
+	ID        uuid.UUID              `json:"id"`
+	UserID    pgtype.UUID            `json:"user_id"`
+	Email     string                 `json:"email"`
+	SessionID uuid.UUID              `json:"session_id"`
+	OrgID     uuid.UUID              `json:"org_id"`
+	Purpose   Purpose                `json:"purpose"`
+	Issuer    string                 `json:"issuer,omitempty"`
+	Audience  []string               `json:"audience,omitempty"`
+	Roles     []string               `json:"roles,omitempty"`
+	Scopes    []string               `json:"scopes,omitempty"`
+	Extra     map[string]interface{} `json:"extra,omitempty"`
+	IssuedAt  time.Time              `json:"issued_at"`
+	NotBefore time.Time              `json:"not_before"`
+	ExpiredAt time.Time              `json:"expired_at"`
+}
+
+// PayloadOption sets optional claims of a new payload
+type PayloadOption func(*Payload)
+
+// WithSessionID ties the token to the refresh session it was issued for,
+// so ending the session can also revoke the token
+func WithSessionID(sessionID uuid.UUID) PayloadOption {
+	return func(payload *Payload) {
+		payload.SessionID = sessionID
+	}
+}
+
+// WithOrgID sets the organization the token holder is acting for
+func WithOrgID(orgID uuid.UUID) PayloadOption {
+	return func(payload *Payload) {
+		payload.OrgID = orgID
+	}
+}
+
+// WithIssuer sets the party that issued the token
+func WithIssuer(issuer string) PayloadOption {
+	return func(payload *Payload) {
+		payload.Issuer = issuer
+	}
+}
+
+// WithAudience sets the recipients the token is intended for
+func WithAudience(audience ...string) PayloadOption {
+	return func(payload *Payload) {
+		payload.Audience = audience
+	}
+}
+
+// WithNotBefore sets the time before which the token must not be accepted
+func WithNotBefore(notBefore time.Time) PayloadOption {
+	return func(payload *Payload) {
+		payload.NotBefore = notBefore
+	}
+}
+
+// WithIssuedAt records the token as issued at issuedAt instead of now, such
+// as the RevocationCutoff of a revocation the token must survive. Expiry
+// and not-before stay relative to now.
+func WithIssuedAt(issuedAt time.Time) PayloadOption {
+	return func(payload *Payload) {
+		payload.IssuedAt = issuedAt
+	}
+}
+
+// WithRoles sets the roles granted to the token holder
+func WithRoles(roles ...string) PayloadOption {
+	return func(payload *Payload) {
+		payload.Roles = roles
+	}
 }
 
-func NewPayload(userID pgtype.UUID, email string, duration time.Duration) (*Payload, error) {
+// WithScopes sets the scopes granted to the token holder
+func WithScopes(scopes ...string) PayloadOption {
+	return func(payload *Payload) {
+		payload.Scopes = scopes
+	}
+}
+
+// WithExtraClaims adds application specific claims to the token
+func WithExtraClaims(claims map[string]interface{}) PayloadOption {
+	return func(payload *Payload) {
+		if payload.Extra == nil {
+			payload.Extra = make(map[string]interface{}, len(claims))
+		}
+		for key, value := range claims {
+			payload.Extra[key] = value
+		}
+	}
+}
+
+func NewPayload(userID pgtype.UUID, email string, duration time.Duration, opts ...PayloadOption) (*Payload, error) {
+	return newPayload(time.Now(), userID, email, duration, opts...)
+}
+
+func newPayload(now time.Time, userID pgtype.UUID, email string, duration time.Duration, opts ...PayloadOption) (*Payload, error) {
 	id, err := uuid.NewRandom()
 	if err != nil {
 		return nil, err
 	}
-	return &Payload{
+	payload := &Payload{
 		ID:        id,
 		UserID:    userID,
 		Email:     email,
-		IssuedAt:  time.Now(),
-		ExpiredAt: time.Now().Add(duration),
-	}, nil
+		Purpose:   PurposeAccess,
+		IssuedAt:  now,
+		NotBefore: now,
+		ExpiredAt: now.Add(duration),
+	}
+	for _, opt := range opts {
+		opt(payload)
+	}
+	return payload, nil
+}
+
+// VerifyOption adds a check performed when verifying a token
+type VerifyOption func(*verifyOptions)
+
+type verifyOptions struct {
+	ctx       context.Context
+	clock     Clock
+	issuer    string
+	audience  string
+	scopes    []string
+	purposes  []Purpose
+	clockSkew time.Duration
+}
+
+// VerifyWithClock checks the time based claims against clock instead of the
+// system clock
+func VerifyWithClock(clock Clock) VerifyOption {
+	return func(o *verifyOptions) {
+		o.clock = clock
+	}
+}
+
+// VerifyWithContext bounds the work a verifier does for the token, such as
+// fetching the keys of a RemoteKeySet, by ctx. Makers holding their keys
+// ignore it.
+func VerifyWithContext(ctx context.Context) VerifyOption {
+	return func(o *verifyOptions) {
+		o.ctx = ctx
+	}
+}
+
+// verifyContext returns the context passed with VerifyWithContext, or the
+// background context
+func verifyContext(opts []VerifyOption) context.Context {
+	var o verifyOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.ctx == nil {
+		return context.Background()
+	}
+	return o.ctx
+}
+
+// ExpectIssuer rejects tokens not issued by issuer
+func ExpectIssuer(issuer string) VerifyOption {
+	return func(o *verifyOptions) {
+		o.issuer = issuer
+	}
+}
+
+// ExpectAudience rejects tokens whose audience does not include audience
+func ExpectAudience(audience string) VerifyOption {
+	return func(o *verifyOptions) {
+		o.audience = audience
+	}
+}
+
+// RequireScopes rejects tokens that were not granted every one of scopes
+func RequireScopes(scopes ...string) VerifyOption {
+	return func(o *verifyOptions) {
+		o.scopes = append(o.scopes, scopes...)
+	}
+}
+
+// AllowClockSkew tolerates clocks of issuer and verifier being up to skew
+// apart when checking expiry and not-before
+func AllowClockSkew(skew time.Duration) VerifyOption {
+	return func(o *verifyOptions) {
+		o.clockSkew = skew
+	}
 }
 
 func (payload *Payload) Valid() error {
-	if time.Now().After(payload.ExpiredAt) {
+	return payload.Validate()
+}
+
+// Validate checks the time based claims of the payload along with every
+// check requested by opts, returning a distinct error for each failure
+func (payload *Payload) Validate(opts ...VerifyOption) error {
+	o := verifyOptions{clock: SystemClock}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	now := o.clock.Now()
+	if now.After(payload.ExpiredAt.Add(o.clockSkew)) {
 		return ErrExpiredToken
 	}
+	if !payload.NotBefore.IsZero() && now.Before(payload.NotBefore.Add(-o.clockSkew)) {
+		return ErrTokenNotYetValid
+	}
+	if !o.expectsPurpose(payload.Purpose) {
+		return ErrInvalidPurpose
+	}
+	if o.issuer != "" && payload.Issuer != o.issuer {
+		return ErrInvalidIssuer
+	}
+	if o.audience != "" && !contains(payload.Audience, o.audience) {
+		return ErrInvalidAudience
+	}
+	for _, scope := range o.scopes {
+		if !contains(payload.Scopes, scope) {
+			return ErrInsufficientScope
+		}
+	}
 	return nil
 }
+
+// HasRole reports whether the token was granted role
+func (payload *Payload) HasRole(role string) bool {
+	return contains(payload.Roles, role)
+}
+
+// HasScope reports whether the token was granted scope
+func (payload *Payload) HasScope(scope string) bool {
+	return contains(payload.Scopes, scope)
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
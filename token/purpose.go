@@ -0,0 +1,59 @@
+package token
+
+// Purpose tells what a token may be used for. Verification always expects
+// a purpose, access by default, so a token minted for one flow cannot be
+// replayed against another.
+type Purpose string
+
+const (
+	// PurposeAccess tokens authenticate API requests
+	PurposeAccess Purpose = "access"
+	// PurposeEmailVerification tokens confirm that a user owns an email
+	PurposeEmailVerification Purpose = "email_verification"
+	// PurposePasswordReset tokens allow setting a new password once
+	PurposePasswordReset Purpose = "password_reset"
+	// PurposeEmailChange tokens confirm or cancel a change of email
+	PurposeEmailChange Purpose = "email_change"
+	// PurposeRestricted tokens authenticate only the few requests allowed
+	// to users who have not verified their email yet
+	PurposeRestricted Purpose = "restricted"
+)
+
+// WithPurpose sets what the token may be used for. Tokens are access
+// tokens unless another purpose is set.
+func WithPurpose(purpose Purpose) PayloadOption {
+	return func(payload *Payload) {
+		payload.Purpose = purpose
+	}
+}
+
+// ExpectPurpose rejects tokens minted for any purpose other than purposes.
+// Without it, only access tokens are accepted.
+func ExpectPurpose(purposes ...Purpose) VerifyOption {
+	return func(o *verifyOptions) {
+		o.purposes = purposes
+	}
+}
+
+// expectsPurpose tells whether a token minted for purpose passes the checks
+// of o
+func (o verifyOptions) expectsPurpose(purpose Purpose) bool {
+	if len(o.purposes) == 0 {
+		return purposeOrAccess(purpose) == PurposeAccess
+	}
+	for _, expected := range o.purposes {
+		if purposeOrAccess(purpose) == purposeOrAccess(expected) {
+			return true
+		}
+	}
+	return false
+}
+
+// purposeOrAccess treats an empty purpose as access, which is what tokens
+// created before purposes were introduced are
+func purposeOrAccess(purpose Purpose) Purpose {
+	if purpose == "" {
+		return PurposeAccess
+	}
+	return purpose
+}
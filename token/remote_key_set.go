@@ -0,0 +1,304 @@
+package token
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	defaultKeySetTTL             = 10 * time.Minute
+	defaultKeySetRefreshInterval = time.Minute
+	defaultKeySetTimeout         = 10 * time.Second
+)
+
+// RemoteKeySetOption configures a RemoteKeySet
+type RemoteKeySetOption func(*RemoteKeySet)
+
+// WithHTTPClient sets the client used to fetch the key set
+func WithHTTPClient(client *http.Client) RemoteKeySetOption {
+	return func(ks *RemoteKeySet) {
+		ks.client = client
+	}
+}
+
+// WithKeySetTTL sets how long fetched keys are cached when the response has
+// no Cache-Control max-age
+func WithKeySetTTL(ttl time.Duration) RemoteKeySetOption {
+	return func(ks *RemoteKeySet) {
+		ks.ttl = ttl
+	}
+}
+
+// WithKeySetRefreshInterval limits how often a token with an unknown key ID
+// makes the key set refetch before its cache expires
+func WithKeySetRefreshInterval(interval time.Duration) RemoteKeySetOption {
+	return func(ks *RemoteKeySet) {
+		ks.refreshInterval = interval
+	}
+}
+
+// WithVerifierOptions sets the clock and leeway used to validate tokens
+func WithVerifierOptions(opts ...MakerOption) RemoteKeySetOption {
+	return func(ks *RemoteKeySet) {
+		ks.options = newMakerOptions(opts)
+	}
+}
+
+// RemoteKeySet is a Verifier for services that do not hold any key. It
+// fetches the public keys published by the auth server at
+// /.well-known/jwks.json, caches them and verifies JWTs signed with RS256 or
+// EdDSA as well as v2.public and v4.public PASETO tokens.
+//
+// Example usage:
+//
+//	verifier := token.NewRemoteKeySet("https://auth.example.com/.well-known/jwks.json")
+//	app.Use(middleware.AuthMiddleware(verifier))
+type RemoteKeySet struct {
+	url             string
+	client          *http.Client
+	ttl             time.Duration
+	refreshInterval time.Duration
+	options         makerOptions
+
+	// fetches is shared by concurrent refreshes so the key set is fetched
+	// once for all of them
+	fetches singleflight.Group
+	// cache holds the last fetched keys and is swapped as a whole, so
+	// lookups never wait for a fetch
+	cache atomic.Pointer[keySetCache]
+	// fetchedAt is when the last fetch started, in Unix nanoseconds
+	fetchedAt atomic.Int64
+}
+
+type keySetCache struct {
+	keys      map[string]JSONWebKey
+	expiresAt time.Time
+}
+
+func NewRemoteKeySet(url string, opts ...RemoteKeySetOption) *RemoteKeySet {
+	ks := &RemoteKeySet{
+		url:             url,
+		client:          &http.Client{Timeout: defaultKeySetTimeout},
+		ttl:             defaultKeySetTTL,
+		refreshInterval: defaultKeySetRefreshInterval,
+		options:         newMakerOptions(nil),
+	}
+	for _, opt := range opts {
+		opt(ks)
+	}
+	return ks
+}
+
+// VerifyToken verifies token with the cached keys. Fetching the key set,
+// when needed, is bounded by the context passed with VerifyWithContext.
+func (ks *RemoteKeySet) VerifyToken(token string, opts ...VerifyOption) (*Payload, error) {
+	var payload *Payload
+	var err error
+
+	ctx := verifyContext(opts)
+	switch {
+	case strings.HasPrefix(token, PasetoVersion2+".public."), strings.HasPrefix(token, PasetoVersion4+".public."):
+		payload, err = ks.verifyPaseto(ctx, token)
+	default:
+		payload, err = verifyJWT(token, func(header jwtHeader) (interface{}, error) {
+			return ks.jwtKey(ctx, header)
+		})
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	err = payload.Validate(ks.options.verifyOptions(opts)...)
+	if err != nil {
+		return nil, err
+	}
+	return payload, nil
+}
+
+func (ks *RemoteKeySet) verifyPaseto(ctx context.Context, token string) (*Payload, error) {
+	keyID, err := footerKeyID(token)
+	if err != nil {
+		return nil, err
+	}
+	jwk, err := ks.key(ctx, keyID)
+	if err != nil {
+		return nil, err
+	}
+	key, err := jwk.PublicKey()
+	if err != nil {
+		return nil, ErrInvalidSignature
+	}
+	publicKey, ok := key.(ed25519.PublicKey)
+	if !ok {
+		return nil, ErrInvalidSignature
+	}
+
+	payload := &Payload{}
+	version := token[:strings.Index(token, ".")]
+	if err := verifyPasetoPublic(version, token, publicKey, payload); err != nil {
+		return nil, err
+	}
+	return payload, nil
+}
+
+// jwtKey returns the key named by the token header if it is meant for the
+// algorithm of the header. Only asymmetric algorithms are accepted, as the
+// key set never holds secrets.
+func (ks *RemoteKeySet) jwtKey(ctx context.Context, header jwtHeader) (interface{}, error) {
+	jwk, err := ks.key(ctx, header.KeyID)
+	if err != nil {
+		return nil, err
+	}
+	if jwk.Algorithm != "" && jwk.Algorithm != header.Algorithm {
+		return nil, ErrInvalidSignature
+	}
+
+	key, err := jwk.PublicKey()
+	if err != nil {
+		return nil, ErrInvalidSignature
+	}
+	switch key.(type) {
+	case *rsa.PublicKey:
+		if header.Algorithm != AlgorithmRS256 {
+			return nil, ErrInvalidSignature
+		}
+	case ed25519.PublicKey:
+		if header.Algorithm != AlgorithmEdDSA {
+			return nil, ErrInvalidSignature
+		}
+	default:
+		return nil, ErrInvalidSignature
+	}
+	if err := validateJWTKey(header.Algorithm, key); err != nil {
+		return nil, ErrInvalidSignature
+	}
+	return key, nil
+}
+
+// key returns a cached key, refetching the key set when the cache has
+// expired or when the key is unknown, which happens right after the auth
+// server rotates its keys
+func (ks *RemoteKeySet) key(ctx context.Context, id string) (JSONWebKey, error) {
+	if id == "" {
+		return JSONWebKey{}, ErrInvalidSignature
+	}
+
+	now := ks.options.clock.Now()
+	cache := ks.cache.Load()
+	if cache == nil || (now.After(cache.expiresAt) && ks.mayRefetch(now)) {
+		if err := ks.refresh(ctx); err != nil && cache == nil {
+			return JSONWebKey{}, err
+		}
+		cache = ks.cache.Load()
+	}
+
+	jwk, exists := cache.keys[id]
+	if !exists && ks.mayRefetch(now) {
+		if err := ks.refresh(ctx); err != nil {
+			return JSONWebKey{}, err
+		}
+		jwk, exists = ks.cache.Load().keys[id]
+	}
+	if !exists {
+		return JSONWebKey{}, ErrInvalidSignature
+	}
+	return jwk, nil
+}
+
+// mayRefetch tells whether the refresh interval has passed since the last
+// fetch started
+func (ks *RemoteKeySet) mayRefetch(now time.Time) bool {
+	return now.Sub(time.Unix(0, ks.fetchedAt.Load())) >= ks.refreshInterval
+}
+
+// Refresh fetches the key set right away, for example to warm the cache at
+// startup
+func (ks *RemoteKeySet) Refresh(ctx context.Context) error {
+	return ks.refresh(ctx)
+}
+
+// refresh fetches the key set, or waits for the fetch already in progress.
+// It returns early when ctx is done, while the fetch goes on for the other
+// callers, bounded by the timeout of the HTTP client. On failure the
+// previously fetched keys are kept.
+func (ks *RemoteKeySet) refresh(ctx context.Context) error {
+	result := ks.fetches.DoChan("", func() (interface{}, error) {
+		return nil, ks.fetch(context.WithoutCancel(ctx))
+	})
+	select {
+	case res := <-result:
+		return res.Err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (ks *RemoteKeySet) fetch(ctx context.Context) error {
+	now := ks.options.clock.Now()
+	ks.fetchedAt.Store(now.UnixNano())
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, ks.url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := ks.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("cannot fetch key set: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("cannot fetch key set: unexpected status %d", resp.StatusCode)
+	}
+
+	var set JSONWebKeySet
+	if err := json.NewDecoder(resp.Body).Decode(&set); err != nil {
+		return fmt.Errorf("cannot decode key set: %w", err)
+	}
+
+	keys := make(map[string]JSONWebKey, len(set.Keys))
+	for _, jwk := range set.Keys {
+		if jwk.KeyID == "" || (jwk.Use != "" && jwk.Use != "sig") {
+			continue
+		}
+		keys[jwk.KeyID] = jwk
+	}
+	if len(keys) == 0 {
+		return errors.New("key set has no signing keys")
+	}
+
+	ks.cache.Store(&keySetCache{
+		keys:      keys,
+		expiresAt: now.Add(cacheMaxAge(resp.Header.Get("Cache-Control"), ks.ttl)),
+	})
+	return nil
+}
+
+// cacheMaxAge reads max-age from a Cache-Control header
+func cacheMaxAge(header string, fallback time.Duration) time.Duration {
+	for _, directive := range strings.Split(header, ",") {
+		name, value, found := strings.Cut(strings.TrimSpace(directive), "=")
+		if !found || !strings.EqualFold(name, "max-age") {
+			continue
+		}
+		seconds, err := strconv.Atoi(value)
+		if err != nil || seconds < 0 {
+			return fallback
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	return fallback
+}
@@ -0,0 +1,136 @@
+package token
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// RevocationStore keeps track of tokens that were revoked before they
+// expired. Entries are only needed until the token would have expired
+// anyway, after which DeleteExpired drops them.
+type RevocationStore interface {
+	// Revoke revokes the single token with the given ID
+	Revoke(ctx context.Context, id uuid.UUID, expiresAt time.Time) error
+	// RevokeUserTokens revokes every token of the user issued before
+	// issuedBefore. expiresAt is when the last of them expires.
+	RevokeUserTokens(ctx context.Context, userID pgtype.UUID, issuedBefore, expiresAt time.Time) error
+	// UserRevocationCutoff returns the issuedBefore the tokens of the user
+	// are revoked up to, or the zero time when none are. Tokens issued to
+	// the user must not be stamped as issued before it.
+	UserRevocationCutoff(ctx context.Context, userID pgtype.UUID) (time.Time, error)
+	IsRevoked(ctx context.Context, payload *Payload) (bool, error)
+	DeleteExpired(ctx context.Context) error
+}
+
+// RevocationCutoff returns the issuedBefore that revokes every token issued
+// up to t. Formats such as JWT keep issue times to the second, so a token
+// issued later within the same second as t cannot be told apart from an
+// earlier one: t is rounded up to the next second to cover them all.
+func RevocationCutoff(t time.Time) time.Time {
+	return t.Truncate(time.Second).Add(time.Second)
+}
+
+type userRevocation struct {
+	issuedBefore time.Time
+	expiresAt    time.Time
+}
+
+// MemoryRevocationStore is an in-process RevocationStore. Revocations are
+// lost on restart and not shared between instances, so it is meant for
+// single instance deployments and tests.
+type MemoryRevocationStore struct {
+	mu      sync.RWMutex
+	revoked map[uuid.UUID]time.Time
+	users   map[[16]byte]userRevocation
+}
+
+func NewMemoryRevocationStore() *MemoryRevocationStore {
+	return &MemoryRevocationStore{
+		revoked: make(map[uuid.UUID]time.Time),
+		users:   make(map[[16]byte]userRevocation),
+	}
+}
+
+func (store *MemoryRevocationStore) Revoke(ctx context.Context, id uuid.UUID, expiresAt time.Time) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	store.revoked[id] = expiresAt
+	return nil
+}
+
+func (store *MemoryRevocationStore) RevokeUserTokens(ctx context.Context, userID pgtype.UUID, issuedBefore, expiresAt time.Time) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	current := store.users[userID.Bytes]
+	if issuedBefore.After(current.issuedBefore) {
+		current.issuedBefore = issuedBefore
+	}
+	if expiresAt.After(current.expiresAt) {
+		current.expiresAt = expiresAt
+	}
+	store.users[userID.Bytes] = current
+	return nil
+}
+
+func (store *MemoryRevocationStore) UserRevocationCutoff(ctx context.Context, userID pgtype.UUID) (time.Time, error) {
+	store.mu.RLock()
+	defer store.mu.RUnlock()
+
+	return store.users[userID.Bytes].issuedBefore, nil
+}
+
+func (store *MemoryRevocationStore) IsRevoked(ctx context.Context, payload *Payload) (bool, error) {
+	store.mu.RLock()
+	defer store.mu.RUnlock()
+
+	if _, revoked := store.revoked[payload.ID]; revoked {
+		return true, nil
+	}
+	if user, ok := store.users[payload.UserID.Bytes]; ok && payload.IssuedAt.Before(user.issuedBefore) {
+		return true, nil
+	}
+	return false, nil
+}
+
+func (store *MemoryRevocationStore) DeleteExpired(ctx context.Context) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	now := time.Now()
+	for id, expiresAt := range store.revoked {
+		if now.After(expiresAt) {
+			delete(store.revoked, id)
+		}
+	}
+	for userID, user := range store.users {
+		if now.After(user.expiresAt) {
+			delete(store.users, userID)
+		}
+	}
+	return nil
+}
+
+// RunRevocationCleanup deletes expired entries from the store every interval
+// until ctx is cancelled. It blocks, so run it in its own goroutine.
+func RunRevocationCleanup(ctx context.Context, store RevocationStore, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := store.DeleteExpired(ctx); err != nil {
+				log.Printf("revocation cleanup failed: %v", err)
+			}
+		}
+	}
+}
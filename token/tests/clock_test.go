@@ -0,0 +1,74 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/suryansh74/auth-package/internal/utils"
+	"github.com/suryansh74/auth-package/token"
+)
+
+func TestMakerUsesClock(t *testing.T) {
+	start := time.Date(2025, time.January, 1, 12, 0, 0, 0, time.UTC)
+	clock := token.NewFakeClock(start)
+
+	maker, err := token.NewPasetoMaker(utils.RandomString(32), token.WithClock(clock))
+	require.NoError(t, err)
+
+	accessToken, err := maker.CreateToken(randomUserID(), utils.RandomEmail(), time.Minute)
+	require.NoError(t, err)
+
+	payload, err := maker.VerifyToken(accessToken)
+	require.NoError(t, err)
+	require.True(t, payload.IssuedAt.Equal(start))
+	require.True(t, payload.ExpiredAt.Equal(start.Add(time.Minute)))
+
+	clock.Advance(time.Minute + time.Nanosecond)
+	payload, err = maker.VerifyToken(accessToken)
+	require.ErrorIs(t, err, token.ErrExpiredToken)
+	require.Nil(t, payload)
+}
+
+func TestMakerLeeway(t *testing.T) {
+	start := time.Date(2025, time.January, 1, 12, 0, 0, 0, time.UTC)
+	clock := token.NewFakeClock(start)
+
+	maker, err := token.NewPasetoMaker(utils.RandomString(32), token.WithClock(clock), token.WithLeeway(5*time.Second))
+	require.NoError(t, err)
+
+	accessToken, err := maker.CreateToken(randomUserID(), utils.RandomEmail(), time.Minute)
+	require.NoError(t, err)
+
+	// expired, but within the leeway
+	clock.Advance(time.Minute + 3*time.Second)
+	_, err = maker.VerifyToken(accessToken)
+	require.NoError(t, err)
+
+	// a single verification can narrow the leeway
+	_, err = maker.VerifyToken(accessToken, token.AllowClockSkew(0))
+	require.ErrorIs(t, err, token.ErrExpiredToken)
+
+	clock.Advance(5 * time.Second)
+	_, err = maker.VerifyToken(accessToken)
+	require.ErrorIs(t, err, token.ErrExpiredToken)
+}
+
+func TestNotBeforeLeeway(t *testing.T) {
+	start := time.Date(2025, time.January, 1, 12, 0, 0, 0, time.UTC)
+	issuerClock := token.NewFakeClock(start)
+	// the verifying server runs two seconds behind the issuer
+	verifierClock := token.NewFakeClock(start.Add(-2 * time.Second))
+
+	maker, err := token.NewPasetoMaker(utils.RandomString(32), token.WithClock(issuerClock), token.WithLeeway(5*time.Second))
+	require.NoError(t, err)
+
+	accessToken, err := maker.CreateToken(randomUserID(), utils.RandomEmail(), time.Minute)
+	require.NoError(t, err)
+
+	_, err = maker.VerifyToken(accessToken, token.VerifyWithClock(verifierClock))
+	require.NoError(t, err)
+
+	_, err = maker.VerifyToken(accessToken, token.VerifyWithClock(verifierClock), token.AllowClockSkew(0))
+	require.ErrorIs(t, err, token.ErrTokenNotYetValid)
+}
@@ -0,0 +1,253 @@
+package tests
+
+import (
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+	"github.com/suryansh74/auth-package/internal/utils"
+	"github.com/suryansh74/auth-package/token"
+)
+
+func randomUserID() pgtype.UUID {
+	return pgtype.UUID{Bytes: uuid.New(), Valid: true}
+}
+
+func TestJWTMaker(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name       string
+		algorithm  string
+		signingKey interface{}
+	}{
+		{name: "HS256", algorithm: token.AlgorithmHS256, signingKey: []byte(utils.RandomString(32))},
+		{name: "RS256", algorithm: token.AlgorithmRS256, signingKey: rsaKey},
+		{name: "EdDSA", algorithm: token.AlgorithmEdDSA, signingKey: edKey},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			maker, err := token.NewJWTMaker(tc.algorithm, tc.signingKey)
+			require.NoError(t, err)
+
+			userID := randomUserID()
+			email := utils.RandomEmail()
+			duration := time.Minute
+			issuedAt := time.Now()
+
+			accessToken, err := maker.CreateToken(userID, email, duration)
+			require.NoError(t, err)
+			require.NotEmpty(t, accessToken)
+
+			payload, err := maker.VerifyToken(accessToken)
+			require.NoError(t, err)
+			require.NotZero(t, payload.ID)
+			require.Equal(t, userID, payload.UserID)
+			require.Equal(t, email, payload.Email)
+			require.WithinDuration(t, issuedAt, payload.IssuedAt, time.Second)
+			require.WithinDuration(t, issuedAt.Add(duration), payload.ExpiredAt, time.Second)
+		})
+	}
+}
+
+func TestJWTSessionID(t *testing.T) {
+	maker, err := token.NewJWTMaker(token.AlgorithmHS256, []byte(utils.RandomString(32)))
+	require.NoError(t, err)
+
+	sessionID := uuid.New()
+	accessToken, err := maker.CreateToken(randomUserID(), utils.RandomEmail(), time.Minute, token.WithSessionID(sessionID))
+	require.NoError(t, err)
+
+	payload, err := maker.VerifyToken(accessToken)
+	require.NoError(t, err)
+	require.Equal(t, sessionID, payload.SessionID)
+}
+
+func TestJWTOrgID(t *testing.T) {
+	maker, err := token.NewJWTMaker(token.AlgorithmHS256, []byte(utils.RandomString(32)))
+	require.NoError(t, err)
+
+	orgID := uuid.New()
+	accessToken, err := maker.CreateToken(randomUserID(), utils.RandomEmail(), time.Minute, token.WithOrgID(orgID))
+	require.NoError(t, err)
+
+	payload, err := maker.VerifyToken(accessToken)
+	require.NoError(t, err)
+	require.Equal(t, orgID, payload.OrgID)
+
+	// tokens issued outside of an organization carry no org_id claim
+	accessToken, err = maker.CreateToken(randomUserID(), utils.RandomEmail(), time.Minute)
+	require.NoError(t, err)
+
+	payload, err = maker.VerifyToken(accessToken)
+	require.NoError(t, err)
+	require.Equal(t, uuid.Nil, payload.OrgID)
+}
+
+func TestJWTCustomClaims(t *testing.T) {
+	maker, err := token.NewJWTMaker(token.AlgorithmHS256, []byte(utils.RandomString(32)))
+	require.NoError(t, err)
+
+	accessToken, err := maker.CreateToken(randomUserID(), utils.RandomEmail(), time.Minute,
+		token.WithIssuer("auth"),
+		token.WithAudience("api"),
+		token.WithRoles("admin"),
+		token.WithScopes("read", "write"),
+		token.WithExtraClaims(map[string]interface{}{"tenant": "acme"}),
+	)
+	require.NoError(t, err)
+
+	payload, err := maker.VerifyToken(accessToken, token.ExpectIssuer("auth"), token.ExpectAudience("api"), token.RequireScopes("write"))
+	require.NoError(t, err)
+	require.Equal(t, []string{"admin"}, payload.Roles)
+	require.Equal(t, []string{"read", "write"}, payload.Scopes)
+	require.Equal(t, "acme", payload.Extra["tenant"])
+
+	_, err = maker.VerifyToken(accessToken, token.ExpectAudience("billing"))
+	require.ErrorIs(t, err, token.ErrInvalidAudience)
+}
+
+func TestJWTPurpose(t *testing.T) {
+	maker, err := token.NewJWTMaker(token.AlgorithmHS256, []byte(utils.RandomString(32)))
+	require.NoError(t, err)
+
+	resetToken, err := maker.CreateToken(randomUserID(), utils.RandomEmail(), time.Minute,
+		token.WithPurpose(token.PurposePasswordReset),
+	)
+	require.NoError(t, err)
+
+	// not accepted where an access token is expected
+	_, err = maker.VerifyToken(resetToken)
+	require.ErrorIs(t, err, token.ErrInvalidPurpose)
+
+	payload, err := maker.VerifyToken(resetToken, token.ExpectPurpose(token.PurposePasswordReset))
+	require.NoError(t, err)
+	require.Equal(t, token.PurposePasswordReset, payload.Purpose)
+}
+
+func TestJWTExpectOneOfPurposes(t *testing.T) {
+	maker, err := token.NewJWTMaker(token.AlgorithmHS256, []byte(utils.RandomString(32)))
+	require.NoError(t, err)
+
+	restrictedToken, err := maker.CreateToken(randomUserID(), utils.RandomEmail(), time.Minute,
+		token.WithPurpose(token.PurposeRestricted),
+	)
+	require.NoError(t, err)
+	accessToken, err := maker.CreateToken(randomUserID(), utils.RandomEmail(), time.Minute)
+	require.NoError(t, err)
+
+	_, err = maker.VerifyToken(restrictedToken)
+	require.ErrorIs(t, err, token.ErrInvalidPurpose)
+
+	for _, tokenString := range []string{restrictedToken, accessToken} {
+		_, err = maker.VerifyToken(tokenString, token.ExpectPurpose(token.PurposeAccess, token.PurposeRestricted))
+		require.NoError(t, err)
+	}
+
+	_, err = maker.VerifyToken(restrictedToken, token.ExpectPurpose(token.PurposeEmailVerification, token.PurposePasswordReset))
+	require.ErrorIs(t, err, token.ErrInvalidPurpose)
+}
+
+func TestJWTReservedExtraClaim(t *testing.T) {
+	maker, err := token.NewJWTMaker(token.AlgorithmHS256, []byte(utils.RandomString(32)))
+	require.NoError(t, err)
+
+	_, err = maker.CreateToken(randomUserID(), utils.RandomEmail(), time.Minute,
+		token.WithExtraClaims(map[string]interface{}{"sub": "someone-else"}),
+	)
+	require.ErrorIs(t, err, token.ErrReservedClaimInUse)
+}
+
+func TestExpiredJWTToken(t *testing.T) {
+	maker, err := token.NewJWTMaker(token.AlgorithmHS256, []byte(utils.RandomString(32)))
+	require.NoError(t, err)
+
+	accessToken, err := maker.CreateToken(randomUserID(), utils.RandomEmail(), -time.Minute)
+	require.NoError(t, err)
+
+	payload, err := maker.VerifyToken(accessToken)
+	require.ErrorIs(t, err, token.ErrExpiredToken)
+	require.Nil(t, payload)
+}
+
+func TestJWTAlgNoneRejected(t *testing.T) {
+	maker, err := token.NewJWTMaker(token.AlgorithmHS256, []byte(utils.RandomString(32)))
+	require.NoError(t, err)
+
+	accessToken, err := maker.CreateToken(randomUserID(), utils.RandomEmail(), time.Minute)
+	require.NoError(t, err)
+
+	parts := strings.Split(accessToken, ".")
+	header := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none","typ":"JWT"}`))
+	unsigned := header + "." + parts[1] + "."
+
+	payload, err := maker.VerifyToken(unsigned)
+	require.ErrorIs(t, err, token.ErrInvalidSignature)
+	require.ErrorIs(t, err, token.ErrInvalidToken)
+	require.Nil(t, payload)
+}
+
+func TestJWTAlgorithmConfusionRejected(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	maker, err := token.NewJWTMaker(token.AlgorithmRS256, rsaKey)
+	require.NoError(t, err)
+
+	accessToken, err := maker.CreateToken(randomUserID(), utils.RandomEmail(), time.Minute)
+	require.NoError(t, err)
+
+	// re-sign the claims with HS256 using the public key as the HMAC secret
+	publicKey, err := x509.MarshalPKIXPublicKey(&rsaKey.PublicKey)
+	require.NoError(t, err)
+	parts := strings.Split(accessToken, ".")
+	header := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`))
+	mac := hmac.New(sha256.New, publicKey)
+	mac.Write([]byte(header + "." + parts[1]))
+	forged := header + "." + parts[1] + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+
+	payload, err := maker.VerifyToken(forged)
+	require.ErrorIs(t, err, token.ErrInvalidSignature)
+	require.Nil(t, payload)
+}
+
+func TestJWTInvalidSignature(t *testing.T) {
+	maker, err := token.NewJWTMaker(token.AlgorithmHS256, []byte(utils.RandomString(32)))
+	require.NoError(t, err)
+	otherMaker, err := token.NewJWTMaker(token.AlgorithmHS256, []byte(utils.RandomString(32)))
+	require.NoError(t, err)
+
+	accessToken, err := otherMaker.CreateToken(randomUserID(), utils.RandomEmail(), time.Minute)
+	require.NoError(t, err)
+
+	payload, err := maker.VerifyToken(accessToken)
+	require.ErrorIs(t, err, token.ErrInvalidSignature)
+	require.Nil(t, payload)
+}
+
+func TestJWTMalformedToken(t *testing.T) {
+	maker, err := token.NewJWTMaker(token.AlgorithmHS256, []byte(utils.RandomString(32)))
+	require.NoError(t, err)
+
+	for _, malformed := range []string{"", "not-a-token", "a.b.c", "a.b"} {
+		payload, err := maker.VerifyToken(malformed)
+		require.ErrorIs(t, err, token.ErrMalformedToken)
+		require.ErrorIs(t, err, token.ErrInvalidToken)
+		require.Nil(t, payload)
+	}
+}
@@ -0,0 +1,125 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/o1egl/paseto"
+	"github.com/stretchr/testify/require"
+	"github.com/suryansh74/auth-package/internal/utils"
+	"github.com/suryansh74/auth-package/token"
+)
+
+func TestPasetoMaker(t *testing.T) {
+	maker, err := token.NewPasetoMaker(utils.RandomString(32))
+	require.NoError(t, err)
+
+	userID := randomUserID()
+	email := utils.RandomEmail()
+	issuedAt := time.Now()
+
+	accessToken, err := maker.CreateToken(userID, email, time.Minute)
+	require.NoError(t, err)
+
+	payload, err := maker.VerifyToken(accessToken)
+	require.NoError(t, err)
+	require.Equal(t, userID, payload.UserID)
+	require.Equal(t, email, payload.Email)
+	require.WithinDuration(t, issuedAt, payload.IssuedAt, time.Second)
+	require.WithinDuration(t, issuedAt.Add(time.Minute), payload.ExpiredAt, time.Second)
+}
+
+func TestPasetoKeyRotation(t *testing.T) {
+	oldKey := utils.RandomString(32)
+	newKey := utils.RandomString(32)
+
+	maker, err := token.NewPasetoMaker(oldKey)
+	require.NoError(t, err)
+	oldToken, err := maker.CreateToken(randomUserID(), utils.RandomEmail(), time.Minute)
+	require.NoError(t, err)
+
+	ring := maker.(token.KeyRotator).KeyRing()
+	oldID := ring.ActiveKeyID()
+	require.NoError(t, ring.AddKey("new", []byte(newKey)))
+	require.NoError(t, ring.ActivateKey("new"))
+	require.Equal(t, "new", ring.ActiveKeyID())
+	require.ErrorIs(t, ring.RemoveKey("new"), token.ErrRemoveActiveKey)
+
+	// tokens from the retired key are still accepted
+	_, err = maker.VerifyToken(oldToken)
+	require.NoError(t, err)
+
+	newToken, err := maker.CreateToken(randomUserID(), utils.RandomEmail(), time.Minute)
+	require.NoError(t, err)
+	_, err = maker.VerifyToken(newToken)
+	require.NoError(t, err)
+
+	// a maker restarted with the old key retired accepts both tokens
+	restarted, err := token.NewPasetoMaker(newKey, token.WithRetiredKeys(oldKey))
+	require.NoError(t, err)
+	_, err = restarted.VerifyToken(oldToken)
+	require.NoError(t, err)
+
+	// once removed, the old key is no longer accepted
+	require.NoError(t, ring.RemoveKey(oldID))
+	payload, err := maker.VerifyToken(oldToken)
+	require.ErrorIs(t, err, token.ErrInvalidSignature)
+	require.Nil(t, payload)
+}
+
+func TestPasetoTokenWithoutKeyID(t *testing.T) {
+	key := utils.RandomString(32)
+	maker, err := token.NewPasetoMaker(key)
+	require.NoError(t, err)
+
+	payload, err := token.NewPayload(randomUserID(), utils.RandomEmail(), time.Minute)
+	require.NoError(t, err)
+	legacyToken, err := paseto.NewV2().Encrypt([]byte(key), payload, nil)
+	require.NoError(t, err)
+
+	verified, err := maker.VerifyToken(legacyToken)
+	require.NoError(t, err)
+	require.Equal(t, payload.ID, verified.ID)
+}
+
+func TestPasetoInvalidKeySize(t *testing.T) {
+	maker, err := token.NewPasetoMaker(utils.RandomString(16))
+	require.Error(t, err)
+	require.Nil(t, maker)
+}
+
+func TestPasetoVerifyErrors(t *testing.T) {
+	maker, err := token.NewPasetoMaker(utils.RandomString(32))
+	require.NoError(t, err)
+	otherMaker, err := token.NewPasetoMaker(utils.RandomString(32))
+	require.NoError(t, err)
+
+	// no key ID in the footer, so the active key is tried and fails
+	payload, err := token.NewPayload(randomUserID(), utils.RandomEmail(), time.Minute)
+	require.NoError(t, err)
+	forged, err := paseto.NewV2().Encrypt([]byte(utils.RandomString(32)), payload, nil)
+	require.NoError(t, err)
+
+	otherToken, err := otherMaker.CreateToken(randomUserID(), utils.RandomEmail(), time.Minute)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name        string
+		token       string
+		expectedErr error
+	}{
+		{name: "Garbage", token: "not-a-token", expectedErr: token.ErrMalformedToken},
+		{name: "WrongVersion", token: "v1.local.AAAA", expectedErr: token.ErrMalformedToken},
+		{name: "UnknownKeyID", token: otherToken, expectedErr: token.ErrInvalidSignature},
+		{name: "WrongKey", token: forged, expectedErr: token.ErrInvalidSignature},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			payload, err := maker.VerifyToken(tc.token)
+			require.ErrorIs(t, err, tc.expectedErr)
+			require.ErrorIs(t, err, token.ErrInvalidToken)
+			require.Nil(t, payload)
+		})
+	}
+}
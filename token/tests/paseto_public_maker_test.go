@@ -0,0 +1,82 @@
+package tests
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/suryansh74/auth-package/internal/utils"
+	"github.com/suryansh74/auth-package/token"
+)
+
+func TestPasetoPublicMaker(t *testing.T) {
+	for _, version := range []string{token.PasetoVersion2, token.PasetoVersion4} {
+		t.Run(version, func(t *testing.T) {
+			publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+			require.NoError(t, err)
+
+			maker, err := token.NewPasetoPublicMaker(version, privateKey)
+			require.NoError(t, err)
+
+			// verifiers only get the public key
+			verifier, err := token.NewPasetoPublicVerifier(version, publicKey)
+			require.NoError(t, err)
+
+			userID := randomUserID()
+			email := utils.RandomEmail()
+			issuedAt := time.Now()
+
+			signedToken, err := maker.CreateToken(userID, email, time.Minute)
+			require.NoError(t, err)
+			require.Contains(t, signedToken, version+".public.")
+
+			payload, err := verifier.VerifyToken(signedToken)
+			require.NoError(t, err)
+			require.Equal(t, userID, payload.UserID)
+			require.Equal(t, email, payload.Email)
+			require.WithinDuration(t, issuedAt, payload.IssuedAt, time.Second)
+			require.WithinDuration(t, issuedAt.Add(time.Minute), payload.ExpiredAt, time.Second)
+		})
+	}
+}
+
+func TestPasetoPublicWrongKey(t *testing.T) {
+	for _, version := range []string{token.PasetoVersion2, token.PasetoVersion4} {
+		t.Run(version, func(t *testing.T) {
+			_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+			require.NoError(t, err)
+			otherPublicKey, _, err := ed25519.GenerateKey(rand.Reader)
+			require.NoError(t, err)
+
+			maker, err := token.NewPasetoPublicMaker(version, privateKey)
+			require.NoError(t, err)
+			verifier, err := token.NewPasetoPublicVerifier(version, otherPublicKey)
+			require.NoError(t, err)
+
+			signedToken, err := maker.CreateToken(randomUserID(), utils.RandomEmail(), time.Minute)
+			require.NoError(t, err)
+
+			payload, err := verifier.VerifyToken(signedToken)
+			require.ErrorIs(t, err, token.ErrInvalidSignature)
+			require.Nil(t, payload)
+		})
+	}
+}
+
+func TestPasetoPublicRejectsLocalToken(t *testing.T) {
+	publicKey, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	verifier, err := token.NewPasetoPublicVerifier(token.PasetoVersion4, publicKey)
+	require.NoError(t, err)
+
+	localMaker, err := token.NewPasetoMaker(utils.RandomString(32))
+	require.NoError(t, err)
+	localToken, err := localMaker.CreateToken(randomUserID(), utils.RandomEmail(), time.Minute)
+	require.NoError(t, err)
+
+	payload, err := verifier.VerifyToken(localToken)
+	require.ErrorIs(t, err, token.ErrMalformedToken)
+	require.Nil(t, payload)
+}
@@ -0,0 +1,106 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/suryansh74/auth-package/internal/utils"
+	"github.com/suryansh74/auth-package/token"
+)
+
+func TestPayloadValidate(t *testing.T) {
+	testCases := []struct {
+		name        string
+		duration    time.Duration
+		claims      []token.PayloadOption
+		verifyOpts  []token.VerifyOption
+		expectedErr error
+	}{
+		{
+			name:     "OK",
+			duration: time.Minute,
+			claims: []token.PayloadOption{
+				token.WithIssuer("auth"),
+				token.WithAudience("api", "admin"),
+				token.WithScopes("read", "write"),
+			},
+			verifyOpts: []token.VerifyOption{
+				token.ExpectIssuer("auth"),
+				token.ExpectAudience("admin"),
+				token.RequireScopes("read", "write"),
+			},
+		},
+		{
+			name:        "Expired",
+			duration:    -time.Minute,
+			expectedErr: token.ErrExpiredToken,
+		},
+		{
+			name:       "ExpiredWithinClockSkew",
+			duration:   -time.Second,
+			verifyOpts: []token.VerifyOption{token.AllowClockSkew(5 * time.Second)},
+		},
+		{
+			name:        "NotYetValid",
+			duration:    time.Hour,
+			claims:      []token.PayloadOption{token.WithNotBefore(time.Now().Add(time.Minute))},
+			expectedErr: token.ErrTokenNotYetValid,
+		},
+		{
+			name:        "WrongIssuer",
+			duration:    time.Minute,
+			claims:      []token.PayloadOption{token.WithIssuer("someone-else")},
+			verifyOpts:  []token.VerifyOption{token.ExpectIssuer("auth")},
+			expectedErr: token.ErrInvalidIssuer,
+		},
+		{
+			name:        "WrongAudience",
+			duration:    time.Minute,
+			claims:      []token.PayloadOption{token.WithAudience("api")},
+			verifyOpts:  []token.VerifyOption{token.ExpectAudience("admin")},
+			expectedErr: token.ErrInvalidAudience,
+		},
+		{
+			name:        "MissingScope",
+			duration:    time.Minute,
+			claims:      []token.PayloadOption{token.WithScopes("read")},
+			verifyOpts:  []token.VerifyOption{token.RequireScopes("read", "write")},
+			expectedErr: token.ErrInsufficientScope,
+		},
+		{
+			name:       "ExpectedPurpose",
+			duration:   time.Minute,
+			claims:     []token.PayloadOption{token.WithPurpose(token.PurposePasswordReset)},
+			verifyOpts: []token.VerifyOption{token.ExpectPurpose(token.PurposePasswordReset)},
+		},
+		{
+			name:        "PurposeTokenUsedAsAccessToken",
+			duration:    time.Minute,
+			claims:      []token.PayloadOption{token.WithPurpose(token.PurposeEmailVerification)},
+			expectedErr: token.ErrInvalidPurpose,
+		},
+		{
+			name:        "AccessTokenUsedForOtherPurpose",
+			duration:    time.Minute,
+			verifyOpts:  []token.VerifyOption{token.ExpectPurpose(token.PurposePasswordReset)},
+			expectedErr: token.ErrInvalidPurpose,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			payload, err := token.NewPayload(randomUserID(), utils.RandomEmail(), tc.duration, tc.claims...)
+			require.NoError(t, err)
+
+			err = payload.Validate(tc.verifyOpts...)
+			if tc.expectedErr == nil {
+				require.NoError(t, err)
+			} else {
+				require.ErrorIs(t, err, tc.expectedErr)
+			}
+		})
+	}
+}
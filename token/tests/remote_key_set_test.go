@@ -0,0 +1,222 @@
+package tests
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/suryansh74/auth-package/internal/utils"
+	"github.com/suryansh74/auth-package/token"
+)
+
+// serveKeySet serves the key set of publisher and counts the requests
+func serveKeySet(t *testing.T, publisher token.KeySetPublisher, requests *int32) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(requests, 1)
+		w.Header().Set("Cache-Control", "public, max-age=60")
+		require.NoError(t, json.NewEncoder(w).Encode(publisher.PublicKeySet()))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestRemoteKeySet(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	rs256, err := token.NewJWTMaker(token.AlgorithmRS256, rsaKey)
+	require.NoError(t, err)
+	edDSA, err := token.NewJWTMaker(token.AlgorithmEdDSA, edKey)
+	require.NoError(t, err)
+	pasetoV2, err := token.NewPasetoPublicMaker(token.PasetoVersion2, edKey)
+	require.NoError(t, err)
+	pasetoV4, err := token.NewPasetoPublicMaker(token.PasetoVersion4, edKey)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name  string
+		maker token.Maker
+	}{
+		{name: "RS256", maker: rs256},
+		{name: "EdDSA", maker: edDSA},
+		{name: "PasetoV2", maker: pasetoV2},
+		{name: "PasetoV4", maker: pasetoV4},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var requests int32
+			server := serveKeySet(t, tc.maker.(token.KeySetPublisher), &requests)
+			keySet := token.NewRemoteKeySet(server.URL)
+
+			userID := randomUserID()
+			for i := 0; i < 3; i++ {
+				signedToken, err := tc.maker.CreateToken(userID, utils.RandomEmail(), time.Minute)
+				require.NoError(t, err)
+
+				payload, err := keySet.VerifyToken(signedToken)
+				require.NoError(t, err)
+				require.Equal(t, userID, payload.UserID)
+			}
+			// keys are cached
+			require.Equal(t, int32(1), atomic.LoadInt32(&requests))
+		})
+	}
+}
+
+func TestRemoteKeySetRejectsHS256(t *testing.T) {
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	maker, err := token.NewJWTMaker(token.AlgorithmEdDSA, edKey)
+	require.NoError(t, err)
+
+	var requests int32
+	server := serveKeySet(t, maker.(token.KeySetPublisher), &requests)
+	keySet := token.NewRemoteKeySet(server.URL)
+
+	signedToken, err := maker.CreateToken(randomUserID(), utils.RandomEmail(), time.Minute)
+	require.NoError(t, err)
+
+	// re-sign the claims with HS256 using the published key as the secret
+	set := maker.(token.KeySetPublisher).PublicKeySet()
+	require.Len(t, set.Keys, 1)
+	publicKey, err := set.Keys[0].PublicKey()
+	require.NoError(t, err)
+	parts := strings.Split(signedToken, ".")
+	header := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT","kid":"` + set.Keys[0].KeyID + `"}`))
+	mac := hmac.New(sha256.New, publicKey.(ed25519.PublicKey))
+	mac.Write([]byte(header + "." + parts[1]))
+	forged := header + "." + parts[1] + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+
+	payload, err := keySet.VerifyToken(forged)
+	require.ErrorIs(t, err, token.ErrInvalidSignature)
+	require.Nil(t, payload)
+}
+
+func TestRemoteKeySetRotation(t *testing.T) {
+	_, oldKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	_, newKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	oldMaker, err := token.NewPasetoPublicMaker(token.PasetoVersion4, oldKey)
+	require.NoError(t, err)
+	newMaker, err := token.NewPasetoPublicMaker(token.PasetoVersion4, newKey,
+		token.WithRetiredPublicKeys(oldKey.Public()))
+	require.NoError(t, err)
+
+	// the new maker publishes both keys and accepts tokens of the old one
+	require.Len(t, newMaker.(token.KeySetPublisher).PublicKeySet().Keys, 2)
+	oldToken, err := oldMaker.CreateToken(randomUserID(), utils.RandomEmail(), time.Minute)
+	require.NoError(t, err)
+	_, err = newMaker.VerifyToken(oldToken)
+	require.NoError(t, err)
+
+	// a remote key set that cached the old keys refetches on an unknown key ID
+	var publisher atomic.Value
+	publisher.Store(oldMaker.(token.KeySetPublisher))
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		require.NoError(t, json.NewEncoder(w).Encode(publisher.Load().(token.KeySetPublisher).PublicKeySet()))
+	}))
+	defer server.Close()
+
+	clock := token.NewFakeClock(time.Now())
+	keySet := token.NewRemoteKeySet(server.URL, token.WithVerifierOptions(token.WithClock(clock)))
+	_, err = keySet.VerifyToken(oldToken)
+	require.NoError(t, err)
+
+	publisher.Store(newMaker.(token.KeySetPublisher))
+	newToken, err := newMaker.CreateToken(randomUserID(), utils.RandomEmail(), time.Minute)
+	require.NoError(t, err)
+
+	// refetching is rate limited
+	_, err = keySet.VerifyToken(newToken)
+	require.ErrorIs(t, err, token.ErrInvalidSignature)
+
+	clock.Advance(2 * time.Minute)
+	newToken, err = newMaker.CreateToken(randomUserID(), utils.RandomEmail(), time.Hour)
+	require.NoError(t, err)
+	_, err = keySet.VerifyToken(newToken)
+	require.NoError(t, err)
+	require.Equal(t, int32(2), atomic.LoadInt32(&requests))
+}
+
+func TestRemoteKeySetFetchDoesNotBlockVerification(t *testing.T) {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	_, unknownKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	maker, err := token.NewPasetoPublicMaker(token.PasetoVersion4, key)
+	require.NoError(t, err)
+	unknownMaker, err := token.NewPasetoPublicMaker(token.PasetoVersion4, unknownKey)
+	require.NoError(t, err)
+
+	// every fetch after the first one hangs until released
+	release := make(chan struct{})
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1) > 1 {
+			<-release
+		}
+		require.NoError(t, json.NewEncoder(w).Encode(maker.(token.KeySetPublisher).PublicKeySet()))
+	}))
+	defer server.Close()
+	defer close(release)
+
+	knownToken, err := maker.CreateToken(randomUserID(), utils.RandomEmail(), time.Hour)
+	require.NoError(t, err)
+	clock := token.NewFakeClock(time.Now())
+	keySet := token.NewRemoteKeySet(server.URL, token.WithVerifierOptions(token.WithClock(clock)))
+	_, err = keySet.VerifyToken(knownToken)
+	require.NoError(t, err)
+
+	// an unknown key ID makes the key set refetch
+	clock.Advance(2 * time.Minute)
+	unknownToken, err := unknownMaker.CreateToken(randomUserID(), utils.RandomEmail(), time.Hour)
+	require.NoError(t, err)
+	done := make(chan error, 1)
+	go func() {
+		_, err := keySet.VerifyToken(unknownToken)
+		done <- err
+	}()
+	require.Eventually(t, func() bool { return atomic.LoadInt32(&requests) == 2 }, time.Second, time.Millisecond)
+
+	// tokens of cached keys are verified meanwhile
+	verified := make(chan error, 1)
+	go func() {
+		_, err := keySet.VerifyToken(knownToken)
+		verified <- err
+	}()
+	select {
+	case err := <-verified:
+		require.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("verification waited for the key set fetch")
+	}
+
+	// callers waiting for the fetch stop with their context, and share the
+	// fetch in progress
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	require.ErrorIs(t, keySet.Refresh(ctx), context.DeadlineExceeded)
+	require.Equal(t, int32(2), atomic.LoadInt32(&requests))
+
+	release <- struct{}{}
+	require.ErrorIs(t, <-done, token.ErrInvalidSignature)
+}
@@ -0,0 +1,125 @@
+package tests
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/suryansh74/auth-package/internal/utils"
+	"github.com/suryansh74/auth-package/token"
+)
+
+func TestMemoryRevocationStore(t *testing.T) {
+	store := token.NewMemoryRevocationStore()
+	ctx := context.Background()
+
+	payload, err := token.NewPayload(randomUserID(), utils.RandomEmail(), time.Minute)
+	require.NoError(t, err)
+
+	revoked, err := store.IsRevoked(ctx, payload)
+	require.NoError(t, err)
+	require.False(t, revoked)
+
+	require.NoError(t, store.Revoke(ctx, payload.ID, payload.ExpiredAt))
+
+	revoked, err = store.IsRevoked(ctx, payload)
+	require.NoError(t, err)
+	require.True(t, revoked)
+
+	// entries are kept until the token would have expired
+	require.NoError(t, store.DeleteExpired(ctx))
+	revoked, err = store.IsRevoked(ctx, payload)
+	require.NoError(t, err)
+	require.True(t, revoked)
+}
+
+func TestMemoryRevocationStoreDeleteExpired(t *testing.T) {
+	store := token.NewMemoryRevocationStore()
+	ctx := context.Background()
+
+	payload, err := token.NewPayload(randomUserID(), utils.RandomEmail(), -time.Minute)
+	require.NoError(t, err)
+	require.NoError(t, store.Revoke(ctx, payload.ID, payload.ExpiredAt))
+
+	require.NoError(t, store.DeleteExpired(ctx))
+
+	revoked, err := store.IsRevoked(ctx, payload)
+	require.NoError(t, err)
+	require.False(t, revoked)
+}
+
+func TestMemoryRevocationStoreRevokeUserTokens(t *testing.T) {
+	store := token.NewMemoryRevocationStore()
+	ctx := context.Background()
+	userID := randomUserID()
+
+	oldPayload, err := token.NewPayload(userID, utils.RandomEmail(), time.Minute)
+	require.NoError(t, err)
+	otherUserPayload, err := token.NewPayload(randomUserID(), utils.RandomEmail(), time.Minute)
+	require.NoError(t, err)
+
+	revokedBefore, err := store.UserRevocationCutoff(ctx, userID)
+	require.NoError(t, err)
+	require.True(t, revokedBefore.IsZero())
+
+	cutoff := time.Now()
+	require.NoError(t, store.RevokeUserTokens(ctx, userID, cutoff, cutoff.Add(time.Minute)))
+	// an earlier revocation does not move the cutoff back
+	require.NoError(t, store.RevokeUserTokens(ctx, userID, cutoff.Add(-time.Hour), cutoff.Add(time.Minute)))
+	revokedBefore, err = store.UserRevocationCutoff(ctx, userID)
+	require.NoError(t, err)
+	require.Equal(t, cutoff, revokedBefore)
+
+	newPayload, err := token.NewPayload(userID, utils.RandomEmail(), time.Minute)
+	require.NoError(t, err)
+
+	revoked, err := store.IsRevoked(ctx, oldPayload)
+	require.NoError(t, err)
+	require.True(t, revoked)
+
+	revoked, err = store.IsRevoked(ctx, newPayload)
+	require.NoError(t, err)
+	require.False(t, revoked)
+
+	revoked, err = store.IsRevoked(ctx, otherUserPayload)
+	require.NoError(t, err)
+	require.False(t, revoked)
+}
+
+func TestRevocationCutoff(t *testing.T) {
+	changedAt := time.Date(2026, time.March, 1, 12, 0, 0, 400_000_000, time.UTC)
+	cutoff := token.RevocationCutoff(changedAt)
+	require.Equal(t, time.Date(2026, time.March, 1, 12, 0, 1, 0, time.UTC), cutoff)
+	// a change on a whole second still covers tokens issued within it
+	require.Equal(t, cutoff, token.RevocationCutoff(changedAt.Truncate(time.Second)))
+
+	store := token.NewMemoryRevocationStore()
+	ctx := context.Background()
+	userID := randomUserID()
+	clock := token.NewFakeClock(changedAt)
+	maker, err := token.NewJWTMaker(token.AlgorithmHS256, []byte(utils.RandomString(32)), token.WithClock(clock))
+	require.NoError(t, err)
+	require.NoError(t, store.RevokeUserTokens(ctx, userID, cutoff, cutoff.Add(time.Minute)))
+
+	// issued after the change within the same second, which JWT cannot tell
+	// from before it
+	clock.Advance(300 * time.Millisecond)
+	tokenString, err := maker.CreateToken(userID, utils.RandomEmail(), time.Minute)
+	require.NoError(t, err)
+	payload, err := maker.VerifyToken(tokenString)
+	require.NoError(t, err)
+	revoked, err := store.IsRevoked(ctx, payload)
+	require.NoError(t, err)
+	require.True(t, revoked)
+
+	// the token of a session kept across the revocation
+	tokenString, err = maker.CreateToken(userID, utils.RandomEmail(), time.Minute, token.WithIssuedAt(cutoff))
+	require.NoError(t, err)
+	payload, err = maker.VerifyToken(tokenString)
+	require.NoError(t, err)
+	require.Equal(t, cutoff, payload.IssuedAt.UTC())
+	revoked, err = store.IsRevoked(ctx, payload)
+	require.NoError(t, err)
+	require.False(t, revoked)
+}